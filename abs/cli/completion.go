@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{booksListCmd, searchCmd, scanCmd} {
+		cmd.RegisterFlagCompletionFunc("library", tool.CompleteFlag("libraries", completeLibraries))
+	}
+	booksShowCmd.ValidArgsFunction = completeBookIDs
+}
+
+func completeLibraries() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	libs, err := client.ListLibraries()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(libs))
+	for i, l := range libs {
+		out[i] = fmt.Sprintf("%s\t%s", l.ID, l.Name)
+	}
+	return out, nil
+}
+
+// completeBookIDs caches per library, since --library changes the candidates
+func completeBookIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return tool.Complete("books-"+flagLibrary, completeBooks)(cmd, args, toComplete)
+}
+
+func completeBooks() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	libraryID, err := getDefaultLibrary(client)
+	if err != nil {
+		return nil, err
+	}
+	items, _, err := client.ListLibraryItems(libraryID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = fmt.Sprintf("%s\t%s", item.ID, item.Media.Metadata.Title)
+	}
+	return out, nil
+}
@@ -1,74 +1,24 @@
 package abs
 
 import (
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
-	"strings"
-	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	api *common.Client
 }
 
 func NewClient(baseURL, token string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
 	return &Client{
-		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		token:      token,
-		httpClient: client,
+		api: common.NewClient(baseURL, common.BearerAuth{Token: token}, common.Options{Insecure: insecure}),
 	}
 }
 
 func (c *Client) request(method, path string, result interface{}) error {
-	reqURL := c.baseURL + path
-	
-	req, err := http.NewRequest(method, reqURL, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return AuthError("invalid or expired token")
-	}
-
-	if resp.StatusCode == http.StatusNotFound {
-		return NotFoundError("resource not found")
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return APIError(fmt.Sprintf("unexpected status %d", resp.StatusCode))
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response: %s", err))
-		}
-	}
-
-	return nil
+	return c.api.Do(method, path, nil, result)
 }
 
 func (c *Client) ListLibraries() ([]APILibrary, error) {
@@ -123,3 +73,18 @@ func (c *Client) Search(libraryID, query string) (*APISearchResponse, error) {
 func (c *Client) ScanLibrary(libraryID string) error {
 	return c.request("POST", "/api/libraries/"+libraryID+"/scan", nil)
 }
+
+// ServerVersion checks the token against /api/me, then reads the version
+// from /status, which is public.
+func (c *Client) ServerVersion() (string, error) {
+	if err := c.request("GET", "/api/me", nil); err != nil {
+		return "", err
+	}
+	var status struct {
+		ServerVersion string `json:"serverVersion"`
+	}
+	if err := c.request("GET", "/status", &status); err != nil {
+		return "", err
+	}
+	return status.ServerVersion, nil
+}
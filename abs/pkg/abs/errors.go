@@ -1,59 +1,35 @@
 package abs
 
-import "fmt"
+import "github.com/schmoli/cli-tools/common"
 
-type ErrorCode string
+type ErrorCode = common.ErrorCode
 
 const (
-	ErrConfig   ErrorCode = "CONFIG_ERROR"
-	ErrAuth     ErrorCode = "AUTH_FAILED"
-	ErrNotFound ErrorCode = "NOT_FOUND"
-	ErrNetwork  ErrorCode = "NETWORK_ERROR"
-	ErrAPI      ErrorCode = "API_ERROR"
+	ErrConfig   = common.ErrConfig
+	ErrAuth     = common.ErrAuth
+	ErrNotFound = common.ErrNotFound
+	ErrNetwork  = common.ErrNetwork
+	ErrAPI      = common.ErrAPI
 )
 
-type AbsError struct {
-	Code    ErrorCode
-	Message string
-}
-
-func (e *AbsError) Error() string {
-	return e.Message
-}
-
-func (e *AbsError) ExitCode() int {
-	switch e.Code {
-	case ErrConfig:
-		return 1
-	case ErrAuth:
-		return 2
-	case ErrNotFound:
-		return 3
-	case ErrNetwork:
-		return 4
-	case ErrAPI:
-		return 5
-	default:
-		return 1
-	}
-}
+type AbsError = common.Error
 
 func ConfigError(msg string) *AbsError {
-	return &AbsError{Code: ErrConfig, Message: msg}
+	return common.ConfigError(msg)
 }
 
 func AuthError(msg string) *AbsError {
-	return &AbsError{Code: ErrAuth, Message: msg}
+	return common.AuthError(msg)
 }
 
 func NotFoundError(msg string) *AbsError {
-	return &AbsError{Code: ErrNotFound, Message: msg}
+	return common.NotFoundError(msg)
 }
 
 func NetworkError(msg string) *AbsError {
-	return &AbsError{Code: ErrNetwork, Message: msg}
+	return common.NetworkError(msg)
 }
 
 func APIError(msg string) *AbsError {
-	return &AbsError{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+	return common.APIError(msg)
 }
@@ -4,18 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	enc := yaml.NewEncoder(os.Stdout)
 	enc.SetIndent(2)
@@ -25,23 +17,70 @@ func PrintYAML(data interface{}) error {
 	return nil
 }
 
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
 func PrintError(err error) {
-	ae, ok := err.(*AbsError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (LibraryListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "MEDIA TYPE", Field: "mediaType"},
+		{Header: "FOLDERS", Field: "folders"},
+	}
+}
+
+func (BookListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "AUTHOR", Field: "author"},
+		{Header: "DURATION", Field: "duration"},
+		{Header: "SIZE", Field: "size", Wide: true},
 	}
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(ae.Code),
-			Message: ae.Message,
-		},
+func (BookDetailItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "AUTHOR", Field: "author"},
+		{Header: "SERIES", Field: "series"},
+		{Header: "DURATION", Field: "duration"},
+		{Header: "NARRATORS", Field: "narrators", Wide: true},
+		{Header: "CHAPTERS", Field: "chapters", Wide: true},
+		{Header: "SIZE", Field: "size", Wide: true},
+		{Header: "PUBLISHED", Field: "publishedYear", Wide: true},
+		{Header: "ADDED", Field: "addedAt", Wide: true},
 	}
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", ae.Message)
+func (ProgressListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TITLE", Field: "title"},
+		{Header: "PROGRESS", Field: "progress"},
+		{Header: "CURRENT", Field: "currentTime"},
+		{Header: "DURATION", Field: "duration"},
+		{Header: "ITEM ID", Field: "libraryItemId", Wide: true},
+		{Header: "LAST UPDATE", Field: "lastUpdate", Wide: true},
 	}
 }
@@ -62,4 +62,8 @@ if [ -f "radarr/cmd/radarr-cli/main.go" ]; then
     build_tool "radarr-cli" "radarr"
 fi
 
+if [ -f "homelab/cmd/homelab/main.go" ]; then
+    build_tool "homelab" "homelab"
+fi
+
 echo -e "${GREEN}Done.${NC}"
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Auth applies credentials to an outgoing request.
+type Auth interface {
+	Apply(req *http.Request)
+}
+
+// Renewer is implemented by auth strategies that can recover from a
+// response by refreshing state (e.g. Transmission's session ID). Renew
+// reports whether the request should be sent again.
+type Renewer interface {
+	Renew(resp *http.Response) (bool, error)
+}
+
+// BearerAuth sends "Authorization: Bearer <token>" (nginx-proxy-manager, Audiobookshelf).
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Apply(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+}
+
+// APIKeyAuth sends the key in a custom header (Portainer, Sonarr, Radarr).
+type APIKeyAuth struct {
+	Header string
+	Key    string
+}
+
+func (a APIKeyAuth) Apply(req *http.Request) {
+	req.Header.Set(a.Header, a.Key)
+}
+
+// PVETokenAuth sends a Proxmox API token.
+type PVETokenAuth struct {
+	TokenID string
+	Secret  string
+}
+
+func (a PVETokenAuth) Apply(req *http.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s=%s", a.TokenID, a.Secret))
+}
+
+// BasicAuth sends HTTP basic credentials when User is set.
+type BasicAuth struct {
+	User string
+	Pass string
+}
+
+func (a BasicAuth) Apply(req *http.Request) {
+	if a.User != "" {
+		req.SetBasicAuth(a.User, a.Pass)
+	}
+}
+
+const TransmissionSessionHeader = "X-Transmission-Session-Id"
+
+// TransmissionAuth is basic auth plus Transmission's CSRF session header.
+// The session ID is learned from the first 409 response and reused.
+type TransmissionAuth struct {
+	BasicAuth
+	SessionID string
+}
+
+func (a *TransmissionAuth) Apply(req *http.Request) {
+	a.BasicAuth.Apply(req)
+	if a.SessionID != "" {
+		req.Header.Set(TransmissionSessionHeader, a.SessionID)
+	}
+}
+
+func (a *TransmissionAuth) Renew(resp *http.Response) (bool, error) {
+	if resp.StatusCode != http.StatusConflict {
+		return false, nil
+	}
+	a.SessionID = resp.Header.Get(TransmissionSessionHeader)
+	if a.SessionID == "" {
+		return false, APIError("received 409 but no session ID in response")
+	}
+	return true, nil
+}
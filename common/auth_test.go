@@ -0,0 +1,61 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuthApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   Auth
+		header string
+		want   string
+	}{
+		{"bearer", BearerAuth{Token: "abc"}, "Authorization", "Bearer abc"},
+		{"api_key", APIKeyAuth{Header: "X-Api-Key", Key: "k"}, "X-Api-Key", "k"},
+		{"pve", PVETokenAuth{TokenID: "user@pam!tok", Secret: "s"}, "Authorization", "PVEAPIToken=user@pam!tok=s"},
+		{"basic", BasicAuth{User: "u", Pass: "p"}, "Authorization", "Basic dTpw"},
+		{"basic_empty", BasicAuth{}, "Authorization", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			tt.auth.Apply(req)
+			if got := req.Header.Get(tt.header); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransmissionAuthRenew(t *testing.T) {
+	auth := &TransmissionAuth{}
+
+	resp := &http.Response{StatusCode: http.StatusConflict, Header: http.Header{}}
+	resp.Header.Set(TransmissionSessionHeader, "sess-1")
+
+	retry, err := auth.Renew(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !retry {
+		t.Error("expected retry on 409")
+	}
+
+	req, _ := http.NewRequest("POST", "http://example.com", nil)
+	auth.Apply(req)
+	if got := req.Header.Get(TransmissionSessionHeader); got != "sess-1" {
+		t.Errorf("session header = %q, want sess-1", got)
+	}
+}
+
+func TestTransmissionAuthRenewMissingHeader(t *testing.T) {
+	auth := &TransmissionAuth{}
+	resp := &http.Response{StatusCode: http.StatusConflict, Header: http.Header{}}
+
+	if _, err := auth.Renew(resp); err == nil {
+		t.Error("expected error when 409 has no session ID")
+	}
+}
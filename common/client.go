@@ -0,0 +1,307 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+// maxErrorBody limits how much of an error response is read for its message
+const maxErrorBody = 64 * 1024
+
+type Options struct {
+	Timeout    time.Duration
+	Insecure   bool
+	ParseError ErrorParser
+}
+
+// Client is the HTTP layer shared by every tool. It applies the auth
+// strategy, retries transient failures, maps status codes onto the error
+// taxonomy and decodes JSON.
+type Client struct {
+	BaseURL    string
+	Auth       Auth
+	HTTPClient *http.Client
+	Retry      RetryPolicy
+	ParseError ErrorParser // service error envelope; defaults to ParseErrorBody
+}
+
+func NewClient(baseURL string, auth Auth, opts Options) *Client {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+	}
+	if opts.Insecure {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	if traceLevel > TraceOff {
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &traceTransport{base: base, level: traceLevel, out: traceOut}
+	}
+
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Auth:       auth,
+		HTTPClient: client,
+		Retry:      retryPolicy,
+		ParseError: opts.ParseError,
+	}
+}
+
+func (c *Client) Get(path string, result interface{}) error {
+	return c.Do("GET", path, nil, result)
+}
+
+func (c *Client) Post(path string, body, result interface{}) error {
+	return c.Do("POST", path, body, result)
+}
+
+// PostIdempotent is Post for requests that are safe to repeat, such as
+// read-only RPC calls, so they are retried like GETs.
+func (c *Client) PostIdempotent(path string, body, result interface{}) error {
+	return c.do("POST", path, body, result, true)
+}
+
+func (c *Client) Put(path string, body, result interface{}) error {
+	return c.Do("PUT", path, body, result)
+}
+
+func (c *Client) Delete(path string, result interface{}) error {
+	return c.Do("DELETE", path, nil, result)
+}
+
+// Do sends body (if non-nil) as JSON and decodes a JSON response into
+// result (if non-nil). Only GET and HEAD requests are retried by default.
+func (c *Client) Do(method, path string, body, result interface{}) error {
+	return c.do(method, path, body, result, idempotent(method))
+}
+
+func (c *Client) do(method, path string, body, result interface{}, safe bool) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return APIError(fmt.Sprintf("failed to encode request: %s", err))
+		}
+	}
+
+	resp, err := c.send(method, path, payload, safe)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && !errors.Is(err, io.EOF) {
+		return APIError(fmt.Sprintf("failed to parse response from %s: %s", path, err))
+	}
+	return nil
+}
+
+// send performs the request and returns the response on 2xx. Any other
+// status is converted into an *Error and the body is closed. Transient
+// failures are retried per c.Retry when the request is safe to repeat.
+func (c *Client) send(method, path string, payload []byte, safe bool) (*http.Response, error) {
+	retries := 0
+	if safe || c.Retry.Unsafe {
+		retries = c.Retry.Retries
+	}
+
+	for n := 0; ; n++ {
+		resp, err := c.attempt(context.Background(), c.HTTPClient, method, path, payload, nil)
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		if err == nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+			err = c.statusError(resp, path, body)
+		}
+		e, ok := err.(*Error)
+		transient := ok && (e.Code == ErrNetwork || (resp != nil && retryableStatus(resp.StatusCode)))
+		if !transient || n >= retries {
+			if ok && n > 0 {
+				e.Message = fmt.Sprintf("%s (after %d retries)", e.Message, n)
+			}
+			return nil, err
+		}
+		sleep(c.Retry.backoff(n, resp))
+	}
+}
+
+// Head sends a HEAD request with extra headers and returns the response
+// headers, for APIs that answer in headers such as registry digests.
+func (c *Client) Head(path string, header http.Header) (http.Header, error) {
+	resp, err := c.attempt(context.Background(), c.HTTPClient, http.MethodHead, path, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, c.statusError(resp, path, nil)
+	}
+	return resp.Header, nil
+}
+
+// Stream sends a GET and returns the response body unread, for output
+// that arrives over time such as followed logs. The client timeout does
+// not apply and nothing is retried; cancel ctx to stop.
+func (c *Client) Stream(ctx context.Context, path string) (io.ReadCloser, error) {
+	return c.Open(ctx, http.MethodGet, path, nil)
+}
+
+// Open is Stream for any method, with extra request headers, e.g. a POST
+// whose response reports progress as it happens.
+func (c *Client) Open(ctx context.Context, method, path string, header http.Header) (io.ReadCloser, error) {
+	return c.Send(ctx, method, path, header, nil)
+}
+
+// Send is Open with a raw request body, such as a file upload or a
+// download requested with a JSON body. The body is sent as JSON unless
+// header sets a Content-Type.
+func (c *Client) Send(ctx context.Context, method, path string, header http.Header, payload []byte) (io.ReadCloser, error) {
+	hc := *c.HTTPClient
+	hc.Timeout = 0
+	resp, err := c.attempt(ctx, &hc, method, path, payload, header)
+	if err != nil {
+		return nil, err
+	}
+	return c.openBody(resp, path)
+}
+
+// Upload is Send with a streamed body, such as a large archive. The body
+// is read once, so the request is never retried, and traces leave it out.
+func (c *Client) Upload(ctx context.Context, method, path string, header http.Header, body io.Reader) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, NetworkError(err.Error())
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if c.Auth != nil {
+		c.Auth.Apply(req)
+	}
+
+	hc := *c.HTTPClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, NetworkError(err.Error())
+	}
+	return c.openBody(resp, path)
+}
+
+// openBody returns a successful response's body, or the status error.
+func (c *Client) openBody(resp *http.Response, path string) (io.ReadCloser, error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		return nil, c.statusError(resp, path, body)
+	}
+	return resp.Body, nil
+}
+
+// attempt sends the request once, with one extra round trip for auth
+// strategies that renew on failure. A non-nil response may have any status.
+func (c *Client) attempt(ctx context.Context, hc *http.Client, method, path string, payload []byte, header http.Header) (*http.Response, error) {
+	url := c.BaseURL + path
+
+	for try := 0; try < 2; try++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return nil, NetworkError(err.Error())
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if payload != nil && req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.Auth != nil {
+			c.Auth.Apply(req)
+		}
+
+		resp, err := hc.Do(req)
+		if err != nil {
+			return nil, NetworkError(err.Error())
+		}
+
+		if renewer, ok := c.Auth.(Renewer); ok && try == 0 {
+			retry, err := renewer.Renew(resp)
+			if err != nil || retry {
+				resp.Body.Close()
+			}
+			if err != nil {
+				return nil, err
+			}
+			if retry {
+				continue
+			}
+		}
+		return resp, nil
+	}
+
+	return nil, APIError("failed to get valid session after retry")
+}
+
+// statusError maps a non-2xx response onto the error taxonomy, using the
+// server's own message when the body carries one.
+func (c *Client) statusError(resp *http.Response, path string, body []byte) *Error {
+	parse := c.ParseError
+	if parse == nil {
+		parse = ParseErrorBody
+	}
+	message, details := parse(body)
+	if message == "" {
+		// Some services (Proxmox) put the reason in the status line
+		reason := strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)))
+		if reason != "" && reason != http.StatusText(resp.StatusCode) {
+			message = reason
+		}
+	}
+
+	var e *Error
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e = AuthError(fmt.Sprintf("invalid or expired credentials for %s", path))
+	case http.StatusNotFound:
+		e = NotFoundError(fmt.Sprintf("resource not found: %s", path))
+	default:
+		e = APIError(fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, path))
+	}
+	if message != "" {
+		e.Message = fmt.Sprintf("%s: %s", e.Message, message)
+	}
+	e.Status = resp.StatusCode
+	e.Details = details
+	return e
+}
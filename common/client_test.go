@@ -0,0 +1,287 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientTrimsURL(t *testing.T) {
+	client := NewClient("http://example.com/", nil, Options{})
+	if client.BaseURL != "http://example.com" {
+		t.Errorf("BaseURL = %q, want %q", client.BaseURL, "http://example.com")
+	}
+}
+
+func TestNewClientDefaultTimeout(t *testing.T) {
+	client := NewClient("http://example.com", nil, Options{})
+	if client.HTTPClient.Timeout != DefaultTimeout {
+		t.Errorf("Timeout = %s, want %s", client.HTTPClient.Timeout, DefaultTimeout)
+	}
+}
+
+func TestNewClientInsecure(t *testing.T) {
+	client := NewClient("http://example.com", nil, Options{Insecure: true})
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("expected *http.Transport for insecure client")
+	}
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify")
+	}
+}
+
+func TestClientGetDecodesJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			t.Error("missing api key header")
+		}
+		w.Write([]byte(`{"name":"test"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, APIKeyAuth{Header: "X-Api-Key", Key: "secret"}, Options{})
+	var result struct {
+		Name string `json:"name"`
+	}
+	if err := client.Get("/thing", &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Name != "test" {
+		t.Errorf("Name = %q, want test", result.Name)
+	}
+}
+
+func TestClientPostSendsJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	var result map[string]string
+	if err := client.Post("/thing", map[string]string{"a": "b"}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   ErrorCode
+	}{
+		{"unauthorized", http.StatusUnauthorized, ErrAuth},
+		{"forbidden", http.StatusForbidden, ErrAuth},
+		{"not_found", http.StatusNotFound, ErrNotFound},
+		{"server_error", http.StatusInternalServerError, ErrAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, nil, Options{})
+			err := client.Get("/thing", nil)
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected *Error, got %T", err)
+			}
+			if e.Code != tt.code {
+				t.Errorf("Code = %q, want %q", e.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestClientStreamIgnoresTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			t.Error("missing api key header")
+		}
+		w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("second\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, APIKeyAuth{Header: "X-Api-Key", Key: "secret"}, Options{Timeout: 10 * time.Millisecond})
+	body, err := client.Stream(context.Background(), "/logs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+	out, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(out) != "first\nsecond\n" {
+		t.Errorf("body = %q", out)
+	}
+}
+
+func TestClientHeadReturnsHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.Header.Get("Accept") != "application/x-test" {
+			t.Errorf("got %s with Accept %q", r.Method, r.Header.Get("Accept"))
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:abc")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	header, err := client.Head("/v2/app/manifests/latest", http.Header{"Accept": {"application/x-test"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := header.Get("Docker-Content-Digest"); got != "sha256:abc" {
+		t.Errorf("digest = %q", got)
+	}
+}
+
+func TestClientSendKeepsContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Header.Get("Content-Type") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	for _, tc := range []struct {
+		header http.Header
+		want   string
+	}{
+		{nil, "application/json {}"},
+		{http.Header{"Content-Type": {"text/plain"}}, "text/plain {}"},
+	} {
+		body, err := client.Send(context.Background(), http.MethodPost, "/upload", tc.header, []byte("{}"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, _ := io.ReadAll(body)
+		body.Close()
+		if string(got) != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestClientStreamStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	_, err := client.Stream(context.Background(), "/logs")
+	if e, ok := err.(*Error); !ok || e.Code != ErrNotFound {
+		t.Errorf("err = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestClientNetworkError(t *testing.T) {
+	client := NewClient("http://127.0.0.1:1", nil, Options{})
+	client.Retry = RetryPolicy{}
+	err := client.Get("/thing", nil)
+	e, ok := err.(*Error)
+	if !ok || e.Code != ErrNetwork {
+		t.Errorf("expected NETWORK_ERROR, got %v", err)
+	}
+}
+
+func TestClientRenewsTransmissionSession(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get(TransmissionSessionHeader) != "sess" {
+			w.Header().Set(TransmissionSessionHeader, "sess")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, &TransmissionAuth{}, Options{})
+	var result map[string]string
+	if err := client.Post("/transmission/rpc", map[string]string{"method": "session-get"}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if result["result"] != "success" {
+		t.Errorf("result = %v", result)
+	}
+}
+
+type fixedResponse struct {
+	status string
+	code   int
+	body   string
+}
+
+func (f fixedResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		Status:     f.status,
+		StatusCode: f.code,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClientErrorUsesServerMessage(t *testing.T) {
+	client := NewClient("http://example.com", nil, Options{})
+	client.Retry = RetryPolicy{}
+	client.HTTPClient.Transport = fixedResponse{status: "400 Bad Request", code: 400, body: `{"message":"name is required"}`}
+
+	err := client.Post("/stacks", nil, nil)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if e.Status != 400 || e.Message != "API error: unexpected status 400 from /stacks: name is required" {
+		t.Errorf("got status %d, message %q", e.Status, e.Message)
+	}
+}
+
+func TestClientErrorUsesStatusReason(t *testing.T) {
+	client := NewClient("http://example.com", nil, Options{})
+	client.Retry = RetryPolicy{}
+	client.HTTPClient.Transport = fixedResponse{status: "500 VM 100 not running", code: 500, body: `{"data":null}`}
+
+	err := client.Post("/status/stop", nil, nil)
+	if e, ok := err.(*Error); !ok || !strings.HasSuffix(e.Message, ": VM 100 not running") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClientErrorCustomParser(t *testing.T) {
+	client := NewClient("http://example.com", nil, Options{
+		ParseError: func(body []byte) (string, []string) {
+			return "validation failed", []string{"path: required"}
+		},
+	})
+	client.Retry = RetryPolicy{}
+	client.HTTPClient.Transport = fixedResponse{status: "400 Bad Request", code: 400, body: `[]`}
+
+	err := client.Get("/series", nil)
+	if e, ok := err.(*Error); !ok || len(e.Details) != 1 || e.Details[0] != "path: required" {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI colours for labelling interleaved output, in the order they are
+// handed out.
+var labelColors = []int{36, 33, 32, 35, 34, 31}
+
+// UseColor reports whether ANSI colour should be written to w: only when w
+// is a terminal and NO_COLOR is unset.
+func UseColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Label returns s in the i-th label colour, or unchanged without colour.
+func Label(s string, i int, color bool) string {
+	if !color {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", labelColors[i%len(labelColors)], s)
+}
@@ -0,0 +1,24 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUseColorNotTerminal(t *testing.T) {
+	if UseColor(&bytes.Buffer{}) {
+		t.Error("expected no colour for a buffer")
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := Label("web", 0, false); got != "web" {
+		t.Errorf("Label without colour = %q", got)
+	}
+	if got := Label("web", 0, true); got != "\x1b[36mweb\x1b[0m" {
+		t.Errorf("Label = %q", got)
+	}
+	if Label("db", 0, true) != Label("db", len(labelColors), true) {
+		t.Error("colours should cycle")
+	}
+}
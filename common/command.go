@@ -0,0 +1,265 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Tool describes a CLI to the shared subcommands (config, doctor, ...).
+type Tool struct {
+	Name    string   // service key in the config file, e.g. "portainer"
+	Keys    []string // settable config keys, named after the tool's flags
+	Secrets []string // keys that may come from the credential store
+	Context *string  // value of the --context flag
+	Print   func(data interface{}) error
+	Fail    func(err error) // prints the error and exits
+	Doctor  *Doctor         // service checks for the doctor command
+}
+
+type ContextList struct {
+	Contexts []ContextListItem `yaml:"contexts"`
+}
+
+type ContextListItem struct {
+	Name       string `yaml:"name"`
+	Current    bool   `yaml:"current"`
+	Configured bool   `yaml:"configured"` // has settings for this tool
+}
+
+func (ContextListItem) TableColumns() []Column {
+	return []Column{
+		{Header: "NAME", Field: "name"},
+		{Header: "CURRENT", Field: "current"},
+		{Header: "CONFIGURED", Field: "configured"},
+	}
+}
+
+// NewConfigCmd builds the "config" command with get-contexts,
+// current-context, use-context, set, set-secret and delete-secret.
+func NewConfigCmd(t *Tool) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: fmt.Sprintf("Manage contexts in %s", ConfigPath()),
+	}
+
+	getContextsCmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List contexts",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			current := cfg.ContextName(*t.Context)
+			output := ContextList{Contexts: []ContextListItem{}}
+			for _, name := range cfg.ContextNames() {
+				output.Contexts = append(output.Contexts, ContextListItem{
+					Name:       name,
+					Current:    name == current,
+					Configured: len(cfg.Contexts[name][t.Name]) > 0,
+				})
+			}
+
+			if err := t.Print(output); err != nil {
+				t.Fail(err)
+			}
+		},
+	}
+
+	currentContextCmd := &cobra.Command{
+		Use:   "current-context",
+		Short: "Show the active context",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			name := cfg.ContextName(*t.Context)
+			if name == "" {
+				t.Fail(ConfigError("no context selected. Use 'config use-context <name>'"))
+				return
+			}
+			fmt.Println(name)
+		},
+	}
+
+	useContextCmd := &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Set the default context",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			name := args[0]
+			if _, ok := cfg.Contexts[name]; !ok {
+				t.Fail(ConfigError(fmt.Sprintf("context %q not found in %s", name, cfg.Path())))
+				return
+			}
+
+			cfg.CurrentContext = name
+			if err := cfg.Save(); err != nil {
+				t.Fail(err)
+				return
+			}
+			fmt.Printf("switched to context %q\n", name)
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:       "set <key> <value>",
+		Short:     fmt.Sprintf("Set a %s setting (%s) in the active context", t.Name, strings.Join(t.settableKeys(), ", ")),
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: t.settableKeys(),
+		Run: func(cmd *cobra.Command, args []string) {
+			key, value := args[0], args[1]
+			if !contains(t.settableKeys(), key) {
+				t.Fail(ConfigError(fmt.Sprintf("unknown key %q for %s (valid: %s)", key, t.Name, strings.Join(t.settableKeys(), ", "))))
+				return
+			}
+
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			name := cfg.ContextName(*t.Context)
+			if name == "" {
+				t.Fail(ConfigError("no context selected. Use --context <name> to create one"))
+				return
+			}
+
+			cfg.Set(name, t.Name, key, value)
+			// The first context created becomes the default
+			if cfg.CurrentContext == "" {
+				cfg.CurrentContext = name
+			}
+			if err := cfg.Save(); err != nil {
+				t.Fail(err)
+				return
+			}
+			fmt.Printf("set %s.%s in context %q\n", t.Name, key, name)
+		},
+	}
+
+	setSecretCmd := &cobra.Command{
+		Use:       "set-secret <key>",
+		Short:     fmt.Sprintf("Save a %s secret (%s) in the credential store, read from stdin", t.Name, strings.Join(t.Secrets, ", ")),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: t.Secrets,
+		Run: func(cmd *cobra.Command, args []string) {
+			key := args[0]
+			if !contains(t.Secrets, key) {
+				t.Fail(ConfigError(fmt.Sprintf("unknown secret %q for %s (valid: %s)", key, t.Name, strings.Join(t.Secrets, ", "))))
+				return
+			}
+
+			settings, err := LoadServiceConfig(t.Name, *t.Context)
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			value, err := ReadSecret(cmd.InOrStdin(), key)
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+
+			store, err := settings.StoreSecret(key, value)
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+			fmt.Printf("stored %s.%s for context %q in %s\n", t.Name, key, CredentialContext(settings.Context), store)
+		},
+	}
+
+	deleteSecretCmd := &cobra.Command{
+		Use:       "delete-secret <key>",
+		Short:     "Remove a secret from the credential store",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: t.Secrets,
+		Run: func(cmd *cobra.Command, args []string) {
+			key := args[0]
+			if !contains(t.Secrets, key) {
+				t.Fail(ConfigError(fmt.Sprintf("unknown secret %q for %s (valid: %s)", key, t.Name, strings.Join(t.Secrets, ", "))))
+				return
+			}
+
+			settings, err := LoadServiceConfig(t.Name, *t.Context)
+			if err != nil {
+				t.Fail(err)
+				return
+			}
+			if err := settings.DeleteSecret(key); err != nil {
+				t.Fail(err)
+				return
+			}
+			fmt.Printf("deleted %s.%s for context %q\n", t.Name, key, CredentialContext(settings.Context))
+		},
+	}
+
+	configCmd.AddCommand(getContextsCmd, currentContextCmd, useContextCmd, setCmd, setSecretCmd, deleteSecretCmd)
+	return configCmd
+}
+
+// settableKeys adds <secret>_command for each secret key.
+func (t *Tool) settableKeys() []string {
+	keys := append([]string{}, t.Keys...)
+	for _, s := range t.Secrets {
+		keys = append(keys, s+"_command")
+	}
+	return keys
+}
+
+// ReadSecret prompts without echo on a terminal, otherwise reads the
+// first line of stdin so secrets can be piped in.
+func ReadSecret(in io.Reader, key string) (string, error) {
+	var value string
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprintf(os.Stderr, "%s: ", key)
+		b, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", ConfigError(fmt.Sprintf("failed to read %s", key))
+		}
+		value = string(b)
+	} else {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", ConfigError(fmt.Sprintf("failed to read %s", key))
+		}
+		value = line
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", ConfigError(fmt.Sprintf("empty %s", key))
+	}
+	return value, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
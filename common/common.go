@@ -1,3 +1,3 @@
-// Package common provides shared utilities for CLI tools.
-// Code will be extracted here when a second tool needs it.
+// Package common provides shared utilities for CLI tools: the HTTP client,
+// auth strategies and the error taxonomy every tool reports through.
 package common
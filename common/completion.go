@@ -0,0 +1,129 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionTTL is how long fetched completion candidates are reused, so
+// repeated <TAB> presses don't hit the API each time.
+const CompletionTTL = 30 * time.Second
+
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Complete returns a ValidArgsFunction offering fetch's candidates for the
+// first positional argument. Candidates are "value\tdescription" strings.
+func (t *Tool) Complete(kind string, fetch func() ([]string, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return t.candidates(cmd, kind, toComplete, fetch), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteFlag is Complete for flag values, e.g. --library.
+func (t *Tool) CompleteFlag(kind string, fetch func() ([]string, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return t.candidates(cmd, kind, toComplete, fetch), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func (t *Tool) candidates(cmd *cobra.Command, kind, prefix string, fetch func() ([]string, error)) []string {
+	path := completionCachePath(t.completionKey(cmd, kind))
+
+	items, ok := readCompletionCache(path)
+	if !ok {
+		var err error
+		if items, err = fetch(); err != nil {
+			// Completion must stay quiet; errors show up on the real run
+			return nil
+		}
+		writeCompletionCache(path, items)
+	}
+
+	var out []string
+	for _, item := range items {
+		if strings.HasPrefix(item, prefix) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// completionKey scopes cached results to the tool, active context and
+// service URL, so --url or an environment override never gets another
+// instance's names.
+func (t *Tool) completionKey(cmd *cobra.Command, kind string) string {
+	context := t.contextName()
+	key := t.Name + "-" + CredentialContext(context) + "-" + kind
+	if url := t.completionURL(cmd, context); url != "" {
+		sum := sha256.Sum256([]byte(url))
+		key += "-" + hex.EncodeToString(sum[:6])
+	}
+	return key
+}
+
+// completionURL resolves the service URL like the tools do (flag, then
+// environment, then config file) but leaves credentials alone: resolving
+// a token can run a command or prompt for a passphrase, which a cache hit
+// must never do.
+func (t *Tool) completionURL(cmd *cobra.Command, context string) string {
+	if cmd != nil {
+		if f := cmd.Flag("url"); f != nil && f.Changed {
+			return f.Value.String()
+		}
+	}
+	if t.Doctor != nil && t.Doctor.URLEnv != "" {
+		if url := os.Getenv(t.Doctor.URLEnv); url != "" {
+			return url
+		}
+	}
+	if settings, err := LoadServiceConfig(t.Name, context); err == nil {
+		return settings.Get("url")
+	}
+	return ""
+}
+
+func completionCachePath(key string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "cli-tools", "completion", unsafeKeyChars.ReplaceAllString(key, "_")+".json")
+}
+
+func readCompletionCache(path string) ([]string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > CompletionTTL {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+func writeCompletionCache(path string, items []string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func completionTool(t *testing.T) *Tool {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv(ConfigPathEnv, filepath.Join(dir, "config.yaml"))
+	context := ""
+	return &Tool{Name: "test", Context: &context}
+}
+
+func TestCompleteCachesAndFilters(t *testing.T) {
+	tool := completionTool(t)
+	calls := 0
+	fetch := func() ([]string, error) {
+		calls++
+		return []string{"1\tweb", "12\tdb", "2\tproxy"}, nil
+	}
+	complete := tool.Complete("stacks", fetch)
+
+	got, _ := complete(nil, nil, "1")
+	if want := []string{"1\tweb", "12\tdb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got, _ = complete(nil, nil, "")
+	if len(got) != 3 {
+		t.Errorf("got %q, want all candidates", got)
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1 within TTL", calls)
+	}
+}
+
+func TestCompleteOnlyFirstArgument(t *testing.T) {
+	tool := completionTool(t)
+	complete := tool.Complete("stacks", func() ([]string, error) {
+		t.Fatal("fetch should not be called")
+		return nil, nil
+	})
+	if got, _ := complete(nil, []string{"1"}, ""); got != nil {
+		t.Errorf("got %q, want nothing", got)
+	}
+}
+
+func TestCompleteErrorsAreQuiet(t *testing.T) {
+	tool := completionTool(t)
+	calls := 0
+	complete := tool.Complete("stacks", func() ([]string, error) {
+		calls++
+		return nil, errors.New("unreachable")
+	})
+	if got, _ := complete(nil, nil, ""); got != nil {
+		t.Errorf("got %q, want nothing", got)
+	}
+	complete(nil, nil, "")
+	if calls != 2 {
+		t.Errorf("failed fetches should not be cached, calls = %d", calls)
+	}
+}
+
+func TestCompleteCacheKeyedByURL(t *testing.T) {
+	tool := completionTool(t)
+	tool.Doctor = &Doctor{URLEnv: "TEST_URL", Connect: func() (string, bool, error) {
+		t.Fatal("completion should not resolve credentials")
+		return "", false, nil
+	}}
+	t.Setenv("TEST_URL", "https://one.example")
+	calls := 0
+	complete := tool.Complete("stacks", func() ([]string, error) {
+		calls++
+		return []string{fmt.Sprint(calls)}, nil
+	})
+
+	root := &cobra.Command{Use: "test"}
+	root.PersistentFlags().String("url", "", "")
+	cmd := &cobra.Command{Use: "show"}
+	root.AddCommand(cmd)
+
+	first, _ := complete(cmd, nil, "")
+	if again, _ := complete(cmd, nil, ""); !reflect.DeepEqual(again, first) {
+		t.Errorf("same URL: got %q, want cached %q", again, first)
+	}
+
+	t.Setenv("TEST_URL", "https://two.example")
+	if got, _ := complete(cmd, nil, ""); reflect.DeepEqual(got, first) {
+		t.Errorf("env override served the cached %q", got)
+	}
+
+	root.PersistentFlags().Set("url", "https://three.example")
+	complete(cmd, nil, "")
+	if calls != 3 {
+		t.Errorf("fetch called %d times, want 3 (one per URL)", calls)
+	}
+}
@@ -0,0 +1,226 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigPathEnv = "CLI_TOOLS_CONFIG"
+	ContextEnv    = "CLI_TOOLS_CONTEXT"
+)
+
+// Config is the shared config file (~/.config/cli-tools/config.yaml):
+//
+//	current-context: prod
+//	contexts:
+//	  prod:
+//	    portainer:
+//	      url: https://portainer.example.com
+//	      token: ptr_xxx
+//	  lab:
+//	    pve:
+//	      url: https://pve.lab:8006
+//
+// Keys under a service use the same names as that tool's flags. Secrets
+// can instead come from a <key>_command or the credential store.
+type Config struct {
+	CurrentContext  string             `yaml:"current-context,omitempty"`
+	CredentialStore string             `yaml:"credential-store,omitempty"`
+	Contexts        map[string]Context `yaml:"contexts,omitempty"`
+
+	path string
+}
+
+// Context holds per-service settings for one environment.
+type Context map[string]ServiceConfig
+
+// ServiceConfig holds one tool's settings within a context.
+type ServiceConfig map[string]string
+
+func (s ServiceConfig) Get(key string) string {
+	return s[key]
+}
+
+func (s ServiceConfig) Bool(key string) bool {
+	b, _ := strconv.ParseBool(s[key])
+	return b
+}
+
+// ConfigPath returns $CLI_TOOLS_CONFIG, or config.yaml under
+// $XDG_CONFIG_HOME/cli-tools (default ~/.config/cli-tools).
+func ConfigPath() string {
+	if p := os.Getenv(ConfigPathEnv); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(".config", "cli-tools", "config.yaml")
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "cli-tools", "config.yaml")
+}
+
+// LoadConfig reads the config file. A missing file yields an empty config.
+func LoadConfig() (*Config, error) {
+	path := ConfigPath()
+	cfg := &Config{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, ConfigError(fmt.Sprintf("failed to read config %s: %s", path, err))
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, ConfigError(fmt.Sprintf("failed to parse config %s: %s", path, err))
+	}
+	return cfg, nil
+}
+
+// Save writes the config file, readable only by the owner since it may
+// contain tokens.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return ConfigError(fmt.Sprintf("failed to create config dir: %s", err))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return ConfigError(fmt.Sprintf("failed to serialize config: %s", err))
+	}
+	if err := os.WriteFile(c.path, buf.Bytes(), 0600); err != nil {
+		return ConfigError(fmt.Sprintf("failed to write config %s: %s", c.path, err))
+	}
+	return nil
+}
+
+func (c *Config) Path() string {
+	return c.path
+}
+
+// ContextName picks the active context: the --context flag, then
+// $CLI_TOOLS_CONTEXT, then current-context from the file.
+func (c *Config) ContextName(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if env := os.Getenv(ContextEnv); env != "" {
+		return env
+	}
+	return c.CurrentContext
+}
+
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Service returns a service's settings in the named context. An empty
+// name means no context is selected and yields empty settings.
+func (c *Config) Service(context, service string) (ServiceConfig, error) {
+	if context == "" {
+		return ServiceConfig{}, nil
+	}
+	ctx, ok := c.Contexts[context]
+	if !ok {
+		return nil, ConfigError(fmt.Sprintf("context %q not found in %s", context, c.path))
+	}
+	if ctx[service] == nil {
+		return ServiceConfig{}, nil
+	}
+	return ctx[service], nil
+}
+
+// Set stores a service setting in the named context, creating it if needed.
+func (c *Config) Set(context, service, key, value string) {
+	if c.Contexts == nil {
+		c.Contexts = map[string]Context{}
+	}
+	if c.Contexts[context] == nil {
+		c.Contexts[context] = Context{}
+	}
+	if c.Contexts[context][service] == nil {
+		c.Contexts[context][service] = ServiceConfig{}
+	}
+	c.Contexts[context][service][key] = value
+}
+
+// Settings is one tool's file settings in the active context.
+type Settings struct {
+	ServiceConfig
+	Context string
+	Service string
+	store   string
+}
+
+// LoadServiceConfig loads the file settings for a tool, honouring the
+// --context flag value. It is the lowest-precedence source in getConfig.
+func LoadServiceConfig(service, contextFlag string) (*Settings, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.ContextName(contextFlag)
+	svc, err := cfg.Service(name, service)
+	if err != nil {
+		return nil, err
+	}
+	return &Settings{ServiceConfig: svc, Context: name, Service: service, store: cfg.CredentialStore}, nil
+}
+
+// Secret resolves a secret setting: a plain value in the file, then the
+// output of <key>_command, then the credential store. A secret that is
+// not configured anywhere yields "".
+func (s *Settings) Secret(key string) (string, error) {
+	if v := s.Get(key); v != "" {
+		return v, nil
+	}
+	if command := s.Get(key + "_command"); command != "" {
+		return runTokenCommand(command)
+	}
+	store, err := NewCredentialStore(s.store)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(CredentialKey(s.Context, s.Service, key))
+}
+
+// StoreSecret saves a secret for the active context in the configured
+// credential store and returns the store's name.
+func (s *Settings) StoreSecret(key, value string) (string, error) {
+	store, err := NewCredentialStore(s.store)
+	if err != nil {
+		return "", err
+	}
+	if err := store.Set(CredentialKey(s.Context, s.Service, key), value); err != nil {
+		return "", err
+	}
+	return store.Name(), nil
+}
+
+// DeleteSecret removes a secret for the active context from the store.
+func (s *Settings) DeleteSecret(key string) error {
+	store, err := NewCredentialStore(s.store)
+	if err != nil {
+		return err
+	}
+	return store.Delete(CredentialKey(s.Context, s.Service, key))
+}
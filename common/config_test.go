@@ -0,0 +1,148 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useTempConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(ConfigPathEnv, path)
+	t.Setenv(ContextEnv, "")
+	return path
+}
+
+func TestConfigPathXDG(t *testing.T) {
+	t.Setenv(ConfigPathEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got := ConfigPath(); got != "/tmp/xdg/cli-tools/config.yaml" {
+		t.Errorf("ConfigPath() = %q", got)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	useTempConfig(t)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Contexts) != 0 || cfg.CurrentContext != "" {
+		t.Errorf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestConfigSaveAndLoad(t *testing.T) {
+	path := useTempConfig(t)
+
+	cfg, _ := LoadConfig()
+	cfg.Set("prod", "portainer", "url", "https://portainer.prod")
+	cfg.Set("prod", "portainer", "insecure", "true")
+	cfg.CurrentContext = "prod"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	svc, err := LoadServiceConfig("portainer", "")
+	if err != nil {
+		t.Fatalf("LoadServiceConfig: %v", err)
+	}
+	if svc.Get("url") != "https://portainer.prod" {
+		t.Errorf("url = %q", svc.Get("url"))
+	}
+	if !svc.Bool("insecure") {
+		t.Error("insecure = false, want true")
+	}
+}
+
+func TestConfigContextPrecedence(t *testing.T) {
+	useTempConfig(t)
+	cfg := &Config{CurrentContext: "file"}
+
+	if got := cfg.ContextName(""); got != "file" {
+		t.Errorf("got %q, want file", got)
+	}
+	t.Setenv(ContextEnv, "env")
+	if got := cfg.ContextName(""); got != "env" {
+		t.Errorf("got %q, want env", got)
+	}
+	if got := cfg.ContextName("flag"); got != "flag" {
+		t.Errorf("got %q, want flag", got)
+	}
+}
+
+func TestConfigServiceUnknownContext(t *testing.T) {
+	cfg := &Config{Contexts: map[string]Context{"prod": {}}}
+
+	_, err := cfg.Service("lab", "pve")
+	if e, ok := err.(*Error); !ok || e.Code != ErrConfig {
+		t.Errorf("expected CONFIG_ERROR, got %v", err)
+	}
+
+	svc, err := cfg.Service("prod", "pve")
+	if err != nil || len(svc) != 0 {
+		t.Errorf("expected empty settings, got %v, %v", svc, err)
+	}
+}
+
+func TestConfigCmdSetAndUseContext(t *testing.T) {
+	useTempConfig(t)
+
+	var failed error
+	contextFlag := ""
+	tool := &Tool{
+		Name:    "pve",
+		Keys:    []string{"url", "token-id", "token"},
+		Context: &contextFlag,
+		Print:   func(interface{}) error { return nil },
+		Fail:    func(err error) { failed = err },
+	}
+
+	run := func(args ...string) {
+		cmd := NewConfigCmd(tool)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute(%v): %v", args, err)
+		}
+	}
+
+	contextFlag = "lab"
+	run("set", "url", "https://pve.lab:8006")
+	contextFlag = "prod"
+	run("set", "url", "https://pve.prod:8006")
+	if failed != nil {
+		t.Fatalf("unexpected failure: %v", failed)
+	}
+
+	cfg, _ := LoadConfig()
+	if cfg.CurrentContext != "lab" {
+		t.Errorf("current-context = %q, want lab (first created)", cfg.CurrentContext)
+	}
+
+	contextFlag = ""
+	run("use-context", "prod")
+	svc, _ := LoadServiceConfig("pve", "")
+	if svc.Get("url") != "https://pve.prod:8006" {
+		t.Errorf("url = %q after use-context prod", svc.Get("url"))
+	}
+
+	run("set", "bogus", "x")
+	if failed == nil {
+		t.Error("expected failure for unknown key")
+	}
+
+	failed = nil
+	run("use-context", "missing")
+	if failed == nil {
+		t.Error("expected failure for unknown context")
+	}
+}
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Confirm asks question on out and reads the answer from in. Anything but
+// "y" or "yes", including no input at all, aborts with a CONFIG_ERROR that
+// points at --yes, so unattended runs fail safe.
+func Confirm(in io.Reader, out io.Writer, question string) error {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(out)
+		return ConfigError("no confirmation on stdin; pass --yes to proceed")
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	}
+	return ConfigError("aborted")
+}
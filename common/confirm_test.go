@@ -0,0 +1,38 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"yes", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := Confirm(strings.NewReader(tt.input), &out, "Delete stack web?")
+		if (err == nil) != tt.ok {
+			t.Errorf("input %q: err = %v, want ok=%v", tt.input, err, tt.ok)
+		}
+		if !strings.Contains(out.String(), "Delete stack web? [y/N]") {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
+
+func TestConfirmWithoutInputSuggestsYes(t *testing.T) {
+	err := Confirm(strings.NewReader(""), &bytes.Buffer{}, "Delete?")
+	if e, ok := err.(*Error); !ok || e.Code != ErrConfig || !strings.Contains(e.Message, "--yes") {
+		t.Errorf("err = %v", err)
+	}
+}
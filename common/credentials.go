@@ -0,0 +1,275 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CredentialStoreEnv = "CLI_TOOLS_CREDENTIAL_STORE"
+	CredentialsPathEnv = "CLI_TOOLS_CREDENTIALS"
+
+	// keyringService is the Secret Service "service" attribute and the
+	// pass/gopass folder that entries are stored under.
+	keyringService = "cli-tools"
+)
+
+// CredentialStore keeps secrets out of the config file, the environment
+// and shell history. Keys look like "<context>/<service>/<setting>".
+type CredentialStore interface {
+	Name() string
+	// Get returns "" without error when the key is not stored.
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// CredentialKey names a secret setting for a context and service.
+func CredentialKey(context, service, setting string) string {
+	return CredentialContext(context) + "/" + service + "/" + setting
+}
+
+// CredentialContext returns the context secrets are stored under; secrets
+// used without a context go under "default".
+func CredentialContext(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// NewCredentialStore opens a store by name: keyring, pass, gopass or file.
+// An empty name falls back to $CLI_TOOLS_CREDENTIAL_STORE, then to the
+// keyring when secret-tool and a session bus are available, else the file.
+func NewCredentialStore(name string) (CredentialStore, error) {
+	if name == "" {
+		name = os.Getenv(CredentialStoreEnv)
+	}
+	if name == "" {
+		name = "file"
+		if _, err := exec.LookPath("secret-tool"); err == nil && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			name = "keyring"
+		}
+	}
+
+	switch name {
+	case "keyring":
+		if err := requireBinary("keyring", "secret-tool"); err != nil {
+			return nil, err
+		}
+		return keyringStore{}, nil
+	case "pass", "gopass":
+		if err := requireBinary(name, name); err != nil {
+			return nil, err
+		}
+		return passStore{bin: name}, nil
+	case "file":
+		return &fileStore{path: CredentialsPath()}, nil
+	default:
+		return nil, ConfigError(fmt.Sprintf("unknown credential store %q (valid: keyring, pass, gopass, file)", name))
+	}
+}
+
+// CredentialsPath returns $CLI_TOOLS_CREDENTIALS, or credentials.yaml next
+// to the config file.
+func CredentialsPath() string {
+	if p := os.Getenv(CredentialsPathEnv); p != "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(ConfigPath()), "credentials.yaml")
+}
+
+// fileStore is the headless fallback: a 0600 YAML map of key to secret.
+type fileStore struct {
+	path string
+}
+
+func (s *fileStore) Name() string {
+	return "file"
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, ConfigError(fmt.Sprintf("failed to read credentials %s: %s", s.path, err))
+	}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, ConfigError(fmt.Sprintf("failed to parse credentials %s: %s", s.path, err))
+	}
+	return secrets, nil
+}
+
+func (s *fileStore) save(secrets map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return ConfigError(fmt.Sprintf("failed to create config dir: %s", err))
+	}
+	data, err := yaml.Marshal(secrets)
+	if err != nil {
+		return ConfigError(fmt.Sprintf("failed to serialize credentials: %s", err))
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return ConfigError(fmt.Sprintf("failed to write credentials %s: %s", s.path, err))
+	}
+	return nil
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[key], nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+// keyringStore talks to the Secret Service (GNOME Keyring, KWallet) via
+// secret-tool, so no D-Bus library is needed.
+type keyringStore struct{}
+
+func (keyringStore) Name() string {
+	return "keyring"
+}
+
+func (keyringStore) Get(key string) (string, error) {
+	out, stderr, err := runSecretCommand("", "secret-tool", "lookup", "service", keyringService, "key", key)
+	if err != nil {
+		// secret-tool exits 1 with no output when nothing matches
+		if stderr == "" {
+			return "", nil
+		}
+		return "", ConfigError(fmt.Sprintf("keyring lookup failed: %s", stderr))
+	}
+	return out, nil
+}
+
+func (keyringStore) Set(key, value string) error {
+	label := fmt.Sprintf("%s %s", keyringService, key)
+	if _, stderr, err := runSecretCommand(value, "secret-tool", "store", "--label", label, "service", keyringService, "key", key); err != nil {
+		return ConfigError(fmt.Sprintf("keyring store failed: %s", firstNonEmpty(stderr, err.Error())))
+	}
+	return nil
+}
+
+func (keyringStore) Delete(key string) error {
+	if _, stderr, err := runSecretCommand("", "secret-tool", "clear", "service", keyringService, "key", key); err != nil {
+		return ConfigError(fmt.Sprintf("keyring clear failed: %s", firstNonEmpty(stderr, err.Error())))
+	}
+	return nil
+}
+
+// passStore keeps entries under cli-tools/ in pass or gopass.
+type passStore struct {
+	bin string
+}
+
+func (s passStore) Name() string {
+	return s.bin
+}
+
+func (s passStore) entry(key string) string {
+	return keyringService + "/" + key
+}
+
+func (s passStore) Get(key string) (string, error) {
+	args := []string{"show", s.entry(key)}
+	if s.bin == "gopass" {
+		args = []string{"show", "-o", s.entry(key)}
+	}
+	out, stderr, err := runSecretCommand("", s.bin, args...)
+	if err != nil {
+		if strings.Contains(stderr, "not in the password store") || strings.Contains(stderr, "not found") {
+			return "", nil
+		}
+		return "", ConfigError(fmt.Sprintf("%s show failed: %s", s.bin, firstNonEmpty(stderr, err.Error())))
+	}
+	// The password is the first line; the rest is free-form notes
+	line, _, _ := strings.Cut(out, "\n")
+	return line, nil
+}
+
+func (s passStore) Set(key, value string) error {
+	args := []string{"insert", "-m", "-f", s.entry(key)}
+	if s.bin == "gopass" {
+		args = []string{"insert", "-f", s.entry(key)}
+	}
+	if _, stderr, err := runSecretCommand(value+"\n", s.bin, args...); err != nil {
+		return ConfigError(fmt.Sprintf("%s insert failed: %s", s.bin, firstNonEmpty(stderr, err.Error())))
+	}
+	return nil
+}
+
+func (s passStore) Delete(key string) error {
+	if _, stderr, err := runSecretCommand("", s.bin, "rm", "-f", s.entry(key)); err != nil {
+		return ConfigError(fmt.Sprintf("%s rm failed: %s", s.bin, firstNonEmpty(stderr, err.Error())))
+	}
+	return nil
+}
+
+// runSecretCommand runs a helper with the secret on stdin (never argv, so
+// it stays out of ps) and returns trimmed stdout and stderr.
+func runSecretCommand(stdin, name string, args ...string) (string, string, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), err
+}
+
+func requireBinary(store, bin string) error {
+	if _, err := exec.LookPath(bin); err != nil {
+		return ConfigError(fmt.Sprintf("credential store %s needs %s in PATH", store, bin))
+	}
+	return nil
+}
+
+// runTokenCommand runs a user-supplied <key>_command through the shell and
+// returns its trimmed output.
+func runTokenCommand(command string) (string, error) {
+	out, stderr, err := runSecretCommand("", "sh", "-c", command)
+	if err != nil {
+		return "", ConfigError(fmt.Sprintf("command %q failed: %s", command, firstNonEmpty(stderr, err.Error())))
+	}
+	if out == "" {
+		return "", ConfigError(fmt.Sprintf("command %q produced no output", command))
+	}
+	return out, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
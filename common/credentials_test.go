@@ -0,0 +1,179 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useTempCredentials(t *testing.T) string {
+	t.Helper()
+	useTempConfig(t)
+	path := filepath.Join(t.TempDir(), "credentials.yaml")
+	t.Setenv(CredentialsPathEnv, path)
+	t.Setenv(CredentialStoreEnv, "file")
+	return path
+}
+
+func TestCredentialKey(t *testing.T) {
+	if got := CredentialKey("prod", "pve", "token"); got != "prod/pve/token" {
+		t.Errorf("got %q", got)
+	}
+	if got := CredentialKey("", "pve", "token"); got != "default/pve/token" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNewCredentialStoreUnknown(t *testing.T) {
+	_, err := NewCredentialStore("vault")
+	if e, ok := err.(*Error); !ok || e.Code != ErrConfig {
+		t.Errorf("expected CONFIG_ERROR, got %v", err)
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := useTempCredentials(t)
+
+	store, err := NewCredentialStore("")
+	if err != nil {
+		t.Fatalf("NewCredentialStore: %v", err)
+	}
+	if store.Name() != "file" {
+		t.Fatalf("store = %s, want file", store.Name())
+	}
+
+	if v, err := store.Get("prod/pve/token"); err != nil || v != "" {
+		t.Errorf("expected empty result, got %q, %v", v, err)
+	}
+	if err := store.Set("prod/pve/token", "s3cret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, _ := store.Get("prod/pve/token"); v != "s3cret" {
+		t.Errorf("Get = %q", v)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := store.Delete("prod/pve/token"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if v, _ := store.Get("prod/pve/token"); v != "" {
+		t.Errorf("expected deleted, got %q", v)
+	}
+}
+
+func TestSettingsSecretPrecedence(t *testing.T) {
+	useTempCredentials(t)
+
+	settings := &Settings{ServiceConfig: ServiceConfig{}, Context: "prod", Service: "portainer"}
+	if v, err := settings.Secret("token"); err != nil || v != "" {
+		t.Errorf("expected empty secret, got %q, %v", v, err)
+	}
+
+	if _, err := settings.StoreSecret("token", "from-store"); err != nil {
+		t.Fatalf("StoreSecret: %v", err)
+	}
+	if v, _ := settings.Secret("token"); v != "from-store" {
+		t.Errorf("got %q, want from-store", v)
+	}
+
+	settings.ServiceConfig["token_command"] = "echo from-command"
+	if v, _ := settings.Secret("token"); v != "from-command" {
+		t.Errorf("got %q, want from-command", v)
+	}
+
+	settings.ServiceConfig["token"] = "from-file"
+	if v, _ := settings.Secret("token"); v != "from-file" {
+		t.Errorf("got %q, want from-file", v)
+	}
+}
+
+func TestSettingsSecretCommandFailure(t *testing.T) {
+	useTempCredentials(t)
+
+	settings := &Settings{ServiceConfig: ServiceConfig{"token_command": "echo denied >&2; exit 3"}}
+	_, err := settings.Secret("token")
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected command failure with stderr, got %v", err)
+	}
+}
+
+func TestPassStoreUsesStdin(t *testing.T) {
+	useTempCredentials(t)
+
+	// A fake pass that keeps entries as files under $PASS_DIR
+	bin := t.TempDir()
+	script := `#!/bin/sh
+dir="$PASS_DIR"
+case "$1" in
+show) f="$dir/$(echo "$2" | tr / _)"; [ -f "$f" ] || { echo "Error: $2 is not in the password store." >&2; exit 1; }; cat "$f";;
+insert) cat > "$dir/$(echo "$4" | tr / _)";;
+rm) rm -f "$dir/$(echo "$3" | tr / _)";;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "pass"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PASS_DIR", t.TempDir())
+
+	store, err := NewCredentialStore("pass")
+	if err != nil {
+		t.Fatalf("NewCredentialStore: %v", err)
+	}
+	if v, err := store.Get("lab/trans/pass"); err != nil || v != "" {
+		t.Errorf("expected missing entry, got %q, %v", v, err)
+	}
+	if err := store.Set("lab/trans/pass", "hunter2"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, err := store.Get("lab/trans/pass"); err != nil || v != "hunter2" {
+		t.Errorf("Get = %q, %v", v, err)
+	}
+}
+
+func TestConfigCmdSetSecret(t *testing.T) {
+	useTempCredentials(t)
+
+	var failed error
+	contextFlag := "lab"
+	tool := &Tool{
+		Name:    "abs",
+		Keys:    []string{"url", "token"},
+		Secrets: []string{"token"},
+		Context: &contextFlag,
+		Print:   func(interface{}) error { return nil },
+		Fail:    func(err error) { failed = err },
+	}
+
+	cmd := NewConfigCmd(tool)
+	cmd.SetArgs([]string{"set", "url", "https://abs.lab"})
+	if err := cmd.Execute(); err != nil || failed != nil {
+		t.Fatalf("set url: %v, %v", err, failed)
+	}
+
+	cmd = NewConfigCmd(tool)
+	cmd.SetIn(strings.NewReader("abc123\n"))
+	cmd.SetArgs([]string{"set-secret", "token"})
+	if err := cmd.Execute(); err != nil || failed != nil {
+		t.Fatalf("set-secret: %v, %v", err, failed)
+	}
+
+	settings, _ := LoadServiceConfig("abs", "lab")
+	if v, _ := settings.Secret("token"); v != "abc123" {
+		t.Errorf("secret = %q, want abc123", v)
+	}
+
+	cmd = NewConfigCmd(tool)
+	cmd.SetArgs([]string{"set", "token_command", "pass show abs"})
+	if err := cmd.Execute(); err != nil || failed != nil {
+		t.Errorf("set token_command: %v, %v", err, failed)
+	}
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffEqual(t *testing.T) {
+	if d := UnifiedDiff("a", "b", "x\r\ny\n", "x\ny"); d != "" {
+		t.Errorf("expected no diff, got:\n%s", d)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "services:\n  web:\n    image: nginx:1.25\n    ports:\n      - 80:80\n"
+	b := "services:\n  web:\n    image: nginx:1.27\n    ports:\n      - 80:80\n"
+	want := `--- portainer
++++ local
+@@ -1,5 +1,5 @@
+ services:
+   web:
+-    image: nginx:1.25
++    image: nginx:1.27
+     ports:
+       - 80:80
+`
+	if got := UnifiedDiff("portainer", "local", a, b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		line := strings.Repeat("x", i)
+		a = append(a, line)
+		if i != 2 && i != 18 {
+			b = append(b, line)
+		}
+	}
+	b = append(b, "new")
+	got := UnifiedDiff("a", "b", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	for _, want := range []string{"@@ -1,5 +1,4 @@", "@@ -15,6 +14,6 @@", "-xx\n", "+new\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "@@ -") != 2 {
+		t.Errorf("expected 2 hunks:\n%s", got)
+	}
+}
+
+func TestUnifiedDiffFromEmpty(t *testing.T) {
+	got := UnifiedDiff("a", "b", "", "one\n")
+	if !strings.Contains(got, "@@ -0,0 +1 @@\n+one\n") {
+		t.Errorf("got:\n%s", got)
+	}
+}
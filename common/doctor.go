@@ -0,0 +1,321 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorTimeout bounds each DNS, TCP and TLS check
+	doctorTimeout = 5 * time.Second
+
+	// certExpiryWarning is how close to expiry a certificate is flagged
+	certExpiryWarning = 14 * 24 * time.Hour
+)
+
+// Check statuses
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// Doctor describes how a tool checks its service for the doctor command.
+type Doctor struct {
+	URLEnv     string                                        // environment variable holding the URL, e.g. "PVE_URL"
+	Connect    func() (url string, insecure bool, err error) // resolved connection settings
+	Version    func() (string, error)                        // cheap authenticated call returning the server version
+	Service    string                                        // display name, e.g. "Proxmox VE"
+	MinVersion string                                        // oldest server version the client is written against
+	Features   string                                        // what the client relies on at that version
+}
+
+// DoctorReport is the outcome of checking one service.
+type DoctorReport struct {
+	Tool     string        `yaml:"tool"`
+	Context  string        `yaml:"context,omitempty"`
+	URL      string        `yaml:"url,omitempty"`
+	Version  string        `yaml:"version,omitempty"`
+	Requires string        `yaml:"requires,omitempty"`
+	Checks   []DoctorCheck `yaml:"checks"`
+}
+
+type DoctorCheck struct {
+	Name   string `yaml:"name"`
+	Status string `yaml:"status"`
+	Detail string `yaml:"detail,omitempty"`
+}
+
+func (DoctorCheck) TableColumns() []Column {
+	return []Column{
+		{Header: "CHECK", Field: "name"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "DETAIL", Field: "detail"},
+	}
+}
+
+func (r *DoctorReport) add(name, status, detail string) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: detail})
+}
+
+// fail records a failed check and returns err for the caller to pass on.
+func (r *DoctorReport) fail(name string, err error) error {
+	r.add(name, CheckFail, err.Error())
+	return err
+}
+
+// Status summarises the report: the first failure, else any warning, else ok.
+func (r *DoctorReport) Status() string {
+	status := CheckOK
+	for _, c := range r.Checks {
+		switch c.Status {
+		case CheckFail:
+			return CheckFail
+		case CheckWarn:
+			status = CheckWarn
+		}
+	}
+	return status
+}
+
+// NewDoctorCmd builds the "doctor" command (alias "ping"). It prints the
+// report and exits with the code of the first failing check.
+func NewDoctorCmd(t *Tool) *cobra.Command {
+	return &cobra.Command{
+		Use:     "doctor",
+		Aliases: []string{"ping"},
+		Short:   "Check DNS, TCP, TLS, auth and server version",
+		Long: `Check DNS, TCP, TLS, auth and server version
+
+Checks run in order and stop at the first failure, which sets the exit code:
+  1  config     missing or invalid settings
+  2  auth       credentials rejected
+  4  tcp        cannot connect
+  5  api        unexpected API response
+  6  dns        host does not resolve
+  7  tls        handshake failed, certificate untrusted or expired
+  8  version    server is older than the client supports`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			report, err := t.Diagnose()
+			if perr := t.Print(report); perr != nil {
+				t.Fail(perr)
+				return
+			}
+			if err != nil {
+				t.Fail(err)
+			}
+		},
+	}
+}
+
+// Configured reports whether the tool has a URL in its config context or
+// environment, so aggregate checks can skip services that aren't set up.
+func (t *Tool) Configured() bool {
+	if t.Doctor != nil && os.Getenv(t.Doctor.URLEnv) != "" {
+		return true
+	}
+	settings, err := LoadServiceConfig(t.Name, *t.Context)
+	return err == nil && settings.Get("url") != ""
+}
+
+// Diagnose runs the checks in order and stops at the first failure.
+func (t *Tool) Diagnose() (*DoctorReport, error) {
+	d := t.Doctor
+	report := &DoctorReport{Tool: t.Name, Context: t.contextName()}
+	if d.MinVersion != "" {
+		report.Requires = fmt.Sprintf("%s >= %s", d.Service, d.MinVersion)
+		if d.Features != "" {
+			report.Requires += " (" + d.Features + ")"
+		}
+	}
+
+	rawURL, insecure, err := d.Connect()
+	if err != nil {
+		return report, report.fail("config", err)
+	}
+	report.URL = rawURL
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return report, report.fail("config", ConfigError(fmt.Sprintf("invalid URL %q", rawURL)))
+	}
+	report.add("config", CheckOK, "")
+
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	if net.ParseIP(host) != nil {
+		report.add("dns", CheckSkip, "IP address")
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		cancel()
+		if err != nil {
+			return report, report.fail("dns", DNSError(fmt.Sprintf("cannot resolve %s: %s", host, err)))
+		}
+		report.add("dns", CheckOK, strings.Join(addrs, ", "))
+	}
+
+	addr := net.JoinHostPort(host, port)
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, doctorTimeout)
+	if err != nil {
+		return report, report.fail("tcp", NetworkError(fmt.Sprintf("cannot connect to %s: %s", addr, err)))
+	}
+	conn.Close()
+	report.add("tcp", CheckOK, fmt.Sprintf("%s in %s", addr, time.Since(start).Round(time.Millisecond)))
+
+	if u.Scheme == "https" {
+		if err := report.checkTLS(addr, host, insecure); err != nil {
+			return report, err
+		}
+	} else {
+		report.add("tls", CheckSkip, "plain http")
+	}
+
+	version, err := d.Version()
+	if err != nil {
+		return report, report.fail("auth", err)
+	}
+	report.Version = version
+	report.add("auth", CheckOK, "")
+
+	if d.MinVersion != "" && compareVersions(version, d.MinVersion) < 0 {
+		return report, report.fail("version", VersionError(fmt.Sprintf("%s %s is older than %s, the oldest version this client supports", d.Service, version, d.MinVersion)))
+	}
+	report.add("version", CheckOK, version)
+	return report, nil
+}
+
+// checkTLS handshakes without verification so an untrusted or expired
+// certificate can still be described, then verifies the chain itself.
+func (r *DoctorReport) checkTLS(addr, host string, insecure bool) error {
+	dialer := &net.Dialer{Timeout: doctorTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err != nil {
+		return r.fail("tls", TLSError(fmt.Sprintf("TLS handshake with %s failed: %s", addr, err)))
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return r.fail("tls", TLSError(fmt.Sprintf("%s presented no certificate", addr)))
+	}
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, verifyErr := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates})
+
+	left := time.Until(leaf.NotAfter)
+	expiry := fmt.Sprintf("certificate expires %s (%d days)", leaf.NotAfter.Format("2006-01-02"), int(left.Hours()/24))
+	switch {
+	case verifyErr != nil && !insecure:
+		return r.fail("tls", TLSError(fmt.Sprintf("certificate for %s is not trusted: %s (use --insecure to skip verification)", host, verifyErr)))
+	case verifyErr != nil:
+		r.add("tls", CheckWarn, fmt.Sprintf("not trusted, ignored with --insecure: %s; %s", verifyErr, expiry))
+	case left < certExpiryWarning:
+		r.add("tls", CheckWarn, expiry)
+	default:
+		r.add("tls", CheckOK, expiry)
+	}
+	return nil
+}
+
+func (t *Tool) contextName() string {
+	cfg, err := LoadConfig()
+	if err != nil || t.Context == nil {
+		return ""
+	}
+	return cfg.ContextName(*t.Context)
+}
+
+var versionNumber = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// compareVersions compares the first dotted number in each string, so
+// "v2.19.4", "8.2.4" and "4.0.5 (a6fe2a64aa)" all work.
+func compareVersions(a, b string) int {
+	as := strings.Split(versionNumber.FindString(a), ".")
+	bs := strings.Split(versionNumber.FindString(b), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// DoctorSummary is the aggregate report across tools.
+type DoctorSummary struct {
+	Services []DoctorSummaryItem `yaml:"services"`
+}
+
+type DoctorSummaryItem struct {
+	Tool    string `yaml:"tool"`
+	Status  string `yaml:"status"`
+	URL     string `yaml:"url,omitempty"`
+	Version string `yaml:"version,omitempty"`
+	Detail  string `yaml:"detail,omitempty"`
+}
+
+func (DoctorSummaryItem) TableColumns() []Column {
+	return []Column{
+		{Header: "TOOL", Field: "tool"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "VERSION", Field: "version"},
+		{Header: "URL", Field: "url"},
+		{Header: "DETAIL", Field: "detail"},
+	}
+}
+
+// Summarize checks every configured tool, skipping the rest. The returned
+// error is the first failure, for the exit code.
+func Summarize(tools []*Tool) (*DoctorSummary, error) {
+	summary := &DoctorSummary{}
+	var first error
+	for _, t := range tools {
+		if !t.Configured() {
+			summary.Services = append(summary.Services, DoctorSummaryItem{Tool: t.Name, Status: CheckSkip, Detail: "not configured"})
+			continue
+		}
+		report, err := t.Diagnose()
+		item := DoctorSummaryItem{Tool: t.Name, Status: report.Status(), URL: report.URL, Version: report.Version}
+		if err != nil {
+			item.Detail = err.Error()
+			if first == nil {
+				first = err
+			}
+		}
+		summary.Services = append(summary.Services, item)
+	}
+	return summary, first
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// doctorTool checks server with a fixed version and an isolated config.
+func doctorTool(t *testing.T, url string, insecure bool, version func() (string, error)) *Tool {
+	t.Helper()
+	t.Setenv(ConfigPathEnv, filepath.Join(t.TempDir(), "config.yaml"))
+	context := ""
+	return &Tool{
+		Name:    "test",
+		Context: &context,
+		Doctor: &Doctor{
+			URLEnv:     "TEST_URL",
+			Service:    "Test",
+			MinVersion: "2.11",
+			Connect:    func() (string, bool, error) { return url, insecure, nil },
+			Version:    version,
+		},
+	}
+}
+
+func checkStatus(report *DoctorReport, name string) string {
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestDiagnoseOK(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool := doctorTool(t, server.URL, false, func() (string, error) { return "2.19.4", nil })
+	report, err := tool.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Version != "2.19.4" || report.Status() != CheckOK {
+		t.Errorf("report = %+v", report)
+	}
+	if got := checkStatus(report, "tls"); got != CheckSkip {
+		t.Errorf("tls = %q, want skip for http", got)
+	}
+	if report.Requires != "Test >= 2.11" {
+		t.Errorf("requires = %q", report.Requires)
+	}
+}
+
+func TestDiagnoseFailureClasses(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+
+	ok := func() (string, error) { return "2.19", nil }
+	tests := []struct {
+		name    string
+		url     string
+		version func() (string, error)
+		check   string
+		code    ErrorCode
+	}{
+		{"dns", "http://nonexistent.invalid", ok, "dns", ErrDNS},
+		{"tcp", "http://127.0.0.1:1", ok, "tcp", ErrNetwork},
+		{"untrusted cert", tlsServer.URL, ok, "tls", ErrTLS},
+		{"auth", server.URL, func() (string, error) { return "", AuthError("invalid token") }, "auth", ErrAuth},
+		{"old server", server.URL, func() (string, error) { return "2.9.1", nil }, "version", ErrVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := doctorTool(t, tt.url, false, tt.version).Diagnose()
+			e, isErr := err.(*Error)
+			if !isErr || e.Code != tt.code {
+				t.Fatalf("error = %v, want %s", err, tt.code)
+			}
+			if got := checkStatus(report, tt.check); got != CheckFail {
+				t.Errorf("%s check = %q, want fail", tt.check, got)
+			}
+			if last := report.Checks[len(report.Checks)-1].Name; last != tt.check {
+				t.Errorf("checks continued past %s to %s", tt.check, last)
+			}
+		})
+	}
+}
+
+func TestDiagnoseInsecureWarns(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tool := doctorTool(t, server.URL, true, func() (string, error) { return "3.0", nil })
+	report, err := tool.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := checkStatus(report, "tls"); got != CheckWarn {
+		t.Errorf("tls = %q, want warn with --insecure", got)
+	}
+	if report.Status() != CheckWarn {
+		t.Errorf("status = %q, want warn", report.Status())
+	}
+}
+
+func TestSummarizeSkipsUnconfigured(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	configured := doctorTool(t, server.URL, false, func() (string, error) { return "1.0", nil })
+	t.Setenv("TEST_URL", server.URL)
+	other := &Tool{Name: "other", Context: configured.Context, Doctor: &Doctor{URLEnv: "OTHER_URL"}}
+
+	summary, err := Summarize([]*Tool{configured, other})
+	if e, ok := err.(*Error); !ok || e.Code != ErrVersion {
+		t.Fatalf("error = %v, want UNSUPPORTED_VERSION", err)
+	}
+	if got := summary.Services[0].Status; got != CheckFail {
+		t.Errorf("configured status = %q, want fail", got)
+	}
+	if got := summary.Services[1].Status; got != CheckSkip {
+		t.Errorf("unconfigured status = %q, want skip", got)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.19.4", "2.11", 1},
+		{"v2.9.0", "2.11", -1},
+		{"4.0.5 (a6fe2a64aa)", "2.80", 1},
+		{"3.0", "3.0.0", 0},
+		{"", "1.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ErrorCode string
+
+const (
+	ErrConfig   ErrorCode = "CONFIG_ERROR"
+	ErrAuth     ErrorCode = "AUTH_FAILED"
+	ErrNotFound ErrorCode = "NOT_FOUND"
+	ErrNetwork  ErrorCode = "NETWORK_ERROR"
+	ErrAPI      ErrorCode = "API_ERROR"
+
+	// Raised by doctor, which separates the connection failure classes
+	ErrDNS     ErrorCode = "DNS_ERROR"
+	ErrTLS     ErrorCode = "TLS_ERROR"
+	ErrVersion ErrorCode = "UNSUPPORTED_VERSION"
+
+	// Raised when deployed state differs from local files, e.g. stacks diff
+	ErrDrift ErrorCode = "DRIFT_DETECTED"
+)
+
+// Error is the structured error shared by all tools. Each tool package
+// aliases it under its own name (PortainerError, PveError, ...).
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Status  int      // HTTP status, when the error came from a response
+	Details []string // extra lines from the server, e.g. validation failures
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) ExitCode() int {
+	switch e.Code {
+	case ErrConfig:
+		return 1
+	case ErrAuth:
+		return 2
+	case ErrNotFound:
+		return 3
+	case ErrNetwork:
+		return 4
+	case ErrAPI:
+		return 5
+	case ErrDNS:
+		return 6
+	case ErrTLS:
+		return 7
+	case ErrVersion:
+		return 8
+	case ErrDrift:
+		return 9
+	default:
+		return 1
+	}
+}
+
+func ConfigError(msg string) *Error {
+	return &Error{Code: ErrConfig, Message: msg}
+}
+
+func AuthError(msg string) *Error {
+	return &Error{Code: ErrAuth, Message: msg}
+}
+
+func NotFoundError(msg string) *Error {
+	return &Error{Code: ErrNotFound, Message: msg}
+}
+
+func NetworkError(msg string) *Error {
+	return &Error{Code: ErrNetwork, Message: msg}
+}
+
+func APIError(msg string) *Error {
+	return &Error{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+}
+
+func DNSError(msg string) *Error {
+	return &Error{Code: ErrDNS, Message: msg}
+}
+
+func TLSError(msg string) *Error {
+	return &Error{Code: ErrTLS, Message: msg}
+}
+
+func VersionError(msg string) *Error {
+	return &Error{Code: ErrVersion, Message: msg}
+}
+
+func DriftError(msg string) *Error {
+	return &Error{Code: ErrDrift, Message: msg}
+}
+
+type ErrorOutput struct {
+	Error ErrorDetail `yaml:"error"`
+}
+
+type ErrorDetail struct {
+	Code    string   `yaml:"code"`
+	Status  int      `yaml:"status,omitempty"`
+	Message string   `yaml:"message"`
+	Details []string `yaml:"details,omitempty"`
+}
+
+// PrintError writes err to w as a YAML error document, the way every tool
+// reports failures. Errors outside the taxonomy are printed as plain text.
+func PrintError(w io.Writer, err error) {
+	e, ok := err.(*Error)
+	if !ok {
+		fmt.Fprintf(w, "error: %s\n", err)
+		return
+	}
+
+	output := ErrorOutput{
+		Error: ErrorDetail{
+			Code:    string(e.Code),
+			Status:  e.Status,
+			Message: e.Message,
+			Details: e.Details,
+		},
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(output); err != nil {
+		fmt.Fprintf(w, "error: %s\n", e.Message)
+	}
+}
+
+// ErrorParser extracts a message and details from a service's error body.
+// It returns "" when the body isn't in the expected envelope.
+type ErrorParser func(body []byte) (message string, details []string)
+
+// ParseErrorBody understands the common envelopes: {"message": ...},
+// {"error": "..."} and {"error": {"message": ...}}, falling back to a
+// short plain-text body.
+func ParseErrorBody(body []byte) (string, []string) {
+	var envelope struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		if envelope.Message != "" {
+			return strings.TrimSpace(envelope.Message), nil
+		}
+		var text string
+		if json.Unmarshal(envelope.Error, &text) == nil && text != "" {
+			return text, nil
+		}
+		var nested struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(envelope.Error, &nested) == nil && nested.Message != "" {
+			return nested.Message, nil
+		}
+		return "", nil
+	}
+
+	text := strings.TrimSpace(string(body))
+	if text == "" || strings.HasPrefix(text, "<") || strings.ContainsAny(text, "\n{[") || len(text) > 200 {
+		return "", nil
+	}
+	return text, nil
+}
+
+// ParseArrError reads the *arr (Sonarr, Radarr) v3 envelopes: a validation
+// array ([{"propertyName": ..., "errorMessage": ...}]) or {"message",
+// "description"}.
+func ParseArrError(body []byte) (string, []string) {
+	var failures []struct {
+		PropertyName string `json:"propertyName"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if json.Unmarshal(body, &failures) == nil && len(failures) > 0 {
+		details := make([]string, 0, len(failures))
+		for _, f := range failures {
+			if f.PropertyName == "" {
+				details = append(details, f.ErrorMessage)
+				continue
+			}
+			details = append(details, f.PropertyName+": "+f.ErrorMessage)
+		}
+		return "validation failed", details
+	}
+
+	var e struct {
+		Message     string `json:"message"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(body, &e) != nil || e.Message == "" {
+		return ParseErrorBody(body)
+	}
+	if e.Description != "" {
+		return e.Message, []string{e.Description}
+	}
+	return e.Message, nil
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestErrorExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *Error
+		expected int
+	}{
+		{"config", ConfigError("test"), 1},
+		{"auth", AuthError("test"), 2},
+		{"not_found", NotFoundError("test"), 3},
+		{"network", NetworkError("test"), 4},
+		{"api", APIError("test"), 5},
+		{"dns", DNSError("test"), 6},
+		{"tls", TLSError("test"), 7},
+		{"version", VersionError("test"), 8},
+		{"drift", DriftError("test"), 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.ExitCode(); got != tt.expected {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAPIErrorPrefix(t *testing.T) {
+	err := APIError("boom")
+	if err.Error() != "API error: boom" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "API error: boom")
+	}
+}
+
+func TestParseErrorBody(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"message":"stack not found"}`, "stack not found"},
+		{`{"error":"invalid token"}`, "invalid token"},
+		{`{"error":{"code":400,"message":"domain in use"}}`, "domain in use"},
+		{`{"data":null}`, ""},
+		{"Not Found\n", "Not Found"},
+		{"<html><body>502 Bad Gateway</body></html>", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got, _ := ParseErrorBody([]byte(tt.body)); got != tt.want {
+			t.Errorf("ParseErrorBody(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestParseArrError(t *testing.T) {
+	msg, details := ParseArrError([]byte(`[{"propertyName":"Path","errorMessage":"Path is already configured"},{"errorMessage":"Invalid"}]`))
+	if msg != "validation failed" || len(details) != 2 || details[0] != "Path: Path is already configured" || details[1] != "Invalid" {
+		t.Errorf("validation = %q %q", msg, details)
+	}
+
+	msg, details = ParseArrError([]byte(`{"message":"NotFound","description":"Series 12 does not exist"}`))
+	if msg != "NotFound" || len(details) != 1 || details[0] != "Series 12 does not exist" {
+		t.Errorf("message = %q %q", msg, details)
+	}
+
+	if msg, _ := ParseArrError([]byte(`{"error":"Unauthorized"}`)); msg != "Unauthorized" {
+		t.Errorf("fallback = %q", msg)
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	e := APIError("validation failed")
+	e.Status = 400
+	e.Details = []string{"Path: Path is already configured"}
+	PrintError(&buf, e)
+	want := "error:\n  code: API_ERROR\n  status: 400\n  message: 'API error: validation failed'\n  details:\n    - 'Path: Path is already configured'\n"
+	if buf.String() != want {
+		t.Errorf("PrintError =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	PrintError(&buf, errors.New("boom"))
+	if buf.String() != "error: boom\n" {
+		t.Errorf("plain error = %q", buf.String())
+	}
+}
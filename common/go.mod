@@ -1,3 +1,15 @@
 module github.com/schmoli/cli-tools/common
 
 go 1.21
+
+require (
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/term v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+)
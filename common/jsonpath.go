@@ -0,0 +1,209 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A small subset of kubectl's JSONPath: literal text mixed with {…}
+// expressions made of .field, [n], [*] and .* steps, plus quoted string
+// literals such as {"\n"}. Multiple matches are joined by spaces. Use
+// go-template for ranges and conditionals.
+
+type jsonPathSegment struct {
+	literal string
+	steps   []jsonPathStep // nil for literal text
+}
+
+type jsonPathStep struct {
+	field   string
+	index   int
+	isIndex bool
+	all     bool
+}
+
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+	for expr != "" {
+		open := strings.IndexByte(expr, '{')
+		if open < 0 {
+			segments = append(segments, jsonPathSegment{literal: expr})
+			break
+		}
+		if open > 0 {
+			segments = append(segments, jsonPathSegment{literal: expr[:open]})
+		}
+		end := strings.IndexByte(expr[open:], '}')
+		if end < 0 {
+			return nil, ConfigError(fmt.Sprintf("invalid jsonpath: unclosed { in %q", expr))
+		}
+		inner := strings.TrimSpace(expr[open+1 : open+end])
+		expr = expr[open+end+1:]
+
+		if strings.HasPrefix(inner, `"`) {
+			text, err := strconv.Unquote(inner)
+			if err != nil {
+				return nil, ConfigError(fmt.Sprintf("invalid jsonpath literal %s", inner))
+			}
+			segments = append(segments, jsonPathSegment{literal: text})
+			continue
+		}
+
+		steps, err := parseJSONPathSteps(inner)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, jsonPathSegment{steps: steps})
+	}
+	return segments, nil
+}
+
+func parseJSONPathSteps(path string) ([]jsonPathStep, error) {
+	if path != "" && path[0] == '$' {
+		path = path[1:]
+	}
+	if path == "" || (path[0] != '.' && path[0] != '[') {
+		return nil, ConfigError(fmt.Sprintf("invalid jsonpath %q: unsupported expression (use go-template for range/filters)", path))
+	}
+
+	steps := []jsonPathStep{}
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			n := strings.IndexAny(path, ".[")
+			if n < 0 {
+				n = len(path)
+			}
+			name := path[:n]
+			path = path[n:]
+			switch name {
+			case "":
+				// "{.}" selects the whole document
+			case "*":
+				steps = append(steps, jsonPathStep{all: true})
+			default:
+				steps = append(steps, jsonPathStep{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, ConfigError(fmt.Sprintf("invalid jsonpath: unclosed [ in %q", path))
+			}
+			inner := path[1:end]
+			path = path[end+1:]
+			if inner == "*" {
+				steps = append(steps, jsonPathStep{all: true})
+				continue
+			}
+			if strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`) {
+				steps = append(steps, jsonPathStep{field: strings.Trim(inner, `'"`)})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, ConfigError(fmt.Sprintf("invalid jsonpath index [%s]", inner))
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+		default:
+			return nil, ConfigError(fmt.Sprintf("invalid jsonpath near %q", path))
+		}
+	}
+	return steps, nil
+}
+
+func evalJSONPath(value interface{}, steps []jsonPathStep) []interface{} {
+	current := []interface{}{value}
+	for _, step := range steps {
+		var next []interface{}
+		for _, v := range current {
+			switch {
+			case step.all:
+				switch t := v.(type) {
+				case []interface{}:
+					next = append(next, t...)
+				case map[string]interface{}:
+					keys := make([]string, 0, len(t))
+					for k := range t {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, t[k])
+					}
+				}
+			case step.isIndex:
+				if list, ok := v.([]interface{}); ok {
+					i := step.index
+					if i < 0 {
+						i += len(list)
+					}
+					if i >= 0 && i < len(list) {
+						next = append(next, list[i])
+					}
+				}
+			default:
+				if m, ok := v.(map[string]interface{}); ok {
+					if child, ok := m[step.field]; ok {
+						next = append(next, child)
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func printJSONPath(w io.Writer, data interface{}, expr string) error {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	v, err := toValue(data)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg.steps == nil {
+			b.WriteString(seg.literal)
+			continue
+		}
+		results := evalJSONPath(v, seg.steps)
+		for i, r := range results {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(jsonPathString(r))
+		}
+	}
+	// End with a newline unless the expression already does
+	if s := b.String(); !strings.HasSuffix(s, "\n") {
+		b.WriteByte('\n')
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func jsonPathString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]interface{}, []interface{}:
+		out, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(out)
+	default:
+		return fmt.Sprint(t)
+	}
+}
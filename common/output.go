@@ -0,0 +1,498 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatYAML       Format = "yaml"
+	FormatJSON       Format = "json"
+	FormatTable      Format = "table"
+	FormatWide       Format = "wide"
+	FormatJSONPath   Format = "jsonpath"
+	FormatGoTemplate Format = "go-template"
+)
+
+// OutputFlagUsage is the help text for the --output flag.
+const OutputFlagUsage = "Output format: yaml|json|table|wide|jsonpath=<expr>|go-template=<tmpl>"
+
+// Column selects a field of a table row by its yaml key. Dots descend
+// into nested mappings.
+type Column struct {
+	Header string
+	Field  string
+	Wide   bool // only shown with -o wide
+}
+
+// Tabler is implemented by output types (usually the row type of a list)
+// to choose their default table columns.
+type Tabler interface {
+	TableColumns() []Column
+}
+
+// Printer renders curated output types in the format chosen by --output.
+type Printer struct {
+	Format Format
+	Expr   string // jsonpath expression or go template
+}
+
+// NewPrinter parses an --output value such as "json" or "jsonpath={.id}".
+func NewPrinter(spec string) (*Printer, error) {
+	name, expr, hasExpr := strings.Cut(spec, "=")
+	switch Format(name) {
+	case "", FormatYAML, FormatJSON, FormatTable, FormatWide:
+		if hasExpr {
+			return nil, ConfigError(fmt.Sprintf("output format %q takes no argument", name))
+		}
+		if name == "" {
+			name = string(FormatYAML)
+		}
+		return &Printer{Format: Format(name)}, nil
+	case FormatJSONPath, FormatGoTemplate:
+		if expr == "" {
+			return nil, ConfigError(fmt.Sprintf("output format %s requires an expression, e.g. %s=...", name, name))
+		}
+		p := &Printer{Format: Format(name), Expr: expr}
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, ConfigError(fmt.Sprintf("unknown output format %q (%s)", spec, OutputFlagUsage))
+	}
+}
+
+func (p *Printer) validate() error {
+	switch p.Format {
+	case FormatJSONPath:
+		_, err := parseJSONPath(p.Expr)
+		return err
+	case FormatGoTemplate:
+		if _, err := template.New("output").Parse(p.Expr); err != nil {
+			return ConfigError(fmt.Sprintf("invalid go-template: %s", err))
+		}
+	}
+	return nil
+}
+
+func (p *Printer) Print(w io.Writer, data interface{}) error {
+	switch p.Format {
+	case FormatJSON:
+		return printJSON(w, data)
+	case FormatTable:
+		return printTable(w, data, false)
+	case FormatWide:
+		return printTable(w, data, true)
+	case FormatJSONPath:
+		return printJSONPath(w, data, p.Expr)
+	case FormatGoTemplate:
+		return printTemplate(w, data, p.Expr)
+	default:
+		return printYAML(w, data)
+	}
+}
+
+func printYAML(w io.Writer, data interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(data); err != nil {
+		return APIError(fmt.Sprintf("failed to serialize: %s", err))
+	}
+	return enc.Close()
+}
+
+// toNode encodes data through its yaml tags, so every format uses the same
+// field names as the YAML output and keeps field order.
+func toNode(data interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(data); err != nil {
+		return nil, APIError(fmt.Sprintf("failed to serialize: %s", err))
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0], nil
+	}
+	return &node, nil
+}
+
+// toValue converts data into plain maps, slices and scalars keyed by yaml tag.
+func toValue(data interface{}) (interface{}, error) {
+	node, err := toNode(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, APIError(fmt.Sprintf("failed to serialize: %s", err))
+	}
+	return v, nil
+}
+
+func printJSON(w io.Writer, data interface{}) error {
+	node, err := toNode(data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, node); err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return APIError(fmt.Sprintf("failed to serialize: %s", err))
+	}
+	out.WriteByte('\n')
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		buf.Write(jsonScalar(node))
+	}
+	return nil
+}
+
+func jsonScalar(node *yaml.Node) []byte {
+	switch node.ShortTag() {
+	case "!!null":
+		return []byte("null")
+	case "!!bool":
+		if b, err := strconv.ParseBool(node.Value); err == nil {
+			return []byte(strconv.FormatBool(b))
+		}
+	case "!!int":
+		if n, err := strconv.ParseInt(node.Value, 0, 64); err == nil {
+			return []byte(strconv.FormatInt(n, 10))
+		}
+	case "!!float":
+		if f, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			if out, err := json.Marshal(f); err == nil {
+				return out
+			}
+		}
+	}
+	out, _ := json.Marshal(node.Value)
+	return out
+}
+
+func printTable(w io.Writer, data interface{}, wide bool) error {
+	node, err := toNode(data)
+	if err != nil {
+		return err
+	}
+	rows := tableRows(node)
+
+	columns := tableColumns(data)
+	if columns == nil {
+		columns = defaultColumns(rows, wide)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	var headers []string
+	for _, col := range columns {
+		if col.Wide && !wide {
+			continue
+		}
+		headers = append(headers, col.Header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		var cells []string
+		for _, col := range columns {
+			if col.Wide && !wide {
+				continue
+			}
+			cells = append(cells, cellValue(lookupNode(row, col.Field)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableRows finds the rows in an encoded value: a top-level list, the first
+// list inside a wrapper such as {stacks: [...]}, the single object inside a
+// wrapper such as {torrent: {...}}, or the object itself.
+func tableRows(node *yaml.Node) []*yaml.Node {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return node.Content
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if node.Content[i].Kind == yaml.SequenceNode {
+				return node.Content[i].Content
+			}
+		}
+		if len(node.Content) == 2 && node.Content[1].Kind == yaml.MappingNode {
+			return []*yaml.Node{node.Content[1]}
+		}
+	}
+	return []*yaml.Node{node}
+}
+
+// tableColumns looks for a Tabler on data or, mirroring tableRows, on the
+// row type it wraps.
+func tableColumns(data interface{}) []Column {
+	if t, ok := data.(Tabler); ok {
+		return t.TableColumns()
+	}
+	if data == nil {
+		return nil
+	}
+	if t, ok := reflect.Zero(rowType(reflect.TypeOf(data))).Interface().(Tabler); ok {
+		return t.TableColumns()
+	}
+	return nil
+}
+
+func rowType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if k := t.Field(i).Type.Kind(); k == reflect.Slice || k == reflect.Array {
+				return t.Field(i).Type.Elem()
+			}
+		}
+		if t.NumField() == 1 && t.Field(0).Type.Kind() == reflect.Struct {
+			return t.Field(0).Type
+		}
+	}
+	return t
+}
+
+// defaultColumns uses every scalar field of the rows (every field for wide).
+func defaultColumns(rows []*yaml.Node, wide bool) []Column {
+	var columns []Column
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if row.Kind != yaml.MappingNode {
+			return []Column{{Header: "VALUE"}}
+		}
+		for i := 0; i+1 < len(row.Content); i += 2 {
+			key := row.Content[i].Value
+			if seen[key] || (!wide && row.Content[i+1].Kind != yaml.ScalarNode) {
+				continue
+			}
+			seen[key] = true
+			columns = append(columns, Column{Header: headerName(key), Field: key})
+		}
+	}
+	return columns
+}
+
+// headerName turns a yaml key like "endpointId" into "ENDPOINT ID".
+func headerName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+func lookupNode(node *yaml.Node, field string) *yaml.Node {
+	if field == "" {
+		return node
+	}
+	for _, key := range strings.Split(field, ".") {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+func cellValue(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!null" {
+			return ""
+		}
+		return node.Value
+	case yaml.SequenceNode:
+		var parts []string
+		for _, child := range node.Content {
+			if child.Kind != yaml.ScalarNode {
+				return flowYAML(node)
+			}
+			parts = append(parts, child.Value)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return flowYAML(node)
+	}
+}
+
+func flowYAML(node *yaml.Node) string {
+	copied := *node
+	copied.Style = yaml.FlowStyle
+	out, err := yaml.Marshal(&copied)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func printTemplate(w io.Writer, data interface{}, expr string) error {
+	tmpl, err := template.New("output").Parse(expr)
+	if err != nil {
+		return ConfigError(fmt.Sprintf("invalid go-template: %s", err))
+	}
+	v, err := toValue(data)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, v); err != nil {
+		return ConfigError(fmt.Sprintf("go-template failed: %s", err))
+	}
+	return nil
+}
+
+// RecordWriter prints a stream of records, such as events, as each one
+// arrives: JSON as one compact line per record, YAML as a document per
+// record, and tables as rows under a header printed once. The first
+// record fixes the table columns; they widen as longer values arrive,
+// since earlier rows are already out.
+type RecordWriter struct {
+	p       *Printer
+	w       io.Writer
+	columns []Column
+	widths  []int
+}
+
+// Records starts a record stream on w.
+func (p *Printer) Records(w io.Writer) *RecordWriter {
+	return &RecordWriter{p: p, w: w}
+}
+
+func (r *RecordWriter) Write(data interface{}) error {
+	switch r.p.Format {
+	case FormatJSON:
+		node, err := toNode(data)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := writeJSONNode(&buf, node); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err = r.w.Write(buf.Bytes())
+		return err
+	case FormatTable, FormatWide:
+		return r.writeRow(data, r.p.Format == FormatWide)
+	case FormatJSONPath, FormatGoTemplate:
+		return r.p.Print(r.w, data)
+	default:
+		if _, err := io.WriteString(r.w, "---\n"); err != nil {
+			return err
+		}
+		return printYAML(r.w, data)
+	}
+}
+
+func (r *RecordWriter) writeRow(data interface{}, wide bool) error {
+	node, err := toNode(data)
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	if r.columns == nil {
+		r.columns = []Column{}
+		columns := tableColumns(data)
+		if columns == nil {
+			columns = defaultColumns([]*yaml.Node{node}, wide)
+		}
+		var headers []string
+		for _, col := range columns {
+			if col.Wide && !wide {
+				continue
+			}
+			r.columns = append(r.columns, col)
+			headers = append(headers, col.Header)
+			r.widths = append(r.widths, max(len(col.Header), len(cellValue(lookupNode(node, col.Field)))))
+		}
+		r.writeCells(&out, headers)
+	}
+
+	cells := make([]string, len(r.columns))
+	for i, col := range r.columns {
+		cells[i] = cellValue(lookupNode(node, col.Field))
+	}
+	r.writeCells(&out, cells)
+	_, err = io.WriteString(r.w, out.String())
+	return err
+}
+
+// writeCells pads each cell like printTable's tabwriter, leaving the last
+// one unpadded.
+func (r *RecordWriter) writeCells(out *strings.Builder, cells []string) {
+	for i, cell := range cells {
+		if i == len(cells)-1 {
+			out.WriteString(cell)
+			break
+		}
+		r.widths[i] = max(r.widths[i], len(cell))
+		out.WriteString(cell + strings.Repeat(" ", r.widths[i]-len(cell)+3))
+	}
+	out.WriteByte('\n')
+}
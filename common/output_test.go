@@ -0,0 +1,253 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	ID     int64    `yaml:"id"`
+	Name   string   `yaml:"name"`
+	Tags   []string `yaml:"tags,omitempty"`
+	Status string   `yaml:"status"`
+}
+
+func (testItem) TableColumns() []Column {
+	return []Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TAGS", Field: "tags", Wide: true},
+	}
+}
+
+type testList struct {
+	Items []testItem `yaml:"items"`
+	Total int        `yaml:"total"`
+}
+
+type untyped struct {
+	ID         int64  `yaml:"id"`
+	EndpointID int64  `yaml:"endpointId"`
+	Nested     []int  `yaml:"nested"`
+	Label      string `yaml:"label"`
+}
+
+func render(t *testing.T, spec string, data interface{}) string {
+	t.Helper()
+	p, err := NewPrinter(spec)
+	if err != nil {
+		t.Fatalf("NewPrinter(%q): %v", spec, err)
+	}
+	var buf bytes.Buffer
+	if err := p.Print(&buf, data); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	return buf.String()
+}
+
+var sample = testList{
+	Items: []testItem{
+		{ID: 1, Name: "alpha", Tags: []string{"a", "b"}, Status: "ok"},
+		{ID: 22, Name: "beta", Status: "down"},
+	},
+	Total: 2,
+}
+
+func TestNewPrinterFormats(t *testing.T) {
+	tests := []struct {
+		spec   string
+		format Format
+		expr   string
+	}{
+		{"", FormatYAML, ""},
+		{"yaml", FormatYAML, ""},
+		{"json", FormatJSON, ""},
+		{"table", FormatTable, ""},
+		{"wide", FormatWide, ""},
+		{"jsonpath={.items[0].name}", FormatJSONPath, "{.items[0].name}"},
+		{"go-template={{.total}}", FormatGoTemplate, "{{.total}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			p, err := NewPrinter(tt.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Format != tt.format || p.Expr != tt.expr {
+				t.Errorf("got %q/%q, want %q/%q", p.Format, p.Expr, tt.format, tt.expr)
+			}
+		})
+	}
+}
+
+func TestNewPrinterInvalid(t *testing.T) {
+	for _, spec := range []string{"xml", "json=x", "jsonpath=", "jsonpath={.a", "go-template={{.a"} {
+		_, err := NewPrinter(spec)
+		e, ok := err.(*Error)
+		if !ok || e.Code != ErrConfig {
+			t.Errorf("NewPrinter(%q) = %v, want CONFIG_ERROR", spec, err)
+		}
+	}
+}
+
+func TestPrintYAML(t *testing.T) {
+	out := render(t, "yaml", sample)
+	if !strings.Contains(out, "items:\n  - id: 1\n    name: alpha") {
+		t.Errorf("unexpected yaml:\n%s", out)
+	}
+}
+
+func TestPrintJSONUsesYAMLKeysInOrder(t *testing.T) {
+	out := render(t, "json", sample.Items[1])
+	want := "{\n  \"id\": 22,\n  \"name\": \"beta\",\n  \"status\": \"down\"\n}\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestPrintJSONScalarTypes(t *testing.T) {
+	data := struct {
+		Flag  bool    `yaml:"flag"`
+		Ratio float64 `yaml:"ratio"`
+		Num   string  `yaml:"num"`
+		Ptr   *int    `yaml:"ptr"`
+	}{Flag: true, Ratio: 1.5, Num: "123"}
+
+	out := render(t, "json", data)
+	for _, want := range []string{`"flag": true`, `"ratio": 1.5`, `"num": "123"`, `"ptr": null`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintTableUsesTablerColumns(t *testing.T) {
+	out := render(t, "table", sample)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out)
+	}
+	if strings.Fields(lines[0])[0] != "ID" || strings.Contains(lines[0], "TAGS") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if strings.Fields(lines[2])[1] != "beta" {
+		t.Errorf("unexpected row: %q", lines[2])
+	}
+}
+
+func TestPrintWideAddsWideColumns(t *testing.T) {
+	out := render(t, "wide", sample)
+	if !strings.Contains(out, "TAGS") || !strings.Contains(out, "a,b") {
+		t.Errorf("wide output missing tags:\n%s", out)
+	}
+}
+
+func TestPrintTableDefaultColumns(t *testing.T) {
+	out := render(t, "table", []untyped{{ID: 1, EndpointID: 2, Nested: []int{1}, Label: "x"}})
+	header := strings.Split(out, "\n")[0]
+	if !strings.Contains(header, "ENDPOINT ID") {
+		t.Errorf("header missing derived name: %q", header)
+	}
+	if strings.Contains(header, "NESTED") {
+		t.Errorf("table should skip nested fields: %q", header)
+	}
+
+	wide := render(t, "wide", []untyped{{ID: 1, Nested: []int{1}}})
+	if !strings.Contains(wide, "NESTED") {
+		t.Errorf("wide should include nested fields:\n%s", wide)
+	}
+}
+
+func TestPrintTableSingleObject(t *testing.T) {
+	out := render(t, "table", struct {
+		Item testItem `yaml:"item"`
+	}{Item: sample.Items[0]})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "alpha") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+}
+
+func TestPrintJSONPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"{.total}", "2\n"},
+		{"{.items[0].name}", "alpha\n"},
+		{"{.items[-1].id}", "22\n"},
+		{"{.items[*].name}", "alpha beta\n"},
+		{`{.items[0].id}{"\t"}{.items[0].status}{"\n"}`, "1\tok\n"},
+		{"{.items[0].tags}", `["a","b"]` + "\n"},
+		{"{.missing}", "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := render(t, "jsonpath="+tt.expr, sample); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintGoTemplate(t *testing.T) {
+	out := render(t, "go-template={{range .items}}{{.id}}:{{.name}} {{end}}", sample)
+	if out != "1:alpha 22:beta " {
+		t.Errorf("got %q", out)
+	}
+}
+
+func renderRecords(t *testing.T, spec string, records ...interface{}) string {
+	t.Helper()
+	p, err := NewPrinter(spec)
+	if err != nil {
+		t.Fatalf("NewPrinter(%q): %v", spec, err)
+	}
+	var buf bytes.Buffer
+	w := p.Records(&buf)
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	return buf.String()
+}
+
+func TestRecordsJSONLines(t *testing.T) {
+	out := renderRecords(t, "json", sample.Items[0], sample.Items[1])
+	want := `{"id":1,"name":"alpha","tags":["a","b"],"status":"ok"}` + "\n" + `{"id":22,"name":"beta","status":"down"}` + "\n"
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRecordsYAMLDocuments(t *testing.T) {
+	out := renderRecords(t, "yaml", sample.Items[0], sample.Items[1])
+	if strings.Count(out, "---\n") != 2 || !strings.Contains(out, "name: beta") {
+		t.Errorf("unexpected YAML stream:\n%s", out)
+	}
+}
+
+func TestRecordsTableKeepsFirstColumns(t *testing.T) {
+	type record struct {
+		Name   string `yaml:"name"`
+		Code   string `yaml:"code,omitempty"`
+		Signal string `yaml:"signal,omitempty"`
+	}
+	out := renderRecords(t, "table", record{Name: "alpha"}, record{Name: "beta", Code: "137", Signal: "KILL"}, record{Name: "gamma-ray"})
+	want := "NAME\nalpha\nbeta\ngamma-ray\n"
+	if out != want {
+		t.Errorf("got:\n%q\nwant:\n%q", out, want)
+	}
+}
+
+func TestRecordsTable(t *testing.T) {
+	out := renderRecords(t, "table", testItem{ID: 4444, Name: "alpha"}, testItem{ID: 55555, Name: "beta"})
+	want := "ID     NAME\n4444   alpha\n55555   beta\n"
+	if out != want {
+		t.Errorf("got:\n%q\nwant:\n%q", out, want)
+	}
+}
@@ -0,0 +1,76 @@
+package common
+
+import "testing"
+
+func TestRedactorSecretName(t *testing.T) {
+	r := NewRedactor(nil)
+	for name, want := range map[string]bool{
+		"DB_PASSWORD":      true,
+		"github_token":     true,
+		"JWT_SECRET":       true,
+		"API_KEY":          true,
+		"KEYCLOAK_URL":     true, // *KEY* is broad on purpose
+		"TZ":               false,
+		"PUID":             false,
+		"POSTGRES_USER":    false,
+		"TRANSMISSION_WEB": false,
+	} {
+		if got := r.SecretName(name); got != want {
+			t.Errorf("SecretName(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	custom := NewRedactor(ParsePatterns(" *PASS*, DSN "))
+	if !custom.SecretName("smtp_pass") || !custom.SecretName("dsn") || custom.SecretName("API_KEY") {
+		t.Error("custom patterns should replace the defaults")
+	}
+}
+
+func TestRedactorValue(t *testing.T) {
+	r := NewRedactor(nil)
+	tests := []struct {
+		name, value, want string
+	}{
+		{"DB_PASSWORD", "hunter2", redacted},
+		{"TZ", "Europe/London", "Europe/London"},
+		{"IMAGE", "linuxserver/sonarr:4.0.2.1183", "linuxserver/sonarr:4.0.2.1183"},
+		{"VERSION", "mariadb-10.11.2-ubuntu-jammy", "mariadb-10.11.2-ubuntu-jammy"},
+		{"INSTANCE_ID", "1facc0c8-3e77-43d2-9c53-b571f66131ab", "1facc0c8-3e77-43d2-9c53-b571f66131ab"},
+		{"GH_PAT", "ghp_G-rwoFnK09QL-r4EYqKTOaZCl6LvqW8x2gue", redacted},
+		{"SESSION", "cfC55SrQq2FxidoPKxRp5qQF2SKzz4Ul", redacted},
+		{"HASH", "0c90e5134bb4e8461daf5cd92704424d", redacted},
+		{"DATABASE_URL", "postgres://app:s3cr%40t@db:5432/app?sslmode=disable", "postgres://app:[REDACTED]@db:5432/app?sslmode=disable"},
+		{"UPSTREAM", "http://user@proxy:3128", "http://user@proxy:3128"},
+		{"EMPTY_PASSWORD", "", ""},
+	}
+	for _, tt := range tests {
+		if got := r.Value(tt.name, tt.value); got != tt.want {
+			t.Errorf("Value(%q, %q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNilRedactorShowsEverything(t *testing.T) {
+	var r *Redactor
+	if got := r.Value("DB_PASSWORD", "hunter2"); got != "hunter2" {
+		t.Errorf("nil redactor masked %q", got)
+	}
+}
+
+func TestMatchesMasked(t *testing.T) {
+	tests := []struct {
+		masked, value string
+		want          bool
+	}{
+		{"[REDACTED]", "hunter2", true},
+		{"postgres://app:[REDACTED]@db/app", "postgres://app:pw@db/app", true},
+		{"postgres://app:[REDACTED]@db/app", "postgres://app:pw@db2/app", false},
+		{"hunter2", "hunter2", false},
+		{"x[REDACTED]", "y", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesMasked(tt.masked, tt.value); got != tt.want {
+			t.Errorf("MatchesMasked(%q, %q) = %v, want %v", tt.masked, tt.value, got, tt.want)
+		}
+	}
+}
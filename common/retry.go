@@ -0,0 +1,102 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetries   = 2
+	DefaultRetryWait = 500 * time.Millisecond
+
+	// maxRetryWait caps both the backoff and any Retry-After from the server
+	maxRetryWait = 30 * time.Second
+)
+
+// RetryPolicy controls how transient failures are retried: network errors
+// and 429/502/503/504 responses. Only idempotent requests are retried
+// unless Unsafe is set.
+type RetryPolicy struct {
+	Retries int           // attempts after the first
+	Wait    time.Duration // base backoff, doubled on each attempt
+	Unsafe  bool          // also retry non-idempotent requests such as POST
+}
+
+var retryPolicy = RetryPolicy{Retries: DefaultRetries, Wait: DefaultRetryWait}
+
+// sleep is swapped out in tests
+var sleep = time.Sleep
+
+// SetRetryPolicy sets the policy for clients created afterwards, typically
+// from the --retries, --retry-wait and --retry-unsafe flags.
+func SetRetryPolicy(p RetryPolicy) error {
+	if p.Retries < 0 {
+		return ConfigError(fmt.Sprintf("invalid --retries %d: must be 0 or more", p.Retries))
+	}
+	if p.Wait < 0 {
+		return ConfigError(fmt.Sprintf("invalid --retry-wait %s: must be 0 or more", p.Wait))
+	}
+	retryPolicy = p
+	return nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before retry n (0-based): Wait*2^n with up to
+// 50% jitter, or the server's Retry-After if it asked for longer.
+func (p RetryPolicy) backoff(n int, resp *http.Response) time.Duration {
+	wait := p.Wait << uint(n)
+	if wait <= 0 || wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	if p.Wait == 0 {
+		wait = 0
+	}
+	if wait > 0 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok && after > wait {
+			wait = after
+		}
+	}
+	if wait > maxRetryWait {
+		wait = maxRetryWait
+	}
+	return wait
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces the backoff sleep for the duration of a test.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	t.Cleanup(func() { sleep = orig })
+	return &waits
+}
+
+// flakyServer fails the first n requests with status, then returns {}.
+func flakyServer(t *testing.T, n, status int, header http.Header) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClientRetriesGet(t *testing.T) {
+	waits := recordSleeps(t)
+	server, calls := flakyServer(t, 2, http.StatusBadGateway, nil)
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 2, Wait: 100 * time.Millisecond}
+	if err := client.Get("/thing", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want 3", *calls)
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("waits = %v, want 2", *waits)
+	}
+	// Exponential with up to 50% jitter: [50ms,100ms] then [100ms,200ms]
+	if w := (*waits)[0]; w < 50*time.Millisecond || w > 100*time.Millisecond {
+		t.Errorf("first wait = %s", w)
+	}
+	if w := (*waits)[1]; w < 100*time.Millisecond || w > 200*time.Millisecond {
+		t.Errorf("second wait = %s", w)
+	}
+}
+
+func TestClientRetryExhausted(t *testing.T) {
+	recordSleeps(t)
+	server, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 1, Wait: time.Millisecond}
+	err := client.Get("/thing", nil)
+	e, ok := err.(*Error)
+	if !ok || e.Code != ErrAPI {
+		t.Fatalf("expected API_ERROR, got %v", err)
+	}
+	if !strings.Contains(e.Message, "after 1 retries") {
+		t.Errorf("message = %q", e.Message)
+	}
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2", *calls)
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	recordSleeps(t)
+	server, calls := flakyServer(t, 1, http.StatusBadGateway, nil)
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 3, Wait: time.Millisecond}
+	if err := client.Post("/start", nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+
+	client.Retry.Unsafe = true
+	*calls = 0
+	server2, calls2 := flakyServer(t, 1, http.StatusBadGateway, nil)
+	client.BaseURL = server2.URL
+	if err := client.Post("/start", nil, nil); err != nil {
+		t.Errorf("expected retry with Unsafe, got %v", err)
+	}
+	if *calls2 != 2 {
+		t.Errorf("calls = %d, want 2", *calls2)
+	}
+}
+
+func TestClientPostIdempotentRetries(t *testing.T) {
+	recordSleeps(t)
+	server, calls := flakyServer(t, 1, http.StatusGatewayTimeout, nil)
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 1, Wait: time.Millisecond}
+	if err := client.PostIdempotent("/rpc", map[string]string{"method": "torrent-get"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("calls = %d, want 2", *calls)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	recordSleeps(t)
+	server, calls := flakyServer(t, 1, http.StatusInternalServerError, nil)
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 2, Wait: time.Millisecond}
+	if err := client.Get("/thing", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	waits := recordSleeps(t)
+	server, _ := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}})
+
+	client := NewClient(server.URL, nil, Options{})
+	client.Retry = RetryPolicy{Retries: 1, Wait: time.Millisecond}
+	if err := client.Get("/thing", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 3*time.Second {
+		t.Errorf("waits = %v, want [3s]", *waits)
+	}
+}
+
+func TestClientRetriesNetworkError(t *testing.T) {
+	waits := recordSleeps(t)
+
+	client := NewClient("http://127.0.0.1:1", nil, Options{})
+	client.Retry = RetryPolicy{Retries: 2, Wait: time.Millisecond}
+	err := client.Get("/thing", nil)
+	e, ok := err.(*Error)
+	if !ok || e.Code != ErrNetwork {
+		t.Fatalf("expected NETWORK_ERROR, got %v", err)
+	}
+	if len(*waits) != 2 {
+		t.Errorf("waits = %v, want 2", *waits)
+	}
+}
+
+func TestSetRetryPolicyValidates(t *testing.T) {
+	orig := retryPolicy
+	t.Cleanup(func() { retryPolicy = orig })
+
+	if err := SetRetryPolicy(RetryPolicy{Retries: -1}); err == nil {
+		t.Error("expected error for negative retries")
+	}
+	if err := SetRetryPolicy(RetryPolicy{Retries: 5, Wait: time.Second}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := NewClient("http://example.com", nil, Options{}); c.Retry.Retries != 5 {
+		t.Errorf("Retries = %d, want 5", c.Retry.Retries)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	d, ok := retryAfter(time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat))
+	if !ok || d < 8*time.Second || d > 10*time.Second {
+		t.Errorf("retryAfter = %s, %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("expected invalid Retry-After to be ignored")
+	}
+}
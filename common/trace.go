@@ -0,0 +1,199 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Trace levels for -v, -vv and --debug
+const (
+	TraceOff     = 0
+	TraceRequest = 1 // method, URL, status and latency
+	TraceBody    = 2 // plus headers and truncated bodies
+)
+
+// maxTraceBody is how much of each body is logged
+const maxTraceBody = 2048
+
+const redacted = "[REDACTED]"
+
+var (
+	traceLevel             = TraceOff
+	traceOut     io.Writer = os.Stderr
+	secretHeader           = map[string]bool{
+		"Authorization":       true,
+		"Proxy-Authorization": true,
+		"X-Api-Key":           true,
+		"Cookie":              true,
+		"Set-Cookie":          true,
+	}
+	secretParam = regexp.MustCompile(`(?i)^(api_?key|token|password|pass|secret)$`)
+	secretField = regexp.MustCompile(`(?i)("(?:api_?key|token|password|pass|secret|jwt)"\s*:\s*)"[^"]*"`)
+)
+
+// SetTrace enables HTTP tracing to stderr for clients created afterwards.
+func SetTrace(level int) {
+	traceLevel = level
+}
+
+// traceTransport logs each round trip with secrets redacted.
+type traceTransport struct {
+	base  http.RoundTripper
+	level int
+	out   io.Writer
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, redactURL(req.URL))
+	if t.level >= TraceBody {
+		writeHeaders(&b, req.Header)
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				data, _ := io.ReadAll(body)
+				writeBody(&b, data)
+			}
+		}
+	}
+	io.WriteString(t.out, b.String())
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.out, "<-- %s %s failed after %s: %s\n", req.Method, redactURL(req.URL), elapsed, err)
+		return nil, err
+	}
+
+	b.Reset()
+	fmt.Fprintf(&b, "<-- %s %s (%s)\n", resp.Status, redactURL(req.URL), elapsed)
+	// An upgraded connection (websocket) is not logged past the handshake
+	if t.level < TraceBody || resp.StatusCode == http.StatusSwitchingProtocols {
+		io.WriteString(t.out, b.String())
+		return resp, nil
+	}
+	writeHeaders(&b, resp.Header)
+
+	// Error bodies are small and otherwise discarded, so read them now.
+	// Success bodies may be streams; log what the caller reads on Close.
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxTraceBody+1))
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		writeBody(&b, data)
+		io.WriteString(t.out, b.String())
+		return resp, nil
+	}
+	io.WriteString(t.out, b.String())
+	resp.Body = &traceBody{ReadCloser: resp.Body, out: t.out}
+	return resp, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// traceBody keeps the first maxTraceBody bytes read and logs them on Close.
+type traceBody struct {
+	io.ReadCloser
+	out    io.Writer
+	buf    bytes.Buffer
+	logged bool
+}
+
+func (t *traceBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if room := maxTraceBody + 1 - t.buf.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		t.buf.Write(p[:room])
+	}
+	return n, err
+}
+
+func (t *traceBody) Close() error {
+	if !t.logged && t.buf.Len() > 0 {
+		t.logged = true
+		var b strings.Builder
+		writeBody(&b, t.buf.Bytes())
+		io.WriteString(t.out, b.String())
+	}
+	return t.ReadCloser.Close()
+}
+
+func writeHeaders(b *strings.Builder, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range h[name] {
+			if secretHeader[http.CanonicalHeaderKey(name)] {
+				v = redactHeaderValue(v)
+			}
+			fmt.Fprintf(b, "    %s: %s\n", name, v)
+		}
+	}
+}
+
+func writeBody(b *strings.Builder, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	truncated := len(data) > maxTraceBody
+	if truncated {
+		data = data[:maxTraceBody]
+	}
+	body := RedactBody(string(data))
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		fmt.Fprintf(b, "    %s\n", line)
+	}
+	if truncated {
+		fmt.Fprintf(b, "    ... (truncated at %d bytes)\n", maxTraceBody)
+	}
+}
+
+// redactHeaderValue keeps the auth scheme ("Bearer", "Basic",
+// "PVEAPIToken") so the trace still shows which strategy was used.
+func redactHeaderValue(v string) string {
+	if scheme, _, ok := strings.Cut(v, " "); ok && !strings.ContainsAny(scheme, "=;") {
+		return scheme + " " + redacted
+	}
+	if scheme, _, ok := strings.Cut(v, "="); ok && scheme == "PVEAPIToken" {
+		return scheme + "=" + redacted
+	}
+	return redacted
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	if c.User != nil {
+		c.User = url.User(c.User.Username())
+	}
+	if c.RawQuery != "" {
+		params := strings.Split(c.RawQuery, "&")
+		for i, param := range params {
+			if key, _, ok := strings.Cut(param, "="); ok && secretParam.MatchString(key) {
+				params[i] = key + "=" + redacted
+			}
+		}
+		c.RawQuery = strings.Join(params, "&")
+	}
+	return c.String()
+}
+
+// RedactBody masks JSON string values of secret-looking fields such as
+// "token", "password" and "apiKey".
+func RedactBody(body string) string {
+	return secretField.ReplaceAllString(body, `$1"`+redacted+`"`)
+}
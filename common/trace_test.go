@@ -0,0 +1,146 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// traceTo enables tracing into a buffer for the duration of a test.
+func traceTo(t *testing.T, level int) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	origLevel, origOut := traceLevel, traceOut
+	traceLevel, traceOut = level, &buf
+	t.Cleanup(func() { traceLevel, traceOut = origLevel, origOut })
+	return &buf
+}
+
+func TestTraceRequestLevel(t *testing.T) {
+	out := traceTo(t, TraceRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, BearerAuth{Token: "jwt-secret"}, Options{})
+	if err := client.Get("/api/things?apikey=k1&page=2", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	log := out.String()
+	if !strings.Contains(log, "--> GET "+server.URL+"/api/things?apikey=[REDACTED]&page=2") {
+		t.Errorf("missing request line:\n%s", log)
+	}
+	if !strings.Contains(log, "<-- 200 OK") {
+		t.Errorf("missing status line:\n%s", log)
+	}
+	if strings.Contains(log, "Authorization") || strings.Contains(log, `"ok"`) {
+		t.Errorf("headers and bodies should only be logged at TraceBody:\n%s", log)
+	}
+}
+
+func TestTraceBodyRedactsSecrets(t *testing.T) {
+	out := traceTo(t, TraceBody)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message":"boom","token":"leaked"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, PVETokenAuth{TokenID: "root@pam!cli", Secret: "pve-secret"}, Options{})
+	client.Retry = RetryPolicy{}
+	body := map[string]string{"identity": "me", "secret": "hunter2"}
+	if err := client.Post("/login", body, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	log := out.String()
+	for _, secret := range []string{"pve-secret", "root@pam", "hunter2", "leaked"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("trace leaked %q:\n%s", secret, log)
+		}
+	}
+	for _, want := range []string{"Authorization: PVEAPIToken=[REDACTED]", `"identity":"me"`, `"message":"boom"`, "<-- 500 Internal Server Error"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("trace missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestTraceBodyLogsSuccessBodyOnClose(t *testing.T) {
+	out := traceTo(t, TraceBody)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`"` + strings.Repeat("x", maxTraceBody+10) + `"`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, APIKeyAuth{Header: "X-Api-Key", Key: "abc"}, Options{})
+	var result interface{}
+	client.Get("/big", &result)
+
+	log := out.String()
+	if !strings.Contains(log, "X-Api-Key: [REDACTED]") {
+		t.Errorf("api key not redacted:\n%s", log)
+	}
+	if !strings.Contains(log, "truncated at 2048 bytes") {
+		t.Errorf("body not truncated:\n%s", log)
+	}
+}
+
+func TestRedactHeaderValue(t *testing.T) {
+	tests := map[string]string{
+		"Bearer abc":                "Bearer [REDACTED]",
+		"Basic dXNlcjpwYXNz":        "Basic [REDACTED]",
+		"PVEAPIToken=root@pam!x=yz": "PVEAPIToken=[REDACTED]",
+		"plainkey":                  "[REDACTED]",
+		"session=abc; other=def":    "[REDACTED]",
+	}
+	for in, want := range tests {
+		if got := redactHeaderValue(in); got != want {
+			t.Errorf("redactHeaderValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRedactURLUserinfo(t *testing.T) {
+	u, _ := url.Parse("http://admin:pw@host/rpc?token=t&x=1")
+	if got := redactURL(u); got != "http://admin@host/rpc?token=[REDACTED]&x=1" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestTraceLeavesOutUploads(t *testing.T) {
+	out := traceTo(t, TraceBody)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("password=hunter2"))
+		pw.Close()
+	}()
+	body, err := client.Upload(context.Background(), http.MethodPost, "/restore", http.Header{"Content-Type": {"text/plain"}}, pr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	body.Close()
+	if string(got) != "password=hunter2" {
+		t.Errorf("server got %q", got)
+	}
+
+	request, _, _ := strings.Cut(out.String(), "<--")
+	if !strings.Contains(request, "--> POST") || strings.Contains(request, "hunter2") {
+		t.Errorf("upload body should not be traced:\n%s", out.String())
+	}
+}
@@ -0,0 +1,256 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is appended to the handshake key (RFC 6455 section 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxFrame bounds a single incoming frame so a bad peer can't exhaust memory
+const maxFrame = 16 << 20
+
+// WebSocket frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// WebSocket is a minimal RFC 6455 connection carrying a byte stream, as
+// used for terminal sessions: Read returns the payloads of text and binary
+// messages in order, and each Write is sent as one binary message. Pings
+// are answered while reading.
+type WebSocket struct {
+	conn    io.ReadWriteCloser
+	r       *bufio.Reader
+	client  bool // clients mask the frames they send
+	mu      sync.Mutex
+	pending []byte
+	closed  bool
+}
+
+func newWebSocket(conn io.ReadWriteCloser, r *bufio.Reader, client bool) *WebSocket {
+	if r == nil {
+		r = bufio.NewReader(conn)
+	}
+	return &WebSocket{conn: conn, r: r, client: client}
+}
+
+// Websocket opens a websocket on path with the client's auth, TLS settings
+// and tracing. The client timeout does not apply; Close the connection or
+// cancel ctx to end it.
+func (c *Client) Websocket(ctx context.Context, path string) (*WebSocket, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, NetworkError(fmt.Sprintf("websocket key: %s", err))
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	header := http.Header{
+		"Upgrade":               {"websocket"},
+		"Connection":            {"Upgrade"},
+		"Sec-WebSocket-Key":     {key},
+		"Sec-WebSocket-Version": {"13"},
+	}
+
+	hc := *c.HTTPClient
+	hc.Timeout = 0
+	resp, err := c.attempt(ctx, &hc, http.MethodGet, path, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil, APIError(fmt.Sprintf("%s did not upgrade to a websocket", path))
+		}
+		return nil, c.statusError(resp, path, body)
+	}
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		resp.Body.Close()
+		return nil, APIError(fmt.Sprintf("invalid websocket handshake from %s", path))
+	}
+	return newWebSocket(conn, nil, true), nil
+}
+
+// AcceptWebSocket upgrades an incoming request to a websocket. It is the
+// server half, for local stand-ins in tests.
+func AcceptWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "expected a websocket upgrade", http.StatusBadRequest)
+		return nil, APIError("expected a websocket upgrade")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "cannot hijack connection", http.StatusInternalServerError)
+		return nil, APIError("cannot hijack connection")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, NetworkError(err.Error())
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, NetworkError(err.Error())
+	}
+	return newWebSocket(conn, rw.Reader, false), nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Read returns message payload bytes. It returns io.EOF once the peer
+// closes the connection.
+func (ws *WebSocket) Read(p []byte) (int, error) {
+	for len(ws.pending) == 0 {
+		op, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case opText, opBinary, opContinuation:
+			ws.pending = payload
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return 0, err
+			}
+		case opClose:
+			ws.Close()
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, ws.pending)
+	ws.pending = ws.pending[n:]
+	return n, nil
+}
+
+// Write sends p as one binary message.
+func (ws *WebSocket) Write(p []byte) (int, error) {
+	if err := ws.writeFrame(opBinary, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends a normal close frame, once, and closes the connection.
+func (ws *WebSocket) Close() error {
+	frame, err := ws.encodeFrame(opClose, []byte{0x03, 0xe8}) // 1000: normal closure
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return nil
+	}
+	ws.closed = true
+	if err == nil {
+		ws.conn.Write(frame)
+	}
+	return ws.conn.Close()
+}
+
+func (ws *WebSocket) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	op := head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	size := uint64(head[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > maxFrame {
+		return 0, nil, APIError(fmt.Sprintf("websocket frame of %d bytes exceeds the %d byte limit", size, maxFrame))
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(ws.r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(ws.r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return op, payload, nil
+}
+
+// writeFrame sends one unfragmented frame. Frames from several goroutines
+// (input and ping replies) never interleave.
+func (ws *WebSocket) writeFrame(op byte, payload []byte) error {
+	frame, err := ws.encodeFrame(op, payload)
+	if err != nil {
+		return err
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.closed {
+		return io.ErrClosedPipe
+	}
+	_, err = ws.conn.Write(frame)
+	return err
+}
+
+func (ws *WebSocket) encodeFrame(op byte, payload []byte) ([]byte, error) {
+	frame := []byte{0x80 | op, 0}
+	switch n := len(payload); {
+	case n < 126:
+		frame[1] = byte(n)
+	case n <= 0xffff:
+		frame[1] = 126
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame[1] = 127
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if !ws.client {
+		return append(frame, payload...), nil
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return nil, NetworkError(fmt.Sprintf("websocket mask: %s", err))
+	}
+	frame[1] |= 0x80
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame, nil
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebsocketEcho(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			t.Error("missing api key header")
+		}
+		ws, err := AcceptWebSocket(w, r)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer ws.Close()
+		io.Copy(ws, ws)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, APIKeyAuth{Header: "X-Api-Key", Key: "secret"}, Options{})
+	ws, err := client.Websocket(context.Background(), "/ws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ws.Close()
+
+	for _, size := range []int{5, 300, 70000} {
+		msg := bytes.Repeat([]byte("x"), size)
+		if _, err := ws.Write(msg); err != nil {
+			t.Fatalf("write %d bytes: %v", size, err)
+		}
+		got := make([]byte, size)
+		if _, err := io.ReadFull(ws, got); err != nil {
+			t.Fatalf("read %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("echo of %d bytes differs", size)
+		}
+	}
+}
+
+func TestWebsocketPingAndClose(t *testing.T) {
+	pong := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := AcceptWebSocket(w, r)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		ws.writeFrame(opText, []byte("hi"))
+		ws.writeFrame(opPing, []byte("p1"))
+		if op, payload, err := ws.readFrame(); err == nil && op == opPong {
+			pong <- string(payload)
+		}
+		ws.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	ws, err := client.Websocket(context.Background(), "/ws")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ws.Close()
+
+	out, err := io.ReadAll(ws)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(out) != "hi" {
+		t.Errorf("read %q, want hi", out)
+	}
+	if got := <-pong; got != "p1" {
+		t.Errorf("pong payload = %q, want p1", got)
+	}
+}
+
+func TestWebsocketStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	_, err := client.Websocket(context.Background(), "/ws")
+	if e, ok := err.(*Error); !ok || e.Code != ErrAuth {
+		t.Errorf("err = %v, want AUTH_ERROR", err)
+	}
+}
+
+func TestWebsocketNotUpgraded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, Options{})
+	_, err := client.Websocket(context.Background(), "/ws")
+	if e, ok := err.(*Error); !ok || e.Code != ErrAPI {
+		t.Errorf("err = %v, want API_ERROR", err)
+	}
+}
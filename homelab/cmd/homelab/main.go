@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	abscli "github.com/schmoli/cli-tools/abs/cli"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/homelab/pkg/homelab"
+	nproxycli "github.com/schmoli/cli-tools/nproxy/cli"
+	portainercli "github.com/schmoli/cli-tools/portainer/cli"
+	pvecli "github.com/schmoli/cli-tools/pve/cli"
+	radarrcli "github.com/schmoli/cli-tools/radarr/cli"
+	sonarrcli "github.com/schmoli/cli-tools/sonarr/cli"
+	transcli "github.com/schmoli/cli-tools/trans/cli"
+)
+
+var version = "dev"
+
+var (
+	flagOutput  string
+	flagContext string
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "homelab",
+	Short: "One entrypoint for every homelab CLI, plus homelab-<name> plugins on PATH",
+	Long: `homelab runs the bundled tools as subcommands (homelab pve list is
+pve-cli list). Any other subcommand runs a homelab-<name> executable
+from PATH with the remaining arguments.`,
+}
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage homelab-<name> plugins",
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins found on PATH",
+	Run: func(cmd *cobra.Command, args []string) {
+		printer, err := common.NewPrinter(flagOutput)
+		if err != nil {
+			handleError(err)
+		}
+		if err := printer.Print(os.Stdout, homelab.PluginList{Plugins: homelab.ListPlugins()}); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Aliases: []string{"ping"},
+	Short:   "Check every configured service",
+	Long: `Run each tool's doctor checks (DNS, TCP, TLS, auth and server version)
+for every service with a URL in the context or environment. The exit code
+is that of the first failing service; see "homelab <tool> doctor --help".`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printer, err := common.NewPrinter(flagOutput)
+		if err != nil {
+			handleError(err)
+		}
+
+		tools := []*common.Tool{
+			abscli.Tool(), nproxycli.Tool(), portainercli.Tool(), pvecli.Tool(),
+			radarrcli.Tool(), sonarrcli.Tool(), transcli.Tool(),
+		}
+		for _, t := range tools {
+			*t.Context = flagContext
+		}
+
+		summary, failure := common.Summarize(tools)
+		if err := printer.Print(os.Stdout, summary); err != nil {
+			handleError(err)
+		}
+		if e, ok := failure.(*common.Error); ok {
+			os.Exit(e.ExitCode())
+		} else if failure != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// tool mounts a standalone CLI's command tree under its short name
+func tool(name string, cmd *cobra.Command) *cobra.Command {
+	cmd.Use = name
+	return cmd
+}
+
+func init() {
+	rootCmd.Flags().BoolP("version", "V", false, "version for homelab")
+	pluginsListCmd.Flags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	pluginsCmd.AddCommand(pluginsListCmd)
+	rootCmd.AddCommand(pluginsCmd)
+
+	doctorCmd.Flags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	doctorCmd.Flags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runPlugin dispatches to homelab-<name> when the first argument is not a
+// built-in command. It only returns if no plugin matched.
+func runPlugin(args []string) {
+	if len(args) == 0 || args[0] == "" || args[0][0] == '-' {
+		return
+	}
+	if cmd, _, err := rootCmd.Find(args); err == nil && cmd != rootCmd {
+		return
+	}
+	path, ok := homelab.FindPlugin(args[0])
+	if !ok {
+		return
+	}
+
+	code, err := homelab.RunPlugin(path, args[1:])
+	if err != nil {
+		handleError(err)
+	}
+	os.Exit(code)
+}
+
+func handleError(err error) {
+	homelab.PrintError(err)
+	if e, ok := err.(*common.Error); ok {
+		os.Exit(e.ExitCode())
+	}
+	os.Exit(1)
+}
+
+func main() {
+	rootCmd.Version = version
+	rootCmd.AddCommand(
+		tool("abs", abscli.Command(version)),
+		tool("nproxy", nproxycli.Command(version)),
+		tool("portainer", portainercli.Command(version)),
+		tool("pve", pvecli.Command(version)),
+		tool("radarr", radarrcli.Command(version)),
+		tool("sonarr", sonarrcli.Command(version)),
+		tool("trans", transcli.Command(version)),
+	)
+
+	runPlugin(os.Args[1:])
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
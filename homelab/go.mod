@@ -0,0 +1,34 @@
+module github.com/schmoli/cli-tools/homelab
+
+go 1.21
+
+require (
+	github.com/schmoli/cli-tools/abs v0.0.0
+	github.com/schmoli/cli-tools/common v0.0.0
+	github.com/schmoli/cli-tools/nproxy v0.0.0
+	github.com/schmoli/cli-tools/portainer v0.0.0
+	github.com/schmoli/cli-tools/pve v0.0.0
+	github.com/schmoli/cli-tools/radarr v0.0.0
+	github.com/schmoli/cli-tools/sonarr v0.0.0
+	github.com/schmoli/cli-tools/trans v0.0.0
+	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/term v0.15.0 // indirect
+)
+
+replace (
+	github.com/schmoli/cli-tools/abs => ../abs
+	github.com/schmoli/cli-tools/common => ../common
+	github.com/schmoli/cli-tools/nproxy => ../nproxy
+	github.com/schmoli/cli-tools/portainer => ../portainer
+	github.com/schmoli/cli-tools/pve => ../pve
+	github.com/schmoli/cli-tools/radarr => ../radarr
+	github.com/schmoli/cli-tools/sonarr => ../sonarr
+	github.com/schmoli/cli-tools/trans => ../trans
+)
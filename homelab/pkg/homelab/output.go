@@ -0,0 +1,11 @@
+package homelab
+
+import (
+	"os"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+func PrintError(err error) {
+	common.PrintError(os.Stderr, err)
+}
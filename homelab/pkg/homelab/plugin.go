@@ -0,0 +1,93 @@
+package homelab
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+// PluginPrefix is prepended to a subcommand name to find an external
+// plugin on PATH, git/kubectl style: "homelab backup" runs homelab-backup.
+const PluginPrefix = "homelab-"
+
+type PluginList struct {
+	Plugins []Plugin `yaml:"plugins"`
+}
+
+type Plugin struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+func (Plugin) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "NAME", Field: "name"},
+		{Header: "PATH", Field: "path"},
+	}
+}
+
+// FindPlugin returns the executable for a plugin name, if any.
+func FindPlugin(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// ListPlugins scans PATH for homelab-* executables. Like LookPath, the
+// first directory wins when a name appears more than once.
+func ListPlugins() []Plugin {
+	seen := map[string]bool{}
+	plugins := []Plugin{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, PluginPrefix) || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: strings.TrimPrefix(name, PluginPrefix), Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// RunPlugin runs a plugin with the terminal attached and returns its exit
+// code. The config file path is passed through the environment so plugins
+// share settings with the built-in tools; CLI_TOOLS_CONTEXT, if set, is
+// inherited as is.
+func RunPlugin(path string, args []string) (int, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), common.ConfigPathEnv+"="+common.ConfigPath())
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, common.ConfigError(err.Error())
+	}
+	return 0, nil
+}
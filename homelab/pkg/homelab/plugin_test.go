@@ -0,0 +1,61 @@
+package homelab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListPlugins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writePlugin(t, first, "homelab-backup", "exit 0\n")
+	writePlugin(t, second, "homelab-backup", "exit 1\n")
+	writePlugin(t, second, "homelab-dns", "exit 0\n")
+	os.WriteFile(filepath.Join(second, "homelab-notes"), []byte("not executable"), 0644)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := ListPlugins()
+	if len(plugins) != 2 {
+		t.Fatalf("got %d plugins, want 2: %v", len(plugins), plugins)
+	}
+	if plugins[0].Name != "backup" || plugins[0].Path != filepath.Join(first, "homelab-backup") {
+		t.Errorf("first plugin = %+v, want backup from the first PATH entry", plugins[0])
+	}
+	if plugins[1].Name != "dns" {
+		t.Errorf("second plugin = %+v", plugins[1])
+	}
+}
+
+func TestFindAndRunPlugin(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "homelab-fail", `[ "$1" = "--flag" ] && [ -n "$CLI_TOOLS_CONFIG" ] && exit 7
+exit 1
+`)
+	t.Setenv("PATH", dir)
+
+	if _, ok := FindPlugin("missing"); ok {
+		t.Error("expected missing plugin not to be found")
+	}
+	if _, ok := FindPlugin("../fail"); ok {
+		t.Error("plugin names must not contain path separators")
+	}
+
+	path, ok := FindPlugin("fail")
+	if !ok {
+		t.Fatal("expected plugin to be found")
+	}
+	code, err := RunPlugin(path, []string{"--flag"})
+	if err != nil {
+		t.Fatalf("RunPlugin: %v", err)
+	}
+	if code != 7 {
+		t.Errorf("exit code = %d, want 7", code)
+	}
+}
@@ -60,6 +60,11 @@ mv "${TMPDIR}/pve-cli" "${INSTALL_DIR}/"
 mv "${TMPDIR}/abs-cli" "${INSTALL_DIR}/"
 mv "${TMPDIR}/sonarr-cli" "${INSTALL_DIR}/"
 mv "${TMPDIR}/radarr-cli" "${INSTALL_DIR}/"
+# Umbrella binary, included in releases since it was introduced
+if [ -f "${TMPDIR}/homelab" ]; then
+    mv "${TMPDIR}/homelab" "${INSTALL_DIR}/"
+    chmod +x "${INSTALL_DIR}/homelab"
+fi
 chmod +x "${INSTALL_DIR}/portainer-cli" "${INSTALL_DIR}/nproxy-cli" "${INSTALL_DIR}/trans-cli" "${INSTALL_DIR}/pve-cli" "${INSTALL_DIR}/abs-cli" "${INSTALL_DIR}/sonarr-cli" "${INSTALL_DIR}/radarr-cli"
 
 # Cleanup
@@ -74,6 +79,9 @@ echo "  - pve-cli"
 echo "  - abs-cli"
 echo "  - sonarr-cli"
 echo "  - radarr-cli"
+if [ -x "${INSTALL_DIR}/homelab" ]; then
+    echo "  - homelab (all of the above as subcommands)"
+fi
 
 # Check PATH
 if [[ ":${PATH}:" != *":${INSTALL_DIR}:"* ]]; then
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	hostsShowCmd.ValidArgsFunction = tool.Complete("hosts", completeHosts)
+	certificatesShowCmd.ValidArgsFunction = tool.Complete("certificates", completeCertificates)
+}
+
+func completeHosts() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := client.ListProxyHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(hosts))
+	for i, h := range hosts {
+		out[i] = fmt.Sprintf("%d\t%s", h.ID, strings.Join(h.DomainNames, ", "))
+	}
+	return out, nil
+}
+
+func completeCertificates() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	certs, err := client.ListCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(certs))
+	for i, c := range certs {
+		out[i] = fmt.Sprintf("%d\t%s", c.ID, c.NiceName)
+	}
+	return out, nil
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/schmoli/cli-tools/common"
+)
+
+// doctor checks nproxy-cli's service for the doctor command
+var doctor = &common.Doctor{
+	URLEnv:     "NPROXY_URL",
+	Service:    "Nginx Proxy Manager",
+	MinVersion: "2.0",
+	Features:   "token auth",
+	Connect: func() (string, bool, error) {
+		url, _, insecure, err := getConfig()
+		return url, insecure, err
+	},
+	Version: func() (string, error) {
+		client, err := getClient()
+		if err != nil {
+			return "", err
+		}
+		return client.ServerVersion()
+	},
+}
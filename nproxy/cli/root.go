@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/nproxy/pkg/nproxy"
+	"golang.org/x/term"
+)
+
+var (
+	flagURL         string
+	flagToken       string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+	flagPrint       bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "nproxy-cli",
+	Short: "CLI for nginx-proxy-manager API",
+}
+
+// tool describes nproxy-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "nproxy",
+	Keys:    []string{"url", "token", "insecure"},
+	Secrets: []string{"token"},
+	Context: &flagContext,
+	Print:   nproxy.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Authenticate and store the token in the credential store",
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := common.LoadServiceConfig("nproxy", flagContext)
+		if err != nil {
+			handleError(err)
+		}
+
+		url := flagURL
+		if url == "" {
+			url = os.Getenv("NPROXY_URL")
+		}
+		if url == "" {
+			url = file.Get("url")
+		}
+		if url == "" {
+			handleError(nproxy.ConfigError("missing URL. Use --url, set NPROXY_URL or add url to a config context"))
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print("Email: ")
+		email, _ := reader.ReadString('\n')
+		email = strings.TrimSpace(email)
+
+		fmt.Print("Password: ")
+		passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Println()
+		if err != nil {
+			handleError(nproxy.ConfigError("failed to read password"))
+		}
+		password := string(passwordBytes)
+
+		token, err := nproxy.Login(url, email, password, flagInsecure || file.Bool("insecure"))
+		if err != nil {
+			handleError(err)
+		}
+
+		if flagPrint {
+			fmt.Println(token)
+			return
+		}
+
+		store, err := file.StoreSecret("token", token)
+		if err != nil {
+			handleError(err)
+		}
+		fmt.Printf("token stored in %s for context %q\n", store, common.CredentialContext(file.Context))
+	},
+}
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Manage proxy hosts",
+}
+
+var hostsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all proxy hosts",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+		}
+
+		hosts, err := client.ListProxyHosts()
+		if err != nil {
+			handleError(err)
+		}
+
+		output := nproxy.ProxyHostList{
+			Hosts: make([]nproxy.ProxyHostListItem, len(hosts)),
+		}
+		for i, h := range hosts {
+			output.Hosts[i] = h.ToListItem()
+		}
+
+		if err := nproxy.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var hostsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show proxy host details",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+		}
+
+		host, err := client.GetProxyHost(id)
+		if err != nil {
+			handleError(err)
+		}
+
+		if err := nproxy.Print(host.ToProxyHost()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var certificatesCmd = &cobra.Command{
+	Use:     "certificates",
+	Aliases: []string{"certs"},
+	Short:   "Manage certificates",
+}
+
+var certificatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all certificates",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+		}
+
+		certs, err := client.ListCertificates()
+		if err != nil {
+			handleError(err)
+		}
+
+		output := nproxy.CertificateList{
+			Certificates: make([]nproxy.CertificateListItem, len(certs)),
+		}
+		for i, c := range certs {
+			output.Certificates[i] = c.ToListItem()
+		}
+
+		if err := nproxy.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var certificatesShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show certificate details",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+		}
+
+		cert, err := client.GetCertificate(id)
+		if err != nil {
+			handleError(err)
+		}
+
+		if err := nproxy.Print(cert.ToCertificate()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "nginx-proxy-manager URL (or set NPROXY_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagToken, "token", "", "API token (or set NPROXY_TOKEN)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for nproxy-cli")
+	rootCmd.PersistentPreRun = setup
+
+	hostsCmd.AddCommand(hostsListCmd)
+	hostsCmd.AddCommand(hostsShowCmd)
+	certificatesCmd.AddCommand(certificatesListCmd)
+	certificatesCmd.AddCommand(certificatesShowCmd)
+
+	loginCmd.Flags().BoolVar(&flagPrint, "print", false, "Print the token instead of storing it")
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(hostsCmd)
+	rootCmd.AddCommand(certificatesCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func parseID(arg string) (int64, error) {
+	var id int64
+	if _, err := fmt.Sscanf(arg, "%d", &id); err != nil {
+		return 0, nproxy.ConfigError(fmt.Sprintf("invalid ID: %s", arg))
+	}
+	if id <= 0 {
+		return 0, nproxy.ConfigError("ID must be positive")
+	}
+	return id, nil
+}
+
+func getConfig() (string, string, bool, error) {
+	file, err := common.LoadServiceConfig("nproxy", flagContext)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	url := flagURL
+	if url == "" {
+		url = os.Getenv("NPROXY_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", false, nproxy.ConfigError("missing URL. Use --url, set NPROXY_URL or add url to a config context")
+	}
+
+	token := flagToken
+	if token == "" {
+		token = os.Getenv("NPROXY_TOKEN")
+	}
+	if token == "" {
+		if token, err = file.Secret("token"); err != nil {
+			return "", "", false, err
+		}
+	}
+	if token == "" {
+		return "", "", false, nproxy.ConfigError("missing token. Use --token, set NPROXY_TOKEN or run 'nproxy-cli config set-secret token'")
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", false, nproxy.ConfigError("URL must start with http:// or https://")
+	}
+
+	return url, token, flagInsecure || file.Bool("insecure"), nil
+}
+
+func getClient() (*nproxy.Client, error) {
+	url, token, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return nproxy.NewClient(url, token, insecure), nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := nproxy.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	nproxy.PrintError(err)
+	if ne, ok := err.(*nproxy.NproxyError); ok {
+		os.Exit(ne.ExitCode())
+	}
+	os.Exit(1)
+}
+
+// Command returns the nproxy-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of nproxy-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
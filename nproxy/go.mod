@@ -3,6 +3,7 @@ module github.com/schmoli/cli-tools/nproxy
 go 1.21
 
 require (
+	github.com/schmoli/cli-tools/common v0.0.0
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/term v0.15.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -13,3 +14,5 @@ require (
 	github.com/spf13/pflag v1.0.9 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 )
+
+replace github.com/schmoli/cli-tools/common => ../common
@@ -1,110 +1,45 @@
 package nproxy
 
 import (
-	"bytes"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"strings"
 	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
+const timeout = 10 * time.Second
+
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	api *common.Client
 }
 
 func NewClient(url, token string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
 	return &Client{
-		baseURL:    strings.TrimSuffix(url, "/"),
-		token:      token,
-		httpClient: client,
+		api: common.NewClient(url, common.BearerAuth{Token: token}, common.Options{Timeout: timeout, Insecure: insecure, ParseError: parseError}),
 	}
 }
 
 func (c *Client) get(path string, result interface{}) error {
-	url := c.baseURL + path
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.token)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response from %s: %s", path, err))
-		}
-		return nil
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return AuthError(fmt.Sprintf("invalid or expired token for %s", path))
-	case http.StatusNotFound:
-		return NotFoundError(fmt.Sprintf("resource not found: %s", path))
-	default:
-		return APIError(fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, path))
-	}
+	return c.api.Get(path, result)
 }
 
 // Login authenticates and returns a token
 func Login(url, email, password string, insecure bool) (string, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
+	client := common.NewClient(url, nil, common.Options{Timeout: timeout, Insecure: insecure, ParseError: parseError})
 
 	payload := map[string]string{
 		"identity": email,
 		"secret":   password,
 	}
-	body, _ := json.Marshal(payload)
-
-	reqURL := strings.TrimSuffix(url, "/") + "/api/tokens"
-	req, err := http.NewRequest("POST", reqURL, bytes.NewReader(body))
-	if err != nil {
-		return "", NetworkError(err.Error())
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return "", AuthError("invalid credentials")
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", APIError(fmt.Sprintf("login failed with status %d", resp.StatusCode))
-	}
 
 	var result struct {
 		Token string `json:"token"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", APIError("failed to parse login response")
+	if err := client.Post("/api/tokens", payload, &result); err != nil {
+		if e, ok := err.(*NproxyError); ok && e.Code == ErrAuth {
+			return "", AuthError("invalid credentials")
+		}
+		return "", err
 	}
 
 	return result.Token, nil
@@ -143,3 +78,23 @@ func (c *Client) GetCertificate(id int64) (*APICertificate, error) {
 	}
 	return &cert, nil
 }
+
+// ServerVersion checks the token against /api/users/me, then reads the
+// version from /api/, which is public.
+func (c *Client) ServerVersion() (string, error) {
+	if err := c.get("/api/users/me", nil); err != nil {
+		return "", err
+	}
+	var status struct {
+		Version struct {
+			Major    int `json:"major"`
+			Minor    int `json:"minor"`
+			Revision int `json:"revision"`
+		} `json:"version"`
+	}
+	if err := c.get("/api/", &status); err != nil {
+		return "", err
+	}
+	v := status.Version
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Revision), nil
+}
@@ -1,17 +1,21 @@
 package nproxy
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
 
 func TestNewClientTrimsTrailingSlash(t *testing.T) {
 	client := NewClient("https://example.com/", "token", false)
-	if client.baseURL != "https://example.com" {
-		t.Errorf("baseURL = %s, want https://example.com", client.baseURL)
+	if client.api.BaseURL != "https://example.com" {
+		t.Errorf("baseURL = %s, want https://example.com", client.api.BaseURL)
 	}
 }
 
 func TestNewClientWithInsecure(t *testing.T) {
 	client := NewClient("https://example.com", "token", true)
-	if client.httpClient.Transport == nil {
+	if client.api.HTTPClient.Transport == nil {
 		t.Error("Transport should not be nil when insecure=true")
 	}
 }
@@ -21,14 +25,15 @@ func TestNewClientCreation(t *testing.T) {
 	if client == nil {
 		t.Error("Client should not be nil")
 	}
-	if client.httpClient == nil {
+	if client.api.HTTPClient == nil {
 		t.Error("httpClient should not be nil")
 	}
 }
 
 func TestClientStoresToken(t *testing.T) {
 	client := NewClient("https://example.com", "mytoken", false)
-	if client.token != "mytoken" {
-		t.Errorf("token = %s, want mytoken", client.token)
+	auth, ok := client.api.Auth.(common.BearerAuth)
+	if !ok || auth.Token != "mytoken" {
+		t.Errorf("auth = %#v, want bearer mytoken", client.api.Auth)
 	}
 }
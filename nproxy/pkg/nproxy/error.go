@@ -1,59 +1,52 @@
 package nproxy
 
-import "fmt"
+import (
+	"encoding/json"
 
-type ErrorCode string
-
-const (
-	ErrConfig   ErrorCode = "CONFIG_ERROR"
-	ErrAuth     ErrorCode = "AUTH_FAILED"
-	ErrNotFound ErrorCode = "NOT_FOUND"
-	ErrNetwork  ErrorCode = "NETWORK_ERROR"
-	ErrAPI      ErrorCode = "API_ERROR"
+	"github.com/schmoli/cli-tools/common"
 )
 
-type NproxyError struct {
-	Code    ErrorCode
-	Message string
-}
+type ErrorCode = common.ErrorCode
 
-func (e *NproxyError) Error() string {
-	return e.Message
-}
+const (
+	ErrConfig   = common.ErrConfig
+	ErrAuth     = common.ErrAuth
+	ErrNotFound = common.ErrNotFound
+	ErrNetwork  = common.ErrNetwork
+	ErrAPI      = common.ErrAPI
+)
 
-func (e *NproxyError) ExitCode() int {
-	switch e.Code {
-	case ErrConfig:
-		return 1
-	case ErrAuth:
-		return 2
-	case ErrNotFound:
-		return 3
-	case ErrNetwork:
-		return 4
-	case ErrAPI:
-		return 5
-	default:
-		return 1
-	}
-}
+type NproxyError = common.Error
 
 func ConfigError(msg string) *NproxyError {
-	return &NproxyError{Code: ErrConfig, Message: msg}
+	return common.ConfigError(msg)
 }
 
 func AuthError(msg string) *NproxyError {
-	return &NproxyError{Code: ErrAuth, Message: msg}
+	return common.AuthError(msg)
 }
 
 func NotFoundError(msg string) *NproxyError {
-	return &NproxyError{Code: ErrNotFound, Message: msg}
+	return common.NotFoundError(msg)
 }
 
 func NetworkError(msg string) *NproxyError {
-	return &NproxyError{Code: ErrNetwork, Message: msg}
+	return common.NetworkError(msg)
 }
 
 func APIError(msg string) *NproxyError {
-	return &NproxyError{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+	return common.APIError(msg)
+}
+
+// parseError reads nginx-proxy-manager's {"error": {"message": ...}} envelope.
+func parseError(body []byte) (string, []string) {
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &e) != nil || e.Error.Message == "" {
+		return common.ParseErrorBody(body)
+	}
+	return e.Error.Message, nil
 }
@@ -46,3 +46,10 @@ func TestErrorMessage(t *testing.T) {
 		t.Errorf("Error() = %s, want 'missing URL'", err.Error())
 	}
 }
+
+func TestParseError(t *testing.T) {
+	msg, _ := parseError([]byte(`{"error":{"code":400,"message":"Domain example.com is already in use"}}`))
+	if msg != "Domain example.com is already in use" {
+		t.Errorf("message = %q", msg)
+	}
+}
@@ -5,18 +5,10 @@ import (
 	"io"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	return writeYAML(os.Stdout, data)
 }
@@ -30,21 +22,71 @@ func writeYAML(w io.Writer, data interface{}) error {
 	return enc.Close()
 }
 
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
 func PrintError(err error) {
-	ne, ok := err.(*NproxyError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (ProxyHostListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "DOMAINS", Field: "domainNames"},
+		{Header: "FORWARD HOST", Field: "forwardHost"},
+		{Header: "PORT", Field: "forwardPort"},
+		{Header: "SSL FORCED", Field: "sslForced"},
+		{Header: "ENABLED", Field: "enabled"},
 	}
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(ne.Code),
-			Message: ne.Message,
-		},
+func (ProxyHost) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "DOMAINS", Field: "domainNames"},
+		{Header: "SCHEME", Field: "forwardScheme"},
+		{Header: "FORWARD HOST", Field: "forwardHost"},
+		{Header: "PORT", Field: "forwardPort"},
+		{Header: "SSL FORCED", Field: "sslForced"},
+		{Header: "ENABLED", Field: "enabled"},
+		{Header: "CERTIFICATE", Field: "certificateId", Wide: true},
+		{Header: "BLOCK EXPLOITS", Field: "blockExploits", Wide: true},
+		{Header: "CACHING", Field: "cachingEnabled", Wide: true},
+		{Header: "WEBSOCKET", Field: "websocket", Wide: true},
 	}
+}
+
+func (CertificateListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "niceName"},
+		{Header: "PROVIDER", Field: "provider"},
+		{Header: "EXPIRES", Field: "expiresOn"},
+	}
+}
 
-	if err := writeYAML(os.Stderr, output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", ne.Message)
+func (Certificate) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "niceName"},
+		{Header: "PROVIDER", Field: "provider"},
+		{Header: "DOMAINS", Field: "domainNames"},
+		{Header: "EXPIRES", Field: "expiresOn"},
 	}
 }
@@ -1,11 +1,15 @@
 package nproxy
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
 
 func TestErrorOutputFormat(t *testing.T) {
 	err := ConfigError("test message")
-	output := ErrorOutput{
-		Error: ErrorDetail{
+	output := common.ErrorOutput{
+		Error: common.ErrorDetail{
 			Code:    string(err.Code),
 			Message: err.Message,
 		},
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var accessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Show who can access endpoints and stacks (administrators only)",
+}
+
+var accessEndpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "List the users and teams granted each endpoint",
+	Long: `List the users and teams granted each endpoint
+
+Access granted through an endpoint's group is included. Administrators can
+use every endpoint and are not listed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, endpoints, groups, err := accessData(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(dir.EndpointAccess(endpoints, groups)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var accessStacksCmd = &cobra.Command{
+	Use:   "stacks",
+	Short: "List each stack's access level and the users and teams it is shared with",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, err := client.Directory()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(dir.StackAccess(stacks)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var accessAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Review every user's access, flagging administrators and users in no team",
+	Long: `Review every user's access, flagging administrators and users in no team
+
+Each user is listed with their role, teams and the endpoints they can use
+directly or through a team. Flagged users come first.`,
+	Example: `  portainer-cli access audit -o table`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, endpoints, groups, err := accessData(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(dir.Audit(endpoints, groups)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// accessData fetches the directory with endpoints and endpoint groups,
+// whose policies grant access.
+func accessData(client *portainer.Client) (*portainer.Directory, []portainer.APIEndpoint, []portainer.APIEndpointGroup, error) {
+	dir, err := client.Directory()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	endpoints, err := client.ListEndpoints()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	groups, err := client.ListEndpointGroups()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return dir, endpoints, groups, nil
+}
+
+func init() {
+	accessCmd.AddCommand(accessEndpointsCmd)
+	accessCmd.AddCommand(accessStacksCmd)
+	accessCmd.AddCommand(accessAuditCmd)
+}
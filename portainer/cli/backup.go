@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagBackupFile string
+	flagPassword   bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore Portainer (administrators only)",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Download a backup archive of Portainer's data",
+	Long: `Download a backup archive of Portainer's data
+
+The archive holds Portainer's database and stack files, including
+credentials, and is written readable by its owner only. With --password it
+is encrypted; the password is prompted for on a terminal, or read from the
+first line of stdin so it can be piped in.
+
+Here -o names the archive; the result is printed as YAML.`,
+	Example: `  portainer-cli backup create -o portainer-$(date +%F).tar.gz
+  pass show portainer/backup | portainer-cli backup create -o backup.tar.gz --password`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		password, err := backupPassword(cmd.InOrStdin())
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		body, err := client.Backup(ctx, password)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		defer body.Close()
+
+		n, err := portainer.SaveBackup(body, flagBackupFile)
+		if err := streamError(ctx, err); err != nil {
+			handleError(err)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := portainer.Print(portainer.NewBackupResult(flagBackupFile, n, password != "", "created")); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore a backup archive into a fresh Portainer",
+	Long: `Restore a backup archive into a fresh Portainer
+
+Portainer only accepts a restore on a new instance whose administrator has
+not been created yet, within a few minutes of its first start, so no token
+is needed. It restarts with the restored data; log in with the accounts
+from the backup. Use --password for an encrypted archive. When stdin is
+piped, the password is its first line and the confirmation its second.`,
+	Example: `  portainer-cli backup restore backup.tar.gz --url https://portainer-drill:9443 --password`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		url, token, insecure, err := resolveConfig()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		client := portainer.NewClient(url, token, insecure)
+
+		archive, err := os.Open(args[0])
+		if err != nil {
+			handleError(portainer.ConfigError(fmt.Sprintf("cannot read backup: %s", err)))
+			return
+		}
+		defer archive.Close()
+		info, err := archive.Stat()
+		if err != nil {
+			handleError(portainer.ConfigError(fmt.Sprintf("cannot read backup: %s", err)))
+			return
+		}
+
+		in := sharedStdin(cmd)
+		password, err := backupPassword(in)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Restore %s into %s?", args[0], url)
+			if err := common.Confirm(in, os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		if err := client.Restore(context.Background(), archive, filepath.Base(args[0]), password); err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(portainer.NewBackupResult(args[0], info.Size(), password != "", "restored")); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var backupExportStacksCmd = &cobra.Command{
+	Use:   "export-stacks",
+	Short: "Write every stack's compose file and env to a directory",
+	Long: `Write every stack's compose file and env to a directory
+
+Each stack goes to <endpoint>/<stack>/docker-compose.yml, with its env in
+.env next to it, and manifest.yaml lists them all. Re-running updates the
+files in place and removes those of deleted stacks, so the directory can
+be committed to git and diffed between runs. An endpoint's directory also
+works with "stacks diff --all --dir".
+
+Env values that look secret are masked, as in "stacks show", and listed
+under redacted in the manifest; --show-secrets writes them as they are.
+"stacks diff" takes a masked value as matching whatever is deployed, so
+masking alone is not drift, though a changed secret is not caught.`,
+	Example: `  portainer-cli backup export-stacks --dir ./out
+  portainer-cli stacks diff --all --dir ./out/local`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		r, err := redactor()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		endpoints, err := client.ListEndpoints()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		names := map[int64]string{}
+		for _, e := range endpoints {
+			names[e.ID] = e.Name
+		}
+
+		previous, err := portainer.ReadManifest(flagDir)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		// The version only annotates the manifest
+		version, _ := client.ServerVersion()
+
+		manifest := &portainer.ExportManifest{Portainer: version, Stacks: []portainer.StackExport{}}
+		for i := range stacks {
+			stack := &stacks[i]
+			file, err := client.GetStackFile(stack.ID)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			export, err := portainer.ExportStack(flagDir, stack, names[stack.EndpointID], file.StackFileContent, r)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			manifest.Stacks = append(manifest.Stacks, export)
+		}
+
+		removed, err := portainer.PruneExport(flagDir, previous, manifest)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		if err := portainer.WriteManifest(flagDir, manifest); err != nil {
+			handleError(err)
+			return
+		}
+
+		result := portainer.ExportResult{Dir: flagDir, Stacks: manifest.Stacks, Removed: removed}
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// backupPassword reads the archive password when --password is given.
+func backupPassword(in io.Reader) (string, error) {
+	if !flagPassword {
+		return "", nil
+	}
+	return common.ReadSecret(in, "password")
+}
+
+// sharedStdin buffers piped stdin once, so the password and confirmation
+// lines read from it in turn don't swallow each other. A terminal is left
+// as it is, so the password is read without echo.
+func sharedStdin(cmd *cobra.Command) io.Reader {
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return in
+	}
+	return bufio.NewReader(in)
+}
+
+func init() {
+	backupCreateCmd.Flags().StringVarP(&flagBackupFile, "output", "o", "", "Archive file to write, e.g. backup.tar.gz")
+	backupCreateCmd.MarkFlagRequired("output")
+	for _, cmd := range []*cobra.Command{backupCreateCmd, backupRestoreCmd} {
+		cmd.Flags().BoolVar(&flagPassword, "password", false, "Encrypt or decrypt the archive with a password, read from stdin or a prompt")
+	}
+	backupRestoreCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	backupExportStacksCmd.Flags().StringVar(&flagDir, "dir", "", "Directory to write the stacks to")
+	backupExportStacksCmd.MarkFlagRequired("dir")
+	backupExportStacksCmd.Flags().BoolVar(&flagShowSecrets, "show-secrets", false, "Write env values that look secret instead of masking them")
+
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupExportStacksCmd)
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+func init() {
+	stackCmds := []*cobra.Command{
+		stacksShowCmd, stacksContainersCmd, stacksUpdateCmd, stacksStartCmd,
+		stacksStopCmd, stacksRestartCmd, stacksDeleteCmd, stacksRedeployCmd, stacksDiffCmd, stacksLogsCmd,
+	}
+	for _, cmd := range stackCmds {
+		cmd.ValidArgsFunction = tool.Complete("stacks", completeStacks)
+	}
+	endpointsShowCmd.ValidArgsFunction = tool.Complete("endpoints", completeEndpoints)
+	containerCmds := []*cobra.Command{
+		containersStartCmd, containersStopCmd, containersRestartCmd,
+		containersKillCmd, containersRmCmd, containersInspectCmd, containersLogsCmd, containersExecCmd,
+	}
+	for _, cmd := range containerCmds {
+		cmd.ValidArgsFunction = tool.Complete("containers", completeContainers)
+	}
+	for _, cmd := range []*cobra.Command{usersDeleteCmd, usersSetRoleCmd} {
+		cmd.ValidArgsFunction = tool.Complete("users", completeUsers)
+	}
+	for _, cmd := range []*cobra.Command{teamsAddMemberCmd, teamsRemoveMemberCmd} {
+		cmd.ValidArgsFunction = tool.Complete("teams", completeTeams)
+	}
+	for _, cmd := range []*cobra.Command{registriesRmCmd, registriesTestCmd} {
+		cmd.ValidArgsFunction = tool.Complete("registries", completeRegistries)
+	}
+	for _, cmd := range []*cobra.Command{edgeStacksShowCmd, edgeStacksStatusCmd} {
+		cmd.ValidArgsFunction = tool.Complete("edge-stacks", completeEdgeStacks)
+	}
+	edgeStacksDeployCmd.RegisterFlagCompletionFunc("group", tool.CompleteFlag("edge-groups", completeEdgeGroups))
+	dockerCmds := []*cobra.Command{
+		imagesListCmd, imagesPullCmd, imagesRmCmd, imagesPruneCmd,
+		volumesListCmd, volumesRmCmd, volumesPruneCmd, networksListCmd, networksInspectCmd,
+	}
+	for _, cmd := range append(append(containerCmds, dockerCmds...), stacksDeployCmd, templatesDeployCmd, eventsCmd, containersListCmd, containersStatsCmd, stacksOutdatedCmd) {
+		cmd.RegisterFlagCompletionFunc("endpoint", tool.CompleteFlag("endpoints", completeEndpoints))
+	}
+}
+
+func completeStacks() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	stacks, err := client.ListStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(stacks))
+	for i, s := range stacks {
+		out[i] = fmt.Sprintf("%d\t%s", s.ID, s.Name)
+	}
+	return out, nil
+}
+
+func completeEndpoints() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := client.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(endpoints))
+	for i, e := range endpoints {
+		out[i] = fmt.Sprintf("%d\t%s", e.ID, e.Name)
+	}
+	return out, nil
+}
+
+// completeContainers offers container names from every endpoint, which is
+// how the container commands resolve them.
+func completeContainers() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := client.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, e := range endpoints {
+		containers, err := client.ListContainers(e.ID)
+		if err != nil {
+			continue
+		}
+		for _, c := range containers {
+			out = append(out, fmt.Sprintf("%s\t%s on %s (%s)", c.Name(), c.State, e.Name, c.Image))
+		}
+	}
+	return out, nil
+}
+
+func completeUsers() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	users, err := client.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(users))
+	for i, u := range users {
+		out[i] = fmt.Sprintf("%s\t%s", u.Username, portainer.RoleLabel(u.Role))
+	}
+	return out, nil
+}
+
+func completeTeams() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	teams, err := client.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(teams))
+	for i, t := range teams {
+		out[i] = t.Name
+	}
+	return out, nil
+}
+
+func completeRegistries() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	registries, err := client.ListRegistries()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(registries))
+	for i := range registries {
+		out[i] = fmt.Sprintf("%s\t%s", registries[i].Name, registries[i].Host())
+	}
+	return out, nil
+}
+
+func completeEdgeStacks() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	stacks, err := client.ListEdgeStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(stacks))
+	for i, s := range stacks {
+		out[i] = s.Name
+	}
+	return out, nil
+}
+
+func completeEdgeGroups() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := client.ListEdgeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(groups))
+	for i, g := range groups {
+		out[i] = g.Name
+	}
+	return out, nil
+}
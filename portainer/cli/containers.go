@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagEndpoint int64
+	flagTimeout  int
+	flagSignal   string
+	flagForce    bool
+	flagVolumes  bool
+)
+
+var containersCmd = &cobra.Command{
+	Use:   "containers",
+	Short: "Manage containers",
+}
+
+var containersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all containers",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ids, err := endpointIDs(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var output portainer.ContainerList
+
+		for _, eid := range ids {
+			containers, err := client.ListContainers(eid)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			for _, c := range containers {
+				output = append(output, c.ToListItem(eid))
+			}
+		}
+
+		// Sort by endpoint, then name
+		sort.Slice(output, func(i, j int) bool {
+			if output[i].Endpoint != output[j].Endpoint {
+				return output[i].Endpoint < output[j].Endpoint
+			}
+			return output[i].Name < output[j].Name
+		})
+
+		if err := portainer.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var (
+	containersStartCmd = containerAction("start", "Start a container", "started", false,
+		func(client *portainer.Client, endpointID int64, id string) error {
+			return client.StartContainer(endpointID, id)
+		})
+	containersStopCmd = containerAction("stop", "Stop a container", "stopped", true,
+		func(client *portainer.Client, endpointID int64, id string) error {
+			return client.StopContainer(endpointID, id, flagTimeout)
+		})
+	containersRestartCmd = containerAction("restart", "Restart a container", "restarted", true,
+		func(client *portainer.Client, endpointID int64, id string) error {
+			return client.RestartContainer(endpointID, id, flagTimeout)
+		})
+	containersKillCmd = containerAction("kill", "Send a signal to a container (default KILL)", "killed", true,
+		func(client *portainer.Client, endpointID int64, id string) error {
+			return client.KillContainer(endpointID, id, flagSignal)
+		})
+	containersRmCmd = containerAction("rm", "Remove a container", "removed", true,
+		func(client *portainer.Client, endpointID int64, id string) error {
+			return client.RemoveContainer(endpointID, id, flagForce, flagVolumes)
+		})
+)
+
+// containerAction builds a container command: it resolves the name or ID,
+// asks for confirmation when destructive unless --yes is given, runs the
+// action and prints the result.
+func containerAction(name, short, done string, destructive bool, run func(*portainer.Client, int64, string) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name + " <name-or-id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+				return
+			}
+
+			endpointID, container, err := resolveContainer(client, args[0])
+			if err != nil {
+				handleError(err)
+				return
+			}
+
+			if destructive && !flagYes {
+				question := fmt.Sprintf("%s container %q on endpoint %d?", strings.ToUpper(name[:1])+name[1:], container.Name(), endpointID)
+				if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+					handleError(err)
+					return
+				}
+			}
+
+			if err := run(client, endpointID, container.ID); err != nil {
+				handleError(err)
+				return
+			}
+
+			if err := portainer.Print(container.ActionResult(endpointID, done)); err != nil {
+				handleError(err)
+			}
+		},
+	}
+	cmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: search all Docker endpoints)")
+	if destructive {
+		cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	}
+	return cmd
+}
+
+var containersInspectCmd = &cobra.Command{
+	Use:   "inspect <name-or-id>",
+	Short: "Show a container's state, ports, mounts and networks",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		endpointID, container, err := resolveContainer(client, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		inspect, err := client.InspectContainer(endpointID, container.ID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(inspect.ToDetail(endpointID)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// resolveContainer finds a container by exact name, else by ID prefix, on
+// the --endpoint or across every Docker endpoint. More than one match is
+// an error listing the candidates, so the caller can add --endpoint or use
+// an ID.
+func resolveContainer(client *portainer.Client, ref string) (int64, *portainer.APIContainer, error) {
+	ids, err := endpointIDs(client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	type match struct {
+		endpointID int64
+		container  portainer.APIContainer
+	}
+	var byName, byID []match
+	for _, eid := range ids {
+		containers, err := client.ListContainers(eid)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, c := range containers {
+			switch {
+			case c.Name() == ref:
+				byName = append(byName, match{eid, c})
+			case strings.HasPrefix(c.ID, ref):
+				byID = append(byID, match{eid, c})
+			}
+		}
+	}
+
+	matches := byName
+	if len(matches) == 0 {
+		matches = byID
+	}
+	switch len(matches) {
+	case 0:
+		return 0, nil, portainer.NotFoundError(fmt.Sprintf("container %q", ref))
+	case 1:
+		return matches[0].endpointID, &matches[0].container, nil
+	}
+
+	pe := portainer.ConfigError(fmt.Sprintf("%q matches %d containers; use --endpoint or a longer ID", ref, len(matches)))
+	for _, m := range matches {
+		pe.Details = append(pe.Details, fmt.Sprintf("%s (%s) on endpoint %d", m.container.Name(), m.container.ShortID(), m.endpointID))
+	}
+	return 0, nil, pe
+}
+
+func init() {
+	containersListCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Filter by endpoint ID")
+	containersInspectCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: search all Docker endpoints)")
+	for _, cmd := range []*cobra.Command{containersStopCmd, containersRestartCmd} {
+		cmd.Flags().IntVarP(&flagTimeout, "timeout", "t", -1, "Seconds to wait for a clean stop before killing (default: the container's own)")
+	}
+	containersKillCmd.Flags().StringVarP(&flagSignal, "signal", "s", "KILL", "Signal to send")
+	containersRmCmd.Flags().BoolVarP(&flagForce, "force", "f", false, "Stop the container first if it is running")
+	containersRmCmd.Flags().BoolVar(&flagVolumes, "volumes", false, "Also remove anonymous volumes")
+
+	containersCmd.AddCommand(containersListCmd)
+	containersCmd.AddCommand(containersStartCmd)
+	containersCmd.AddCommand(containersStopCmd)
+	containersCmd.AddCommand(containersRestartCmd)
+	containersCmd.AddCommand(containersKillCmd)
+	containersCmd.AddCommand(containersRmCmd)
+	containersCmd.AddCommand(containersInspectCmd)
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/schmoli/cli-tools/common"
+)
+
+// doctor checks portainer-cli's service for the doctor command
+var doctor = &common.Doctor{
+	URLEnv:     "PORTAINER_URL",
+	Service:    "Portainer",
+	MinVersion: "2.11",
+	Features:   "access tokens via X-API-Key",
+	Connect: func() (string, bool, error) {
+		url, _, insecure, err := getConfig()
+		return url, insecure, err
+	},
+	Version: func() (string, error) {
+		client, err := getClient()
+		if err != nil {
+			return "", err
+		}
+		return client.ServerVersion()
+	},
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagGroups []string
+	flagFailed bool
+)
+
+var edgeCmd = &cobra.Command{
+	Use:   "edge",
+	Short: "Manage Edge groups and Edge stacks",
+	Long: `Manage Edge groups and Edge stacks
+
+Edge stacks are deployed by the edge agents in their edge groups when the
+agents next check in. Needs Portainer 2.19 or later.`,
+}
+
+var edgeGroupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "Manage edge groups",
+}
+
+var edgeGroupsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List edge groups with their endpoints and stacks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		edge, err := client.Edge()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(edge.ToGroups()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var edgeStacksCmd = &cobra.Command{
+	Use:   "stacks",
+	Short: "Manage edge stacks",
+}
+
+var edgeStacksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List edge stacks with how many endpoints deployed them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		edge, err := client.Edge()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(edge.ToStackList()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var edgeStacksShowCmd = &cobra.Command{
+	Use:   "show <name-or-id>",
+	Short: "Show an edge stack with its status on each endpoint and its file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		edge, err := client.Edge()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		stack, err := edge.FindStack(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		file, err := client.GetEdgeStackFile(stack.ID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(edge.ToStack(stack, file.StackFileContent)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var edgeStacksDeployCmd = &cobra.Command{
+	Use:   "deploy <name>",
+	Short: "Deploy a compose file as an edge stack",
+	Long: `Deploy a compose file as an edge stack
+
+Creates the edge stack for the given edge groups, or updates it if one with
+that name exists, keeping its groups unless --group is given. An update
+bumps the stack's version so every agent redeploys it.`,
+	Example: `  portainer-cli edge stacks deploy monitor -f monitor.yml --group sites
+  portainer-cli edge stacks status monitor`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		content, err := readStackFile(cmd.InOrStdin(), flagStackFile)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		edge, err := client.Edge()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		groups, err := edge.GroupIDs(flagGroups)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stack, _ := edge.FindStack(args[0])
+		if stack != nil && stack.Name != args[0] {
+			// Matched by ID; deploy always names the stack
+			stack = nil
+		}
+
+		if stack == nil {
+			if len(groups) == 0 {
+				handleError(portainer.ConfigError("--group is required for a new edge stack"))
+				return
+			}
+			stack, err = client.CreateEdgeStack(args[0], content, groups)
+		} else {
+			if len(groups) == 0 {
+				groups = stack.EdgeGroups
+			}
+			stack, err = client.UpdateEdgeStack(stack.ID, content, groups, stack.DeploymentType)
+		}
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(edge.ToListItem(stack)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var edgeStacksStatusCmd = &cobra.Command{
+	Use:   "status [name-or-id]",
+	Short: "Show where edge stacks are deployed, failed or still pending",
+	Long: `Show where edge stacks are deployed, failed or still pending
+
+Lists one row per stack and endpoint, failures first with the agent's
+error. Without an argument every edge stack is listed. An endpoint is
+outdated when it last deployed an older version of the stack.`,
+	Example: `  portainer-cli edge stacks status --failed -o table`,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		edge, err := client.Edge()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stacks := edge.Stacks
+		if len(args) == 1 {
+			stack, err := edge.FindStack(args[0])
+			if err != nil {
+				handleError(err)
+				return
+			}
+			stacks = []portainer.APIEdgeStack{*stack}
+		}
+
+		out := []portainer.EdgeEndpointStatus{}
+		for i := range stacks {
+			for _, st := range edge.Status(&stacks[i]) {
+				if !flagFailed || st.Outcome == portainer.EdgeOutcomeFailed {
+					out = append(out, st)
+				}
+			}
+		}
+		if err := portainer.Print(out); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	edgeStacksDeployCmd.Flags().StringVarP(&flagStackFile, "file", "f", "", "Compose file to deploy (- for stdin)")
+	edgeStacksDeployCmd.Flags().StringArrayVarP(&flagGroups, "group", "g", nil, "Edge group name or ID (repeatable; required for a new stack)")
+	edgeStacksDeployCmd.MarkFlagRequired("file")
+	edgeStacksStatusCmd.Flags().BoolVar(&flagFailed, "failed", false, "Only endpoints that failed to deploy")
+
+	edgeGroupsCmd.AddCommand(edgeGroupsListCmd)
+	edgeStacksCmd.AddCommand(edgeStacksListCmd)
+	edgeStacksCmd.AddCommand(edgeStacksShowCmd)
+	edgeStacksCmd.AddCommand(edgeStacksDeployCmd)
+	edgeStacksCmd.AddCommand(edgeStacksStatusCmd)
+	edgeCmd.AddCommand(edgeGroupsCmd)
+	edgeCmd.AddCommand(edgeStacksCmd)
+}
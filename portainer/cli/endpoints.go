@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var endpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "Manage endpoints",
+}
+
+var endpointsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all endpoints",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		endpoints, err := client.ListEndpoints()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		output := portainer.EndpointList{
+			Endpoints: make([]portainer.Endpoint, len(endpoints)),
+		}
+		for i, e := range endpoints {
+			output.Endpoints[i] = e.ToEndpoint()
+		}
+
+		if err := portainer.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var endpointsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an endpoint by ID",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		endpoint, err := client.GetEndpoint(id)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(endpoint.ToEndpoint()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// endpointIDs is the endpoint given with --endpoint, or every endpoint
+// with a Docker API that is up, so one Kubernetes or offline endpoint
+// doesn't fail a command. Skipped offline endpoints are noted on stderr.
+func endpointIDs(client *portainer.Client) ([]int64, error) {
+	if flagEndpoint != 0 {
+		return []int64{flagEndpoint}, nil
+	}
+	endpoints, err := client.ListEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(endpoints))
+	for _, e := range endpoints {
+		switch {
+		case !e.HasDocker():
+		case e.StatusLabel() == "inactive":
+			fmt.Fprintf(os.Stderr, "# endpoint %q (ID %d) is down; skipped\n", e.Name, e.ID)
+		default:
+			ids = append(ids, e.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, portainer.NotFoundError("no Docker endpoints")
+	}
+	return ids, nil
+}
+
+// targetEndpoint is the endpoint given with --endpoint. Without one it is
+// the only endpoint, so single-host setups can leave the flag out.
+func targetEndpoint(client *portainer.Client) (int64, error) {
+	if flagEndpoint != 0 {
+		return flagEndpoint, nil
+	}
+	endpoints, err := client.ListEndpoints()
+	if err != nil {
+		return 0, err
+	}
+	if len(endpoints) == 1 {
+		return endpoints[0].ID, nil
+	}
+	pe := portainer.ConfigError(fmt.Sprintf("%d endpoints; choose one with --endpoint", len(endpoints)))
+	for _, e := range endpoints {
+		pe.Details = append(pe.Details, fmt.Sprintf("%d: %s", e.ID, e.Name))
+	}
+	return 0, pe
+}
+
+func init() {
+	endpointsCmd.AddCommand(endpointsListCmd)
+	endpointsCmd.AddCommand(endpointsShowCmd)
+}
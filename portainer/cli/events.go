@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagFilters []string
+	flagExec    string
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream Docker events from endpoints",
+	Long: `Stream Docker events from endpoints
+
+Events are printed as they happen, one record each: a JSON line with
+-o json, a YAML document by default, or a table row. Without --endpoint
+every Docker endpoint is followed. Dropped streams are reconnected with
+backoff, resuming after the last event seen; reconnects are reported on
+stderr.
+
+Filters use Docker's names (type, event, container, image, label, ...);
+repeat a key to match any of its values.
+
+--exec runs a shell command for each event, with the event as a JSON line
+on stdin and its fields in PORTAINER_EVENT_* variables. Hooks run one at a
+time, and their output goes to stderr.`,
+	Example: `  portainer-cli events --filter type=container,event=die -o json
+  portainer-cli events --filter event=die --filter event=oom \
+    --exec 'notify-send "$PORTAINER_EVENT_NAME exited $PORTAINER_EVENT_EXIT_CODE"'`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		filters, err := portainer.ParseEventFilters(flagFilters)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		ids, err := endpointIDs(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		if err := followEvents(ctx, client, ids, filters); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// followEvents follows every endpoint at once until ctx is cancelled. The
+// first error that reconnecting can't fix stops them all.
+func followEvents(ctx context.Context, client *portainer.Client, ids []int64, filters portainer.EventFilters) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := portainer.Records()
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		first error
+	)
+	handle := func(e portainer.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := records.Write(e); err != nil {
+			return err
+		}
+		if flagExec != "" {
+			runEventHook(ctx, flagExec, e)
+		}
+		return nil
+	}
+
+	for _, eid := range ids {
+		wg.Add(1)
+		go func(eid int64) {
+			defer wg.Done()
+			err := client.FollowEvents(ctx, eid, filters, handle, func(err error, retry time.Duration) {
+				fmt.Fprintf(os.Stderr, "endpoint %d: event stream dropped (%s); reconnecting in %s\n", eid, err, retry)
+			})
+			if err != nil {
+				mu.Lock()
+				if first == nil {
+					first = err
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(eid)
+	}
+	wg.Wait()
+	return first
+}
+
+// runEventHook runs the --exec command for an event. A failing hook is
+// reported on stderr and the stream carries on.
+func runEventHook(ctx context.Context, command string, e portainer.Event) {
+	var line bytes.Buffer
+	printer := &common.Printer{Format: common.FormatJSON}
+	if err := printer.Records(&line).Write(e); err != nil {
+		fmt.Fprintf(os.Stderr, "exec hook: %s\n", err)
+		return
+	}
+
+	hook := exec.CommandContext(ctx, "sh", "-c", command)
+	hook.Stdin = &line
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	hook.Env = append(os.Environ(),
+		"PORTAINER_ENDPOINT_ID="+fmt.Sprint(e.EndpointID),
+		"PORTAINER_EVENT_TIME="+e.Time,
+		"PORTAINER_EVENT_TYPE="+e.Type,
+		"PORTAINER_EVENT_ACTION="+e.Action,
+		"PORTAINER_EVENT_ID="+e.ID,
+		"PORTAINER_EVENT_NAME="+e.Name,
+		"PORTAINER_EVENT_IMAGE="+e.Image,
+		"PORTAINER_EVENT_STACK="+e.Stack,
+		"PORTAINER_EVENT_EXIT_CODE="+e.ExitCode,
+	)
+	if err := hook.Run(); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "exec hook failed for %s %s %s: %s\n", e.Type, e.Action, e.ID, err)
+	}
+}
+
+func init() {
+	eventsCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all Docker endpoints)")
+	eventsCmd.Flags().StringArrayVar(&flagFilters, "filter", nil, "Docker event filter as KEY=VALUE[,KEY=VALUE] (repeatable)")
+	eventsCmd.Flags().StringVar(&flagExec, "exec", "", "Shell command to run for each event")
+}
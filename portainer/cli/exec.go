@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagInteractive bool
+	flagTTY         bool
+)
+
+var containersExecCmd = &cobra.Command{
+	Use:   "exec <name-or-id> -- <command> [args...]",
+	Short: "Run a command in a container",
+	Long: `Run a command in a container
+
+The command runs through Portainer's websocket exec endpoint, so no SSH
+access to the Docker host is needed. With -t the local terminal is put in
+raw mode and window resizes are forwarded. The exit code is the command's.`,
+	Example: `  portainer-cli containers exec -it web -- sh
+  portainer-cli containers exec db -- pg_dump -U app app > app.sql`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			handleError(portainer.ConfigError("no command given, e.g. portainer-cli containers exec -it web -- sh"))
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		endpointID, container, err := resolveContainer(client, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		execID, err := client.CreateExec(endpointID, container.ID, args[1:], flagInteractive, flagTTY)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := execSession(client, endpointID, execID); err != nil {
+			handleError(err)
+			return
+		}
+
+		exec, err := client.InspectExec(endpointID, execID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		if exec.ExitCode != 0 {
+			os.Exit(exec.ExitCode)
+		}
+	},
+}
+
+// execSession attaches to a created exec and copies the terminal to and
+// from it until the command exits or the user interrupts. The terminal
+// is restored before returning, so callers may exit afterwards.
+func execSession(client *portainer.Client, endpointID int64, execID string) error {
+	ctx, stop := interruptContext()
+	defer stop()
+
+	ws, err := client.AttachExec(ctx, endpointID, execID)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	go func() {
+		<-ctx.Done()
+		ws.Close()
+	}()
+
+	if flagTTY {
+		stdin := int(os.Stdin.Fd())
+		if flagInteractive && term.IsTerminal(stdin) {
+			state, err := term.MakeRaw(stdin)
+			if err != nil {
+				return portainer.ConfigError(fmt.Sprintf("cannot put terminal in raw mode: %s", err))
+			}
+			defer term.Restore(stdin, state)
+		}
+		stopResize := forwardResize(ctx, client, endpointID, execID)
+		defer stopResize()
+	}
+
+	if flagInteractive {
+		go io.Copy(ws, os.Stdin)
+	}
+
+	if _, err := io.Copy(os.Stdout, ws); err != nil && ctx.Err() == nil {
+		return portainer.NetworkError(fmt.Sprintf("exec session failed: %s", err))
+	}
+	return nil
+}
+
+// forwardResize sends the terminal size now and on every SIGWINCH. Resizes
+// are best effort: a failure only leaves the remote size stale.
+func forwardResize(ctx context.Context, client *portainer.Client, endpointID int64, execID string) func() {
+	stdout := int(os.Stdout.Fd())
+	if !term.IsTerminal(stdout) {
+		return func() {}
+	}
+	resize := func() {
+		if width, height, err := term.GetSize(stdout); err == nil {
+			client.ResizeExec(endpointID, execID, width, height)
+		}
+	}
+	resize()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-winch:
+				resize()
+			}
+		}
+	}()
+	return func() { signal.Stop(winch) }
+}
+
+func init() {
+	containersExecCmd.Flags().BoolVarP(&flagInteractive, "interactive", "i", false, "Send stdin to the command")
+	containersExecCmd.Flags().BoolVarP(&flagTTY, "tty", "t", false, "Allocate a terminal (raw mode, resizes forwarded)")
+	containersExecCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: search all Docker endpoints)")
+	containersExecCmd.Flags().SetInterspersed(false)
+
+	containersCmd.AddCommand(containersExecCmd)
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var flagDangling bool
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Manage images on endpoints",
+}
+
+var imagesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List images with their size and the containers using them",
+	Long: `List images with their size and the containers using them
+
+The totals include the space reclaimable by removing images no container
+uses, excluding layers they share with other images.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		usage, err := diskUsage(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(portainer.NewImageList(usage, flagDangling)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var imagesPullCmd = &cobra.Command{
+	Use:   "pull <image>",
+	Short: "Pull an image on an endpoint",
+	Long: `Pull an image on an endpoint
+
+Registries configured in Portainer are used with their stored credentials.
+Layer progress is written to stderr.`,
+	Example: `  portainer-cli images pull nginx:1.27 --endpoint 2`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ref := portainer.ParseImageRef(args[0])
+		if ref.Digest != "" {
+			handleError(portainer.ConfigError("pulling by digest is not supported; use a tag"))
+			return
+		}
+
+		// Listing registries needs admin rights; without them the pull is
+		// anonymous
+		registries, _ := client.ListRegistries()
+
+		// Interrupting stops the progress output; Docker finishes the pull
+		body, err := client.PullImage(context.Background(), flagEndpoint, ref, portainer.MatchRegistry(ref, registries))
+		if err != nil {
+			handleError(err)
+			return
+		}
+		defer body.Close()
+
+		digest, err := portainer.ReadPull(body, os.Stderr)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		result := portainer.ImagePullResult{Image: args[0], Endpoint: flagEndpoint, Digest: digest, Status: "pulled"}
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var imagesRmCmd = &cobra.Command{
+	Use:   "rm <image>",
+	Short: "Remove an image from an endpoint",
+	Long: `Remove an image from an endpoint
+
+The argument is a tag or image ID. Removing a tag deletes the image once no
+other tag refers to it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Remove image %q on endpoint %d?", args[0], flagEndpoint)
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		items, err := client.RemoveImage(flagEndpoint, args[0], flagForce)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		result := portainer.ImageRemoveResult{Image: args[0], Endpoint: flagEndpoint, Untagged: []string{}, Deleted: []string{}}
+		for _, item := range items {
+			if item.Untagged != "" {
+				result.Untagged = append(result.Untagged, item.Untagged)
+			}
+			if item.Deleted != "" {
+				result.Deleted = append(result.Deleted, item.Deleted)
+			}
+		}
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var imagesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove dangling images from an endpoint",
+	Long: `Remove dangling images from an endpoint
+
+With --all, every image not used by a container is removed. The prompt
+shows how much space that is expected to free.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			du, err := client.DiskUsage(flagEndpoint)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			var images []portainer.APIImage
+			for _, img := range du.Images {
+				if img.Containers == 0 && (flagAll || img.Dangling()) {
+					images = append(images, img)
+				}
+			}
+			list := portainer.NewImageList(map[int64]*portainer.APIDiskUsage{flagEndpoint: {Images: images}}, false)
+			if list.Count == 0 {
+				report := portainer.APIPruneReport{}
+				if err := portainer.Print(report.ToResult(flagEndpoint)); err != nil {
+					handleError(err)
+				}
+				return
+			}
+
+			question := fmt.Sprintf("Remove %d unused images on endpoint %d, reclaiming about %s?", list.Count, flagEndpoint, list.Reclaimable)
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		report, err := client.PruneImages(flagEndpoint, flagAll)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(report.ToResult(flagEndpoint)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// diskUsage fetches image and volume usage for --endpoint or every endpoint.
+func diskUsage(client *portainer.Client) (map[int64]*portainer.APIDiskUsage, error) {
+	ids, err := endpointIDs(client)
+	if err != nil {
+		return nil, err
+	}
+	usage := map[int64]*portainer.APIDiskUsage{}
+	for _, eid := range ids {
+		du, err := client.DiskUsage(eid)
+		if err != nil {
+			return nil, err
+		}
+		usage[eid] = du
+	}
+	return usage, nil
+}
+
+func init() {
+	imagesListCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all endpoints)")
+	imagesListCmd.Flags().BoolVar(&flagDangling, "dangling", false, "Only untagged images")
+	for _, cmd := range []*cobra.Command{imagesPullCmd, imagesRmCmd, imagesPruneCmd} {
+		cmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID")
+		cmd.MarkFlagRequired("endpoint")
+	}
+	for _, cmd := range []*cobra.Command{imagesRmCmd, imagesPruneCmd} {
+		cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	}
+	imagesRmCmd.Flags().BoolVarP(&flagForce, "force", "f", false, "Remove even if stopped containers use the image")
+	imagesPruneCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "Remove all unused images, not just dangling ones")
+
+	imagesCmd.AddCommand(imagesListCmd)
+	imagesCmd.AddCommand(imagesPullCmd)
+	imagesCmd.AddCommand(imagesRmCmd)
+	imagesCmd.AddCommand(imagesPruneCmd)
+}
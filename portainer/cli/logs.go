@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagFollow     bool
+	flagTail       int
+	flagSince      string
+	flagTimestamps bool
+)
+
+var containersLogsCmd = &cobra.Command{
+	Use:   "logs <name-or-id>",
+	Short: "Print a container's logs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		opts, err := logOptions()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		endpointID, container, err := resolveContainer(client, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		inspect, err := client.InspectContainer(endpointID, container.ID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		body, err := client.ContainerLogs(ctx, endpointID, container.ID, opts)
+		if err == nil {
+			err = portainer.DemuxLogs(body, os.Stdout, os.Stderr, inspect.Config.Tty)
+			body.Close()
+		}
+		if err := streamError(ctx, err); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var stacksLogsCmd = &cobra.Command{
+	Use:   "logs <stack-id>",
+	Short: "Print the logs of every container in a stack",
+	Long: `Print the logs of every container in a stack
+
+Lines from each container are prefixed with its name, coloured when
+writing to a terminal (set NO_COLOR to disable).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		opts, err := logOptions()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stack, err := findStack(client, id)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		containers, err := client.ListContainers(stack.EndpointID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		var members []portainer.APIContainer
+		for _, c := range containers {
+			if c.Labels["com.docker.compose.project"] == stack.Name {
+				members = append(members, c)
+			}
+		}
+		if len(members) == 0 {
+			handleError(portainer.NotFoundError(fmt.Sprintf("no containers for stack %q", stack.Name)))
+			return
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].Name() < members[j].Name()
+		})
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		err = stackLogs(ctx, client, stack.EndpointID, members, opts)
+		if err := streamError(ctx, err); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// stackLogs streams every container's logs at once, each line prefixed
+// with the padded container name. The first error is returned once all
+// streams have ended.
+func stackLogs(ctx context.Context, client *portainer.Client, endpointID int64, containers []portainer.APIContainer, opts portainer.LogOptions) error {
+	width := 0
+	for _, c := range containers {
+		width = max(width, len(c.Name()))
+	}
+	color := common.UseColor(os.Stdout)
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		first error
+	)
+	for i, c := range containers {
+		prefix := common.Label(fmt.Sprintf("%-*s |", width, c.Name()), i, color) + " "
+		stdout := portainer.NewPrefixWriter(os.Stdout, &mu, prefix)
+		stderr := portainer.NewPrefixWriter(os.Stderr, &mu, prefix)
+
+		wg.Add(1)
+		go func(c portainer.APIContainer) {
+			defer wg.Done()
+			err := containerLogs(ctx, client, endpointID, c.ID, opts, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
+			if err != nil {
+				errMu.Lock()
+				if first == nil {
+					first = err
+				}
+				errMu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return first
+}
+
+func containerLogs(ctx context.Context, client *portainer.Client, endpointID int64, id string, opts portainer.LogOptions, stdout, stderr io.Writer) error {
+	inspect, err := client.InspectContainer(endpointID, id)
+	if err != nil {
+		return err
+	}
+	body, err := client.ContainerLogs(ctx, endpointID, id, opts)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return portainer.DemuxLogs(body, stdout, stderr, inspect.Config.Tty)
+}
+
+func logOptions() (portainer.LogOptions, error) {
+	opts := portainer.LogOptions{Follow: flagFollow, Tail: flagTail, Timestamps: flagTimestamps}
+	if flagSince != "" {
+		since, err := portainer.ParseSince(flagSince, time.Now())
+		if err != nil {
+			return opts, err
+		}
+		opts.Since = since
+	}
+	return opts, nil
+}
+
+// interruptContext is cancelled by Ctrl-C or SIGTERM, closing any open
+// log streams.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// streamError hides the error from a stream closed by Ctrl-C, which is
+// how a followed log normally ends.
+func streamError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	if _, ok := err.(*portainer.PortainerError); ok {
+		return err
+	}
+	return portainer.NetworkError(fmt.Sprintf("log stream failed: %s", err))
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{containersLogsCmd, stacksLogsCmd} {
+		cmd.Flags().BoolVarP(&flagFollow, "follow", "f", false, "Keep streaming new output until interrupted")
+		cmd.Flags().IntVar(&flagTail, "tail", -1, "Number of lines to show from the end (default: all)")
+		cmd.Flags().StringVar(&flagSince, "since", "", "Only show output since a duration ago (10m) or a time (RFC3339)")
+		cmd.Flags().BoolVar(&flagTimestamps, "timestamps", false, "Prefix each line with its timestamp")
+	}
+	containersLogsCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: search all Docker endpoints)")
+
+	containersCmd.AddCommand(containersLogsCmd)
+	stacksCmd.AddCommand(stacksLogsCmd)
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var networksCmd = &cobra.Command{
+	Use:   "networks",
+	Short: "Inspect networks on endpoints",
+}
+
+var networksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List networks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ids, err := endpointIDs(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		output := portainer.NetworkList{}
+		for _, eid := range ids {
+			networks, err := client.ListNetworks(eid)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			for _, n := range networks {
+				output = append(output, n.ToNetwork(eid))
+			}
+		}
+
+		sort.Slice(output, func(i, j int) bool {
+			if output[i].Endpoint != output[j].Endpoint {
+				return output[i].Endpoint < output[j].Endpoint
+			}
+			return output[i].Name < output[j].Name
+		})
+
+		if err := portainer.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var networksInspectCmd = &cobra.Command{
+	Use:   "inspect <name-or-id>",
+	Short: "Show a network's subnets and attached containers",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		network, err := client.InspectNetwork(flagEndpoint, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(network.ToDetail(flagEndpoint)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	networksListCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all endpoints)")
+	networksInspectCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID")
+	networksInspectCmd.MarkFlagRequired("endpoint")
+
+	networksCmd.AddCommand(networksListCmd)
+	networksCmd.AddCommand(networksInspectCmd)
+}
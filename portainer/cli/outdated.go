@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+// outdatedWorkers bounds concurrent image and registry lookups
+const outdatedWorkers = 8
+
+var stacksOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List stacks whose images have newer versions in the registry",
+	Long: `List stacks whose images have newer versions in the registry
+
+For each running stack container, the digest the image was pulled with is
+compared with the registry's current digest for the same tag. Registries
+with credentials in Portainer are queried through Portainer; others are
+queried directly, anonymously. Images pinned by digest or built locally
+are skipped.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ids, err := endpointIDs(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		// Listing registries needs admin rights; without them every
+		// registry is queried directly
+		registries, _ := client.ListRegistries()
+
+		type target struct {
+			endpointID int64
+			container  portainer.APIContainer
+		}
+		var targets []target
+		for _, eid := range ids {
+			containers, err := client.ListContainers(eid)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			for _, c := range containers {
+				if c.State == "running" && c.Labels["com.docker.compose.project"] != "" {
+					targets = append(targets, target{eid, c})
+				}
+			}
+		}
+
+		remote := cachedDigests(func(ref portainer.ImageRef) (string, error) {
+			return client.RemoteDigest(ref, registries)
+		})
+
+		checks := make([]portainer.ImageCheck, len(targets))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, outdatedWorkers)
+		for i, t := range targets {
+			wg.Add(1)
+			go func(i int, t target) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				check := t.container.ImageCheck(t.endpointID)
+				image, err := client.InspectImage(t.endpointID, t.container.ImageID)
+				if err != nil {
+					check.Status = portainer.ImageError
+					check.Error = err.Error()
+				} else {
+					check.Resolve(image.RepoDigests, remote)
+				}
+				checks[i] = check
+			}(i, t)
+		}
+		wg.Wait()
+
+		if err := portainer.Print(portainer.NewOutdatedReport(checks)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// cachedDigests looks each image reference up once, however many
+// containers use it.
+func cachedDigests(lookup func(portainer.ImageRef) (string, error)) func(portainer.ImageRef) (string, error) {
+	type result struct {
+		once   sync.Once
+		digest string
+		err    error
+	}
+	var mu sync.Mutex
+	cache := map[string]*result{}
+	return func(ref portainer.ImageRef) (string, error) {
+		mu.Lock()
+		r, ok := cache[ref.String()]
+		if !ok {
+			r = &result{}
+			cache[ref.String()] = r
+		}
+		mu.Unlock()
+		r.once.Do(func() {
+			r.digest, r.err = lookup(ref)
+		})
+		return r.digest, r.err
+	}
+}
+
+func init() {
+	stacksOutdatedCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all Docker endpoints)")
+
+	stacksCmd.AddCommand(stacksOutdatedCmd)
+}
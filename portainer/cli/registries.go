@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagRegistryType string
+	flagRegistryURL  string
+	flagUsername     string
+	flagImage        string
+)
+
+var registriesCmd = &cobra.Command{
+	Use:   "registries",
+	Short: "Manage registry credentials (administrators only)",
+}
+
+var registriesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registries configured in Portainer",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		registries, err := client.ListRegistries()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		out := make([]portainer.Registry, len(registries))
+		for i := range registries {
+			out[i] = registries[i].ToRegistry()
+		}
+		if err := portainer.Print(out); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var registriesAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a registry",
+	Long: `Add a registry
+
+--registry-url defaults to the public host for dockerhub, ghcr and quay. With
+--username the password or access token is prompted for on a terminal, or
+read from the first line of stdin so it can be piped in.`,
+	Example: `  portainer-cli registries add github --type ghcr --username bot
+  portainer-cli registries add lan --type custom --registry-url registry.lan:5000`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		registryType, err := portainer.ParseRegistryType(flagRegistryType)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var password string
+		if flagUsername != "" {
+			if password, err = common.ReadSecret(cmd.InOrStdin(), "password"); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		req, err := portainer.NewRegistryRequest(args[0], registryType, flagRegistryURL, flagUsername, password)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		registry, err := client.CreateRegistry(req)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(registry.ToRegistry()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var registriesRmCmd = &cobra.Command{
+	Use:   "rm <name-or-id>",
+	Short: "Remove a registry and its credentials",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		registries, err := client.ListRegistries()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		registry, err := portainer.FindRegistry(registries, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Remove registry %q (%s)?", registry.Name, registry.Host())
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		if err := client.DeleteRegistry(registry.ID); err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(registry.ToRegistry()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var registriesTestCmd = &cobra.Command{
+	Use:   "test <name-or-id>",
+	Short: "Check that a registry answers and accepts its credentials",
+	Long: `Check that a registry answers and accepts its credentials
+
+Registries with credentials are checked through Portainer, which logs in
+with the stored ones. With --image the image's manifest is also looked up,
+proving read access to that repository. The image may leave out the
+registry host.`,
+	Example: `  portainer-cli registries test github --image schmoli/app:latest`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		registries, err := client.ListRegistries()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		registry, err := portainer.FindRegistry(registries, args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := client.PingRegistry(registry); err != nil {
+			handleError(err)
+			return
+		}
+		result := portainer.RegistryTest{ID: registry.ID, Name: registry.Name, Host: registry.Host(), Checked: "reachable"}
+		if registry.Authentication {
+			result.Checked = "credentials"
+		}
+
+		if flagImage != "" {
+			ref, err := registry.ImageRef(flagImage)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			digest, err := client.RemoteDigest(ref, []portainer.APIRegistry{*registry})
+			if err != nil {
+				handleError(err)
+				return
+			}
+			result.Checked, result.Image, result.Digest = "image", ref.String(), digest
+		}
+
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	registriesAddCmd.Flags().StringVar(&flagRegistryType, "type", "custom", "Registry type: dockerhub, ghcr, quay, gitlab, ecr, azure, proget or custom")
+	registriesAddCmd.Flags().StringVar(&flagRegistryURL, "registry-url", "", "Registry host, e.g. registry.lan:5000 (default for dockerhub, ghcr and quay)")
+	registriesAddCmd.Flags().StringVar(&flagUsername, "username", "", "Username; the password is read from stdin or a prompt")
+	registriesRmCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	registriesTestCmd.Flags().StringVar(&flagImage, "image", "", "Also check read access to this image")
+
+	registriesCmd.AddCommand(registriesListCmd)
+	registriesCmd.AddCommand(registriesAddCmd)
+	registriesCmd.AddCommand(registriesRmCmd)
+	registriesCmd.AddCommand(registriesTestCmd)
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagURL         string
+	flagToken       string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "portainer-cli",
+	Short: "CLI for Portainer API",
+}
+
+// tool describes portainer-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "portainer",
+	Keys:    []string{"url", "token", "insecure", "secret-patterns"},
+	Secrets: []string{"token"},
+	Context: &flagContext,
+	Print:   portainer.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "Portainer URL (or set PORTAINER_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagToken, "token", "", "API token (or set PORTAINER_TOKEN)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for portainer-cli")
+	rootCmd.PersistentPreRun = setup
+
+	rootCmd.AddCommand(stacksCmd)
+	rootCmd.AddCommand(endpointsCmd)
+	rootCmd.AddCommand(containersCmd)
+	rootCmd.AddCommand(imagesCmd)
+	rootCmd.AddCommand(volumesCmd)
+	rootCmd.AddCommand(networksCmd)
+	rootCmd.AddCommand(usersCmd)
+	rootCmd.AddCommand(teamsCmd)
+	rootCmd.AddCommand(accessCmd)
+	rootCmd.AddCommand(registriesCmd)
+	rootCmd.AddCommand(templatesCmd)
+	rootCmd.AddCommand(edgeCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func parseID(arg string) (int64, error) {
+	var id int64
+	if _, err := fmt.Sscanf(arg, "%d", &id); err != nil {
+		return 0, portainer.ConfigError(fmt.Sprintf("invalid ID: %s", arg))
+	}
+	if id <= 0 {
+		return 0, portainer.ConfigError("ID must be positive")
+	}
+	return id, nil
+}
+
+func getConfig() (string, string, bool, error) {
+	url, token, insecure, err := resolveConfig()
+	if err != nil {
+		return "", "", false, err
+	}
+	if token == "" {
+		return "", "", false, portainer.ConfigError("missing token. Use --token, set PORTAINER_TOKEN or run 'portainer-cli config set-secret token'")
+	}
+	return url, token, insecure, nil
+}
+
+// resolveConfig is getConfig without requiring a token, for the few
+// requests a Portainer that is not set up yet accepts.
+func resolveConfig() (string, string, bool, error) {
+	file, err := common.LoadServiceConfig("portainer", flagContext)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	url := flagURL
+	if url == "" {
+		url = os.Getenv("PORTAINER_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", false, portainer.ConfigError("missing URL. Use --url, set PORTAINER_URL or add url to a config context")
+	}
+
+	token := flagToken
+	if token == "" {
+		token = os.Getenv("PORTAINER_TOKEN")
+	}
+	if token == "" {
+		if token, err = file.Secret("token"); err != nil {
+			return "", "", false, err
+		}
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", false, portainer.ConfigError("URL must start with http:// or https://")
+	}
+
+	return url, token, flagInsecure || file.Bool("insecure"), nil
+}
+
+// redactor masks secrets in output by the secret-patterns setting, a
+// comma-separated list such as "*PASSWORD*,*DSN*" that replaces the
+// defaults. It is nil with --show-secrets.
+func redactor() (*common.Redactor, error) {
+	if flagShowSecrets {
+		return nil, nil
+	}
+	file, err := common.LoadServiceConfig("portainer", flagContext)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewRedactor(common.ParsePatterns(file.Get("secret-patterns"))), nil
+}
+
+func getClient() (*portainer.Client, error) {
+	url, token, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return portainer.NewClient(url, token, insecure), nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := portainer.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	portainer.PrintError(err)
+	if pe, ok := err.(*portainer.PortainerError); ok {
+		os.Exit(pe.ExitCode())
+	}
+	os.Exit(1)
+}
+
+// Command returns the portainer-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of portainer-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
@@ -0,0 +1,671 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagStackFile   string
+	flagEnvFile     string
+	flagPrune       bool
+	flagPullImage   bool
+	flagYes         bool
+	flagRef         string
+	flagAll         bool
+	flagDir         string
+	flagShowSecrets bool
+)
+
+var stacksCmd = &cobra.Command{
+	Use:   "stacks",
+	Short: "Manage stacks",
+}
+
+var stacksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all stacks",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		output := portainer.StackList{
+			Stacks: make([]portainer.StackListItem, len(stacks)),
+		}
+		for i, s := range stacks {
+			output.Stacks[i] = s.ToListItem()
+		}
+
+		if err := portainer.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var stacksShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a stack by ID",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		// Get stack list to find endpoint_id
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var apiStack *portainer.APIStack
+		for i := range stacks {
+			if stacks[i].ID == id {
+				apiStack = &stacks[i]
+				break
+			}
+		}
+		if apiStack == nil {
+			handleError(portainer.NotFoundError(fmt.Sprintf("stack with ID %d", id)))
+			return
+		}
+
+		// Get stack file content
+		file, err := client.GetStackFile(id)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		printStack(apiStack.ToStack(file.StackFileContent))
+	},
+}
+
+var stacksContainersCmd = &cobra.Command{
+	Use:   "containers <stack-id>",
+	Short: "List containers for a stack",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		// Get stack to find endpoint and name
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var apiStack *portainer.APIStack
+		for i := range stacks {
+			if stacks[i].ID == id {
+				apiStack = &stacks[i]
+				break
+			}
+		}
+		if apiStack == nil {
+			handleError(portainer.NotFoundError(fmt.Sprintf("stack with ID %d", id)))
+			return
+		}
+
+		// Get containers for that endpoint
+		containers, err := client.ListContainers(apiStack.EndpointID)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		// Filter by stack name
+		var output portainer.ContainerList
+		for _, c := range containers {
+			item := c.ToListItem(apiStack.EndpointID)
+			if item.Stack == apiStack.Name {
+				output = append(output, item)
+			}
+		}
+
+		// Sort by name
+		sort.Slice(output, func(i, j int) bool {
+			return output[i].Name < output[j].Name
+		})
+
+		if err := portainer.Print(output); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var stacksDeployCmd = &cobra.Command{
+	Use:   "deploy <name>",
+	Short: "Deploy a compose stack from a local file",
+	Long: `Deploy a compose stack from a local file.
+
+Creates the stack on the endpoint, or updates it in place if a stack with
+that name already exists there, so the same command works on every CI run.
+Portainer can't pull or prune while creating, so with --pull-image or
+--prune a new stack is redeployed with them right after it is created.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		content, err := readStackFile(cmd.InOrStdin(), flagStackFile)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		env, err := readEnvFile(flagEnvFile)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stacks, err := client.ListStacks()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var stack *portainer.APIStack
+		for i := range stacks {
+			if stacks[i].Name == args[0] && stacks[i].EndpointID == flagEndpoint {
+				stack = &stacks[i]
+				break
+			}
+		}
+
+		if stack == nil {
+			stack, err = client.CreateStack(flagEndpoint, args[0], content, env)
+			if err == nil && (flagPrune || flagPullImage) {
+				created := stack
+				if stack, err = client.UpdateStack(created.ID, created.EndpointID, content, env, flagPrune, flagPullImage); err != nil {
+					if pe, ok := err.(*portainer.PortainerError); ok {
+						pe.Details = append(pe.Details, fmt.Sprintf("stack %q (ID %d) was created, but not redeployed with --pull-image/--prune", created.Name, created.ID))
+					}
+				}
+			}
+		} else {
+			if flagEnvFile == "" {
+				env = stack.Env
+			}
+			stack, err = client.UpdateStack(stack.ID, stack.EndpointID, content, env, flagPrune, flagPullImage)
+		}
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		printStack(stack.ToStack(""))
+	},
+}
+
+var stacksUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Update a stack's compose file and env, then redeploy it",
+	Long: `Update a stack's compose file and env, then redeploy it.
+
+Without -f the current compose file is kept, and without --env-file the
+current env is kept, so "update <id> --pull-image" just redeploys with
+fresh images.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stack, err := findStack(client, id)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var content string
+		if flagStackFile != "" {
+			content, err = readStackFile(cmd.InOrStdin(), flagStackFile)
+		} else {
+			var file *portainer.APIStackFile
+			if file, err = client.GetStackFile(id); err == nil {
+				content = file.StackFileContent
+			}
+		}
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		env := stack.Env
+		if flagEnvFile != "" {
+			if env, err = readEnvFile(flagEnvFile); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		updated, err := client.UpdateStack(id, stack.EndpointID, content, env, flagPrune, flagPullImage)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		printStack(updated.ToStack(""))
+	},
+}
+
+var (
+	stacksStartCmd = stackAction("start", "Start a stopped stack", "started", false,
+		func(client *portainer.Client, s *portainer.APIStack) error {
+			return client.StartStack(s.ID, s.EndpointID)
+		})
+	stacksStopCmd = stackAction("stop", "Stop a stack's containers", "stopped", true,
+		func(client *portainer.Client, s *portainer.APIStack) error {
+			return client.StopStack(s.ID, s.EndpointID)
+		})
+	stacksRestartCmd = stackAction("restart", "Stop and start a stack", "restarted", true,
+		func(client *portainer.Client, s *portainer.APIStack) error {
+			return client.RestartStack(s)
+		})
+	stacksDeleteCmd = stackAction("delete", "Delete a stack and its containers", "deleted", true,
+		func(client *portainer.Client, s *portainer.APIStack) error {
+			return client.DeleteStack(s.ID, s.EndpointID)
+		})
+)
+
+// stackAction builds a lifecycle command: it resolves the stack's endpoint
+// from the stack list, asks for confirmation when destructive unless --yes
+// is given, runs the action and prints the result.
+func stackAction(name, short, done string, destructive bool, run func(*portainer.Client, *portainer.APIStack) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name + " <id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+				return
+			}
+
+			id, err := parseID(args[0])
+			if err != nil {
+				handleError(err)
+				return
+			}
+
+			stack, err := findStack(client, id)
+			if err != nil {
+				handleError(err)
+				return
+			}
+
+			if destructive && !flagYes {
+				question := fmt.Sprintf("%s stack %q (ID %d) on endpoint %d?", strings.ToUpper(name[:1])+name[1:], stack.Name, stack.ID, stack.EndpointID)
+				if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+					handleError(err)
+					return
+				}
+			}
+
+			if err := run(client, stack); err != nil {
+				handleError(err)
+				return
+			}
+
+			if err := portainer.Print(stack.ActionResult(done)); err != nil {
+				handleError(err)
+			}
+		},
+	}
+	if destructive {
+		cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	}
+	return cmd
+}
+
+var stacksRedeployCmd = &cobra.Command{
+	Use:   "redeploy <id>",
+	Short: "Pull the latest commit of a git-backed stack and redeploy it",
+	Long: `Pull the latest commit of a git-backed stack and redeploy it.
+
+Images are re-pulled and the stack's env is kept. Use --ref to switch
+branch or tag, and --prune to remove services dropped from the compose
+file.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		stack, err := findStack(client, id)
+		if err != nil {
+			handleError(err)
+			return
+		}
+		if stack.GitConfig == nil || stack.GitConfig.URL == "" {
+			handleError(portainer.ConfigError(fmt.Sprintf("stack %q is not deployed from git; use 'stacks update' instead", stack.Name)))
+			return
+		}
+
+		ref := flagRef
+		if ref == "" {
+			ref = stack.GitConfig.ReferenceName
+		}
+		updated, err := client.RedeployGitStack(stack.ID, stack.EndpointID, ref, stack.Env, flagPrune, true)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		printStack(updated.ToStack(""))
+	},
+}
+
+var stacksDiffCmd = &cobra.Command{
+	Use:   "diff [<id>]",
+	Short: "Compare deployed stacks with local compose files",
+	Long: `Compare deployed stacks with local compose files.
+
+Prints a unified diff of the compose file (and env, when an env file is
+given or found) and exits with code 9 if anything differs, so a scheduled
+job can alert on changes made in the Portainer UI.
+
+With --all, every stack is matched by name to a file in --dir:
+<name>.yml, <name>.yaml or <name>/[docker-]compose.y[a]ml, with env from
+<name>.env or <name>/.env if present. Stacks without a file are listed as
+no-file and do not count as drift.
+
+Secret env values are compared as they are but shown masked; a changed
+secret is marked "(changed)". --show-secrets prints them in full.
+
+-o prints a structured report instead of the diff text.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if flagAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var stacks []portainer.APIStack
+		if flagAll {
+			if flagDir == "" {
+				handleError(portainer.ConfigError("--all requires --dir"))
+				return
+			}
+			if stacks, err = client.ListStacks(); err != nil {
+				handleError(err)
+				return
+			}
+		} else {
+			if flagStackFile == "" {
+				handleError(portainer.ConfigError("-f is required unless --all is given"))
+				return
+			}
+			id, err := parseID(args[0])
+			if err != nil {
+				handleError(err)
+				return
+			}
+			stack, err := findStack(client, id)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			stacks = []portainer.APIStack{*stack}
+		}
+
+		r, err := redactor()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var output portainer.StackDiffList
+		drifted := 0
+		for i := range stacks {
+			stack := &stacks[i]
+			composePath, envPath := flagStackFile, flagEnvFile
+			if flagAll {
+				composePath, envPath = findLocalStack(flagDir, stack.Name)
+				if composePath == "" {
+					output.Stacks = append(output.Stacks, portainer.StackDiff{
+						ID: stack.ID, Name: stack.Name, EndpointID: stack.EndpointID, Status: portainer.DiffNoFile,
+					})
+					continue
+				}
+			}
+
+			result, err := diffStack(client, stack, cmd.InOrStdin(), composePath, envPath, r)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			if result.Status == portainer.DiffDrift {
+				drifted++
+			}
+			output.Stacks = append(output.Stacks, result)
+		}
+
+		if cmd.Flags().Changed("output") {
+			err = portainer.Print(output)
+		} else {
+			err = printDiffText(output)
+		}
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if drifted > 0 {
+			handleError(portainer.DriftError(fmt.Sprintf("%d of %d stacks differ from local files", drifted, len(output.Stacks))))
+		}
+	},
+}
+
+func diffStack(client *portainer.Client, stack *portainer.APIStack, stdin io.Reader, composePath, envPath string, r *common.Redactor) (portainer.StackDiff, error) {
+	local, err := readStackFile(stdin, composePath)
+	if err != nil {
+		return portainer.StackDiff{}, err
+	}
+	env, err := readEnvFile(envPath)
+	if err != nil {
+		return portainer.StackDiff{}, err
+	}
+	if envPath != "" && env == nil {
+		env = []portainer.APIEnvVar{}
+	}
+	file, err := client.GetStackFile(stack.ID)
+	if err != nil {
+		return portainer.StackDiff{}, err
+	}
+	return portainer.DiffStack(stack, file.StackFileContent, local, composePath, env, envPath, r), nil
+}
+
+// findLocalStack looks for a stack's compose and env files in dir.
+func findLocalStack(dir, name string) (compose, env string) {
+	candidates := []string{
+		name + ".yml", name + ".yaml",
+		filepath.Join(name, "docker-compose.yml"), filepath.Join(name, "docker-compose.yaml"),
+		filepath.Join(name, "compose.yml"), filepath.Join(name, "compose.yaml"),
+	}
+	for _, c := range candidates {
+		if path := filepath.Join(dir, c); fileExists(path) {
+			compose = path
+			break
+		}
+	}
+	for _, c := range []string{name + ".env", filepath.Join(name, ".env")} {
+		if path := filepath.Join(dir, c); fileExists(path) {
+			env = path
+			break
+		}
+	}
+	return compose, env
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// printDiffText prints the diffs, plus a note on stderr for stacks without
+// a local file.
+func printDiffText(output portainer.StackDiffList) error {
+	for _, s := range output.Stacks {
+		switch s.Status {
+		case portainer.DiffDrift:
+			fmt.Print(s.Diff)
+		case portainer.DiffNoFile:
+			fmt.Fprintf(os.Stderr, "# stack %q (ID %d) has no local file\n", s.Name, s.ID)
+		}
+	}
+	return nil
+}
+
+// findStack looks a stack up by ID in the stack list, which also carries
+// the endpoint it runs on.
+func findStack(client *portainer.Client, id int64) (*portainer.APIStack, error) {
+	stacks, err := client.ListStacks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range stacks {
+		if stacks[i].ID == id {
+			return &stacks[i], nil
+		}
+	}
+	return nil, portainer.NotFoundError(fmt.Sprintf("stack with ID %d", id))
+}
+
+// readStackFile reads a compose file, or stdin when path is "-".
+func readStackFile(stdin io.Reader, path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", portainer.ConfigError(fmt.Sprintf("cannot read compose file: %s", err))
+	}
+	if len(data) == 0 {
+		return "", portainer.ConfigError(fmt.Sprintf("compose file %s is empty", path))
+	}
+	return string(data), nil
+}
+
+// printStack prints a stack with secret env values masked, unless
+// --show-secrets is given.
+func printStack(stack portainer.Stack) {
+	r, err := redactor()
+	if err != nil {
+		handleError(err)
+		return
+	}
+	stack.Env = portainer.RedactEnv(stack.Env, r)
+	if err := portainer.Print(stack); err != nil {
+		handleError(err)
+	}
+}
+
+// readEnvFile returns nil when no --env-file was given.
+func readEnvFile(path string) ([]portainer.APIEnvVar, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return portainer.ReadEnvFile(path)
+}
+
+func init() {
+	stacksDeployCmd.Flags().StringVarP(&flagStackFile, "file", "f", "", "Compose file to deploy (- for stdin)")
+	stacksDeployCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID to deploy to")
+	stacksDeployCmd.MarkFlagRequired("file")
+	stacksDeployCmd.MarkFlagRequired("endpoint")
+	stacksUpdateCmd.Flags().StringVarP(&flagStackFile, "file", "f", "", "New compose file (- for stdin; default: keep current)")
+	for _, cmd := range []*cobra.Command{stacksDeployCmd, stacksUpdateCmd} {
+		cmd.Flags().StringVar(&flagEnvFile, "env-file", "", "Env file with KEY=VALUE lines for the stack")
+		cmd.Flags().BoolVar(&flagPrune, "prune", false, "Remove services no longer in the compose file")
+		cmd.Flags().BoolVar(&flagPullImage, "pull-image", false, "Pull the latest images before redeploying")
+	}
+	stacksRedeployCmd.Flags().StringVar(&flagRef, "ref", "", "Git reference to deploy, e.g. refs/heads/main (default: the stack's current one)")
+	stacksRedeployCmd.Flags().BoolVar(&flagPrune, "prune", false, "Remove services no longer in the compose file")
+
+	for _, cmd := range []*cobra.Command{stacksShowCmd, stacksDeployCmd, stacksUpdateCmd, stacksRedeployCmd, stacksDiffCmd} {
+		cmd.Flags().BoolVar(&flagShowSecrets, "show-secrets", false, "Show env values that look secret instead of masking them")
+	}
+
+	stacksDiffCmd.Flags().StringVarP(&flagStackFile, "file", "f", "", "Local compose file to compare with")
+	stacksDiffCmd.Flags().StringVar(&flagEnvFile, "env-file", "", "Local env file to compare with the stack's env")
+	stacksDiffCmd.Flags().BoolVar(&flagAll, "all", false, "Compare every stack with files in --dir")
+	stacksDiffCmd.Flags().StringVar(&flagDir, "dir", "", "Directory of compose files named after stacks (with --all)")
+
+	stacksCmd.AddCommand(stacksListCmd)
+	stacksCmd.AddCommand(stacksShowCmd)
+	stacksCmd.AddCommand(stacksContainersCmd)
+	stacksCmd.AddCommand(stacksDeployCmd)
+	stacksCmd.AddCommand(stacksUpdateCmd)
+	stacksCmd.AddCommand(stacksStartCmd)
+	stacksCmd.AddCommand(stacksStopCmd)
+	stacksCmd.AddCommand(stacksRestartCmd)
+	stacksCmd.AddCommand(stacksDeleteCmd)
+	stacksCmd.AddCommand(stacksRedeployCmd)
+	stacksCmd.AddCommand(stacksDiffCmd)
+}
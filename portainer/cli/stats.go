@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+// statsWorkers bounds concurrent stats requests; each takes about a second
+const statsWorkers = 8
+
+var (
+	flagStack    string
+	flagWatch    bool
+	flagInterval time.Duration
+	flagSort     string
+)
+
+var containersStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show CPU, memory, network and block IO of running containers",
+	Long: `Show CPU, memory, network and block IO of running containers
+
+Containers on every endpoint are sampled concurrently and sorted by CPU
+(or --sort memory|name). With --watch the table is redrawn until Ctrl-C.`,
+	Example: `  portainer-cli containers stats -o table
+  portainer-cli containers stats --stack media --watch -o table`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch flagSort {
+		case "cpu", "memory", "name":
+		default:
+			handleError(portainer.ConfigError(fmt.Sprintf("invalid --sort %q (cpu|memory|name)", flagSort)))
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		ctx, stop := interruptContext()
+		defer stop()
+
+		redraw := flagWatch && term.IsTerminal(int(os.Stdout.Fd()))
+		for n := 0; ; n++ {
+			stats, err := collectStats(client)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			switch {
+			case redraw:
+				fmt.Print("\x1b[H\x1b[2J")
+			case n > 0:
+				fmt.Println()
+			}
+			if err := portainer.Print(stats); err != nil {
+				handleError(err)
+				return
+			}
+			if !flagWatch {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(flagInterval):
+			}
+		}
+	},
+}
+
+// collectStats samples every running container matching --endpoint and
+// --stack. Containers that stop while being sampled are left out.
+func collectStats(client *portainer.Client) (portainer.ContainerStatsList, error) {
+	ids, err := endpointIDs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	type target struct {
+		endpointID int64
+		container  portainer.APIContainer
+	}
+	var targets []target
+	for _, eid := range ids {
+		containers, err := client.ListContainers(eid)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			if c.State != "running" {
+				continue
+			}
+			if flagStack != "" && c.Labels["com.docker.compose.project"] != flagStack {
+				continue
+			}
+			targets = append(targets, target{eid, c})
+		}
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		first error
+		out   portainer.ContainerStatsList
+	)
+	sem := make(chan struct{}, statsWorkers)
+	for _, t := range targets {
+		wg.Add(1)
+		go func(t target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stats, err := client.ContainerStats(t.endpointID, t.container.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if e, ok := err.(*portainer.PortainerError); ok && e.Code == portainer.ErrNotFound {
+					return
+				}
+				if first == nil {
+					first = err
+				}
+				return
+			}
+			out = append(out, t.container.ToStats(t.endpointID, stats.Usage()))
+		}(t)
+	}
+	wg.Wait()
+	if first != nil {
+		return nil, first
+	}
+
+	out.Sort(flagSort)
+	return out, nil
+}
+
+func init() {
+	containersStatsCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all Docker endpoints)")
+	containersStatsCmd.Flags().StringVar(&flagStack, "stack", "", "Only containers of this stack (compose project name)")
+	containersStatsCmd.Flags().BoolVarP(&flagWatch, "watch", "w", false, "Refresh until interrupted")
+	containersStatsCmd.Flags().DurationVar(&flagInterval, "interval", 2*time.Second, "Refresh interval for --watch")
+	containersStatsCmd.Flags().StringVar(&flagSort, "sort", "cpu", "Sort by cpu, memory or name")
+
+	containersCmd.AddCommand(containersStatsCmd)
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var flagLeader bool
+
+var teamsCmd = &cobra.Command{
+	Use:   "teams",
+	Short: "Manage Portainer teams (administrators only)",
+}
+
+var teamsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List teams with their leaders and members",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, err := client.Directory()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(dir.ToTeams()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var teamsCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a team",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		team, err := client.CreateTeam(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir := portainer.Directory{}
+		if err := portainer.Print(dir.ToTeam(team)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var teamsAddMemberCmd = &cobra.Command{
+	Use:   "add-member <team> <user>",
+	Short: "Add a user to a team",
+	Long: `Add a user to a team
+
+Team and user are names or IDs. A user already in the team keeps their
+membership; use remove-member first to change it.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, team, user, err := resolveMembership(client, args[0], args[1])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if dir.Membership(user.ID, team.ID) == nil {
+			role := portainer.TeamMember
+			if flagLeader {
+				role = portainer.TeamLeader
+			}
+			membership, err := client.CreateTeamMembership(user.ID, team.ID, role)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			dir.Memberships = append(dir.Memberships, *membership)
+		}
+
+		if err := portainer.Print(dir.ToTeam(team)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var teamsRemoveMemberCmd = &cobra.Command{
+	Use:   "remove-member <team> <user>",
+	Short: "Remove a user from a team",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, team, user, err := resolveMembership(client, args[0], args[1])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		membership := dir.Membership(user.ID, team.ID)
+		if membership == nil {
+			handleError(portainer.NotFoundError(fmt.Sprintf("%q is not a member of team %q", user.Username, team.Name)))
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Remove %q from team %q?", user.Username, team.Name)
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		id := membership.ID
+		if err := client.DeleteTeamMembership(id); err != nil {
+			handleError(err)
+			return
+		}
+		dir.Memberships = slices.DeleteFunc(dir.Memberships, func(m portainer.APITeamMembership) bool {
+			return m.ID == id
+		})
+
+		if err := portainer.Print(dir.ToTeam(team)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+// resolveMembership looks up a team and a user by name or ID.
+func resolveMembership(client *portainer.Client, teamRef, userRef string) (*portainer.Directory, *portainer.APITeam, *portainer.APIUser, error) {
+	dir, err := client.Directory()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	team, err := dir.FindTeam(teamRef)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	user, err := dir.FindUser(userRef)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return dir, team, user, nil
+}
+
+func init() {
+	teamsAddMemberCmd.Flags().BoolVar(&flagLeader, "leader", false, "Make the user a team leader")
+	teamsRemoveMemberCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	teamsCmd.AddCommand(teamsListCmd)
+	teamsCmd.AddCommand(teamsCreateCmd)
+	teamsCmd.AddCommand(teamsAddMemberCmd)
+	teamsCmd.AddCommand(teamsRemoveMemberCmd)
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var (
+	flagCustom    bool
+	flagStackName string
+	flagEnv       []string
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List and deploy app and custom templates",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List app templates and custom templates",
+	Long: `List app templates and custom templates
+
+App templates come from the template URL in Portainer's settings; custom
+templates are the ones saved in Portainer. IDs are per source, so deploy
+custom templates with --custom.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		templates, err := client.ListTemplates()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		custom, err := client.ListCustomTemplates()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var out []portainer.TemplateListItem
+		for i := range templates {
+			out = append(out, templates[i].ToListItem())
+		}
+		for i := range custom {
+			out = append(out, custom[i].ToListItem())
+		}
+		sort.SliceStable(out, func(i, j int) bool {
+			if out[i].Source != out[j].Source {
+				return out[i].Source == portainer.SourceApp
+			}
+			return out[i].ID < out[j].ID
+		})
+		if err := portainer.Print(out); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var templatesDeployCmd = &cobra.Command{
+	Use:   "deploy <id>",
+	Short: "Deploy a template as a new stack",
+	Long: `Deploy a template as a new stack
+
+Container templates are deployed as a one-service compose stack, and
+compose templates from their git repository. Template variables are set
+with --env or --env-file; values that are not template variables are added
+to the stack env. Without --endpoint the only endpoint is used. The stack
+is named after the template unless --name is given.`,
+	Example: `  portainer-cli templates deploy 12 --env TZ=Europe/Berlin
+  portainer-cli templates deploy 3 --custom --name media --endpoint 2`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseID(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+		values, err := templateValues()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		endpointID, err := targetEndpoint(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		var stack *portainer.APIStack
+		if flagCustom {
+			stack, err = deployCustomTemplate(client, id, endpointID, values)
+		} else {
+			stack, err = deployAppTemplate(client, id, endpointID, values)
+		}
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		printStack(stack.ToStack(""))
+	},
+}
+
+func deployCustomTemplate(client *portainer.Client, id, endpointID int64, values map[string]string) (*portainer.APIStack, error) {
+	template, err := client.GetCustomTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if template.Type != portainer.CustomTemplateCompose {
+		return nil, portainer.ConfigError(fmt.Sprintf("%s templates are not supported; only compose", template.TypeLabel()))
+	}
+	content, err := client.GetCustomTemplateFile(id)
+	if err != nil {
+		return nil, err
+	}
+	content, env, err := portainer.RenderCustomTemplate(template, content, values)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateStack(endpointID, stackName(template.Title), content, env)
+}
+
+func deployAppTemplate(client *portainer.Client, id, endpointID int64, values map[string]string) (*portainer.APIStack, error) {
+	templates, err := client.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var template *portainer.APITemplate
+	for i := range templates {
+		if templates[i].ID == id {
+			template = &templates[i]
+		}
+	}
+	if template == nil {
+		return nil, portainer.NotFoundError(fmt.Sprintf("app template %d", id))
+	}
+
+	env, err := portainer.ResolveTemplateEnv(template.Env, values)
+	if err != nil {
+		return nil, err
+	}
+	name := stackName(template.Title)
+
+	switch {
+	case template.Type == portainer.TemplateContainer:
+		content, err := template.ComposeFile(name, env)
+		if err != nil {
+			return nil, err
+		}
+		return client.CreateStack(endpointID, name, content, env)
+	case template.Type == portainer.TemplateComposeStack && template.Repository.URL != "":
+		return client.CreateGitStack(endpointID, name, template.Repository.URL, template.Repository.StackFile, env)
+	default:
+		return nil, portainer.ConfigError(fmt.Sprintf("%s templates are not supported; only container and compose", template.TypeLabel()))
+	}
+}
+
+// stackName is --name, or the template title made into a stack name.
+func stackName(title string) string {
+	if flagStackName != "" {
+		return flagStackName
+	}
+	return portainer.StackName(title)
+}
+
+// templateValues merges --env-file and --env, with --env taking precedence.
+func templateValues() (map[string]string, error) {
+	env, err := readEnvFile(flagEnvFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range flagEnv {
+		parsed, err := portainer.ParseEnv(strings.NewReader(e), "--env")
+		if err != nil || len(parsed) != 1 {
+			return nil, portainer.ConfigError(fmt.Sprintf("invalid --env %q: expected KEY=VALUE", e))
+		}
+		env = append(env, parsed[0])
+	}
+	values := map[string]string{}
+	for _, e := range env {
+		values[e.Name] = e.Value
+	}
+	return values, nil
+}
+
+func init() {
+	templatesDeployCmd.Flags().BoolVar(&flagCustom, "custom", false, "Deploy a custom template instead of an app template")
+	templatesDeployCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID to deploy to (default: the only endpoint)")
+	templatesDeployCmd.Flags().StringVar(&flagStackName, "name", "", "Stack name (default: from the template title)")
+	templatesDeployCmd.Flags().StringArrayVarP(&flagEnv, "env", "e", nil, "Template variable or stack env as KEY=VALUE (repeatable)")
+	templatesDeployCmd.Flags().StringVar(&flagEnvFile, "env-file", "", "Env file with KEY=VALUE lines")
+	templatesDeployCmd.Flags().BoolVar(&flagShowSecrets, "show-secrets", false, "Show env values that look secret instead of masking them")
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesDeployCmd)
+}
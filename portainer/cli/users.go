@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var flagRole string
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage Portainer users (administrators only)",
+}
+
+var usersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users with their role and teams",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, err := client.Directory()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(dir.ToUsers()); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var usersCreateCmd = &cobra.Command{
+	Use:   "create <username>",
+	Short: "Create a user",
+	Long: `Create a user
+
+The password is prompted for on a terminal, or read from the first line of
+stdin so it can be piped in.`,
+	Example: `  portainer-cli users create alice
+  pass show portainer/alice | portainer-cli users create alice --role admin`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		role, err := portainer.ParseRole(flagRole)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		password, err := common.ReadSecret(cmd.InOrStdin(), "password")
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		user, err := client.CreateUser(args[0], password, role)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir := portainer.Directory{}
+		if err := portainer.Print(dir.ToUser(user)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var usersDeleteCmd = &cobra.Command{
+	Use:   "delete <username-or-id>",
+	Short: "Delete a user",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, err := client.Directory()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		user, err := dir.FindUser(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Delete %s %q?", portainer.RoleLabel(user.Role), user.Username)
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		if err := client.DeleteUser(user.ID); err != nil {
+			handleError(err)
+			return
+		}
+
+		result := portainer.UserActionResult{ID: user.ID, Username: user.Username, Action: "deleted"}
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var usersSetRoleCmd = &cobra.Command{
+	Use:   "set-role <username-or-id> <admin|user>",
+	Short: "Make a user an administrator or a standard user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		role, err := portainer.ParseRole(args[1])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		dir, err := client.Directory()
+		if err != nil {
+			handleError(err)
+			return
+		}
+		user, err := dir.FindUser(args[0])
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if user.Role != role {
+			if _, err := client.SetUserRole(user.ID, role); err != nil {
+				handleError(err)
+				return
+			}
+			user.Role = role
+		}
+
+		if err := portainer.Print(dir.ToUser(user)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	usersCreateCmd.Flags().StringVar(&flagRole, "role", "user", "Role: admin or user")
+	usersDeleteCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	usersCmd.AddCommand(usersListCmd)
+	usersCmd.AddCommand(usersCreateCmd)
+	usersCmd.AddCommand(usersDeleteCmd)
+	usersCmd.AddCommand(usersSetRoleCmd)
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/portainer/pkg/portainer"
+)
+
+var volumesCmd = &cobra.Command{
+	Use:   "volumes",
+	Short: "Manage volumes on endpoints",
+}
+
+var volumesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List volumes with their size and the containers using them",
+	Long: `List volumes with their size and the containers using them
+
+The totals include the space reclaimable by removing volumes no container
+mounts. Volumes on drivers that can't report a size show "-".`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		usage, err := diskUsage(client)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(portainer.NewVolumeList(usage, flagDangling)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var volumesRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a volume from an endpoint",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			question := fmt.Sprintf("Remove volume %q and its data on endpoint %d?", args[0], flagEndpoint)
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		if err := client.RemoveVolume(flagEndpoint, args[0], flagForce); err != nil {
+			handleError(err)
+			return
+		}
+
+		result := portainer.VolumeActionResult{Name: args[0], Endpoint: flagEndpoint, Action: "removed"}
+		if err := portainer.Print(result); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+var volumesPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused anonymous volumes from an endpoint",
+	Long: `Remove unused anonymous volumes from an endpoint
+
+With --all, unused named volumes are removed too. The prompt shows how much
+space that is expected to free.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if !flagYes {
+			du, err := client.DiskUsage(flagEndpoint)
+			if err != nil {
+				handleError(err)
+				return
+			}
+			list := portainer.NewVolumeList(map[int64]*portainer.APIDiskUsage{flagEndpoint: du}, true)
+			if list.Count == 0 {
+				report := portainer.APIPruneReport{}
+				if err := portainer.Print(report.ToResult(flagEndpoint)); err != nil {
+					handleError(err)
+				}
+				return
+			}
+
+			// Without --all Docker keeps named volumes, which the estimate
+			// can't tell apart; say so rather than overstate
+			question := fmt.Sprintf("Remove unused volumes on endpoint %d, reclaiming up to %s?", flagEndpoint, list.Reclaimable)
+			if flagAll {
+				question = fmt.Sprintf("Remove %d unused volumes and their data on endpoint %d, reclaiming about %s?", list.Count, flagEndpoint, list.Reclaimable)
+			}
+			if err := common.Confirm(cmd.InOrStdin(), os.Stderr, question); err != nil {
+				handleError(err)
+				return
+			}
+		}
+
+		report, err := client.PruneVolumes(flagEndpoint, flagAll)
+		if err != nil {
+			handleError(err)
+			return
+		}
+
+		if err := portainer.Print(report.ToResult(flagEndpoint)); err != nil {
+			handleError(err)
+		}
+	},
+}
+
+func init() {
+	volumesListCmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID (default: all endpoints)")
+	volumesListCmd.Flags().BoolVar(&flagDangling, "dangling", false, "Only volumes no container uses")
+	for _, cmd := range []*cobra.Command{volumesRmCmd, volumesPruneCmd} {
+		cmd.Flags().Int64Var(&flagEndpoint, "endpoint", 0, "Endpoint ID")
+		cmd.MarkFlagRequired("endpoint")
+		cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	}
+	volumesRmCmd.Flags().BoolVarP(&flagForce, "force", "f", false, "Ignore a volume that doesn't exist")
+	volumesPruneCmd.Flags().BoolVarP(&flagAll, "all", "a", false, "Also remove unused named volumes")
+
+	volumesCmd.AddCommand(volumesListCmd)
+	volumesCmd.AddCommand(volumesRmCmd)
+	volumesCmd.AddCommand(volumesPruneCmd)
+}
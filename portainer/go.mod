@@ -3,11 +3,16 @@ module github.com/schmoli/cli-tools/portainer
 go 1.21
 
 require (
+	github.com/schmoli/cli-tools/common v0.0.0
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/term v0.15.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 )
+
+replace github.com/schmoli/cli-tools/common => ../common
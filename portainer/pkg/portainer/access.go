@@ -0,0 +1,443 @@
+package portainer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Portainer user roles
+const (
+	RoleAdmin    = 1
+	RoleStandard = 2
+)
+
+// Team membership roles
+const (
+	TeamLeader = 1
+	TeamMember = 2
+)
+
+// Audit flags
+const (
+	FlagAdmin  = "admin"
+	FlagNoTeam = "no-team"
+)
+
+type APIUser struct {
+	ID       int64  `json:"Id"`
+	Username string `json:"Username"`
+	Role     int    `json:"Role"`
+}
+
+type APITeam struct {
+	ID   int64  `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type APITeamMembership struct {
+	ID     int64 `json:"Id"`
+	UserID int64 `json:"UserID"`
+	TeamID int64 `json:"TeamID"`
+	Role   int   `json:"Role"`
+}
+
+// APIEndpointGroup grants its access policies to every endpoint in it
+type APIEndpointGroup struct {
+	ID                 int64                     `json:"Id"`
+	Name               string                    `json:"Name"`
+	UserAccessPolicies map[int64]APIAccessPolicy `json:"UserAccessPolicies"`
+	TeamAccessPolicies map[int64]APIAccessPolicy `json:"TeamAccessPolicies"`
+}
+
+// APIAccessPolicy is keyed by user or team ID. RoleId is only set in
+// Business Edition.
+type APIAccessPolicy struct {
+	RoleID int64 `json:"RoleId"`
+}
+
+// APIResourceControl decides who besides administrators can see and
+// manage a stack, container, volume or other resource.
+type APIResourceControl struct {
+	ID                 int64 `json:"Id"`
+	Public             bool  `json:"Public"`
+	AdministratorsOnly bool  `json:"AdministratorsOnly"`
+	UserAccesses       []struct {
+		UserID int64 `json:"UserId"`
+	} `json:"UserAccesses"`
+	TeamAccesses []struct {
+		TeamID int64 `json:"TeamId"`
+	} `json:"TeamAccesses"`
+}
+
+// Request bodies for user and team administration
+type APIUserCreateRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     int    `json:"role"`
+}
+
+type APITeamMembershipRequest struct {
+	UserID int64 `json:"userID"`
+	TeamID int64 `json:"teamID"`
+	Role   int   `json:"role"`
+}
+
+// Output types
+
+type User struct {
+	ID       int64    `yaml:"id"`
+	Username string   `yaml:"username"`
+	Role     string   `yaml:"role"`
+	Teams    []string `yaml:"teams"`
+}
+
+type Team struct {
+	ID      int64    `yaml:"id"`
+	Name    string   `yaml:"name"`
+	Leaders []string `yaml:"leaders"`
+	Members []string `yaml:"members"`
+}
+
+type UserActionResult struct {
+	ID       int64  `yaml:"id"`
+	Username string `yaml:"username"`
+	Action   string `yaml:"action"`
+}
+
+// EndpointAccess lists who can use an endpoint besides administrators,
+// granted directly or through the endpoint's group.
+type EndpointAccess struct {
+	ID    int64    `yaml:"id"`
+	Name  string   `yaml:"name"`
+	Group string   `yaml:"group,omitempty"`
+	Users []string `yaml:"users"`
+	Teams []string `yaml:"teams"`
+}
+
+// Stack access levels
+const (
+	AccessPublic     = "public"
+	AccessAdmins     = "administrators"
+	AccessRestricted = "restricted"
+)
+
+type StackAccess struct {
+	ID       int64    `yaml:"id"`
+	Name     string   `yaml:"name"`
+	Endpoint int64    `yaml:"endpoint"`
+	Access   string   `yaml:"access"`
+	Users    []string `yaml:"users"`
+	Teams    []string `yaml:"teams"`
+}
+
+// AccessAudit is the quarterly access review: every user with their
+// teams and endpoints, flagged when they are an administrator or in no
+// team. Flagged counts users with at least one flag.
+type AccessAudit struct {
+	Users   int          `yaml:"users"`
+	Admins  int          `yaml:"admins"`
+	NoTeam  int          `yaml:"noTeam"`
+	Flagged int          `yaml:"flagged"`
+	Entries []AuditEntry `yaml:"entries"`
+}
+
+type AuditEntry struct {
+	Username  string   `yaml:"username"`
+	Role      string   `yaml:"role"`
+	Teams     []string `yaml:"teams"`
+	Endpoints []string `yaml:"endpoints"` // "*" for administrators
+	Flags     []string `yaml:"flags"`
+}
+
+func RoleLabel(role int) string {
+	switch role {
+	case RoleAdmin:
+		return "admin"
+	case RoleStandard:
+		return "user"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRole accepts the labels from RoleLabel.
+func ParseRole(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "admin", "administrator":
+		return RoleAdmin, nil
+	case "user", "standard":
+		return RoleStandard, nil
+	}
+	return 0, ConfigError(fmt.Sprintf("invalid role %q (admin|user)", s))
+}
+
+// Directory joins users, teams and memberships so IDs can be shown as
+// names.
+type Directory struct {
+	Users       []APIUser
+	Teams       []APITeam
+	Memberships []APITeamMembership
+}
+
+// FindUser looks a user up by username, ignoring case as Portainer does,
+// or by ID.
+func (d *Directory) FindUser(ref string) (*APIUser, error) {
+	id, _ := strconv.ParseInt(ref, 10, 64)
+	for i := range d.Users {
+		if strings.EqualFold(d.Users[i].Username, ref) {
+			return &d.Users[i], nil
+		}
+	}
+	for i := range d.Users {
+		if d.Users[i].ID == id {
+			return &d.Users[i], nil
+		}
+	}
+	return nil, NotFoundError(fmt.Sprintf("user %q", ref))
+}
+
+// FindTeam looks a team up by name or ID.
+func (d *Directory) FindTeam(ref string) (*APITeam, error) {
+	id, _ := strconv.ParseInt(ref, 10, 64)
+	for i := range d.Teams {
+		if strings.EqualFold(d.Teams[i].Name, ref) {
+			return &d.Teams[i], nil
+		}
+	}
+	for i := range d.Teams {
+		if d.Teams[i].ID == id {
+			return &d.Teams[i], nil
+		}
+	}
+	return nil, NotFoundError(fmt.Sprintf("team %q", ref))
+}
+
+// Membership finds the user's membership of the team, if any.
+func (d *Directory) Membership(userID, teamID int64) *APITeamMembership {
+	for i := range d.Memberships {
+		if m := &d.Memberships[i]; m.UserID == userID && m.TeamID == teamID {
+			return m
+		}
+	}
+	return nil
+}
+
+// username falls back to the ID for users deleted since a policy was set.
+func (d *Directory) username(id int64) string {
+	for _, u := range d.Users {
+		if u.ID == id {
+			return u.Username
+		}
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (d *Directory) teamName(id int64) string {
+	for _, t := range d.Teams {
+		if t.ID == id {
+			return t.Name
+		}
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (d *Directory) userTeams(userID int64) []int64 {
+	var ids []int64
+	for _, m := range d.Memberships {
+		if m.UserID == userID {
+			ids = append(ids, m.TeamID)
+		}
+	}
+	return ids
+}
+
+func (d *Directory) userNames(ids []int64) []string {
+	names := []string{}
+	for _, id := range ids {
+		names = append(names, d.username(id))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *Directory) teamNames(ids []int64) []string {
+	names := []string{}
+	for _, id := range ids {
+		names = append(names, d.teamName(id))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d *Directory) ToUser(u *APIUser) User {
+	return User{ID: u.ID, Username: u.Username, Role: RoleLabel(u.Role), Teams: d.teamNames(d.userTeams(u.ID))}
+}
+
+// ToUsers lists users by name.
+func (d *Directory) ToUsers() []User {
+	users := make([]User, len(d.Users))
+	for i := range d.Users {
+		users[i] = d.ToUser(&d.Users[i])
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users
+}
+
+func (d *Directory) ToTeam(t *APITeam) Team {
+	var leaders, members []int64
+	for _, m := range d.Memberships {
+		if m.TeamID != t.ID {
+			continue
+		}
+		if m.Role == TeamLeader {
+			leaders = append(leaders, m.UserID)
+		} else {
+			members = append(members, m.UserID)
+		}
+	}
+	return Team{ID: t.ID, Name: t.Name, Leaders: d.userNames(leaders), Members: d.userNames(members)}
+}
+
+// ToTeams lists teams by name.
+func (d *Directory) ToTeams() []Team {
+	teams := make([]Team, len(d.Teams))
+	for i := range d.Teams {
+		teams[i] = d.ToTeam(&d.Teams[i])
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams
+}
+
+// endpointGrants returns the users and teams an endpoint's own and its
+// group's policies grant access to.
+func endpointGrants(e *APIEndpoint, groups []APIEndpointGroup) (users, teams map[int64]bool) {
+	users, teams = map[int64]bool{}, map[int64]bool{}
+	for id := range e.UserAccessPolicies {
+		users[id] = true
+	}
+	for id := range e.TeamAccessPolicies {
+		teams[id] = true
+	}
+	for _, g := range groups {
+		if g.ID != e.GroupID {
+			continue
+		}
+		for id := range g.UserAccessPolicies {
+			users[id] = true
+		}
+		for id := range g.TeamAccessPolicies {
+			teams[id] = true
+		}
+	}
+	return users, teams
+}
+
+func grantIDs(m map[int64]bool) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// EndpointAccess shows who besides administrators can use each endpoint.
+func (d *Directory) EndpointAccess(endpoints []APIEndpoint, groups []APIEndpointGroup) []EndpointAccess {
+	out := []EndpointAccess{}
+	for i := range endpoints {
+		e := &endpoints[i]
+		users, teams := endpointGrants(e, groups)
+		access := EndpointAccess{ID: e.ID, Name: e.Name, Users: d.userNames(grantIDs(users)), Teams: d.teamNames(grantIDs(teams))}
+		for _, g := range groups {
+			if g.ID == e.GroupID {
+				access.Group = g.Name
+			}
+		}
+		out = append(out, access)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// StackAccess shows each stack's resource control. Stacks without one
+// are visible to administrators only.
+func (d *Directory) StackAccess(stacks []APIStack) []StackAccess {
+	out := []StackAccess{}
+	for _, s := range stacks {
+		access := StackAccess{ID: s.ID, Name: s.Name, Endpoint: s.EndpointID, Access: AccessAdmins, Users: []string{}, Teams: []string{}}
+		if rc := s.ResourceControl; rc != nil && !rc.AdministratorsOnly {
+			var users, teams []int64
+			for _, a := range rc.UserAccesses {
+				users = append(users, a.UserID)
+			}
+			for _, a := range rc.TeamAccesses {
+				teams = append(teams, a.TeamID)
+			}
+			access.Users, access.Teams = d.userNames(users), d.teamNames(teams)
+			switch {
+			case rc.Public:
+				access.Access = AccessPublic
+			case len(users)+len(teams) > 0:
+				access.Access = AccessRestricted
+			}
+		}
+		out = append(out, access)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Audit lists every user with the endpoints they can reach, directly or
+// through a team, and flags administrators and users in no team.
+func (d *Directory) Audit(endpoints []APIEndpoint, groups []APIEndpointGroup) *AccessAudit {
+	audit := &AccessAudit{Users: len(d.Users), Entries: []AuditEntry{}}
+	for i := range d.Users {
+		u := &d.Users[i]
+		teamIDs := d.userTeams(u.ID)
+		entry := AuditEntry{
+			Username:  u.Username,
+			Role:      RoleLabel(u.Role),
+			Teams:     d.teamNames(teamIDs),
+			Endpoints: []string{},
+			Flags:     []string{},
+		}
+
+		if u.Role == RoleAdmin {
+			entry.Endpoints = append(entry.Endpoints, "*")
+			entry.Flags = append(entry.Flags, FlagAdmin)
+			audit.Admins++
+		} else {
+			for j := range endpoints {
+				users, teams := endpointGrants(&endpoints[j], groups)
+				granted := users[u.ID]
+				for _, t := range teamIDs {
+					granted = granted || teams[t]
+				}
+				if granted {
+					entry.Endpoints = append(entry.Endpoints, endpoints[j].Name)
+				}
+			}
+			sort.Strings(entry.Endpoints)
+		}
+
+		if len(teamIDs) == 0 {
+			entry.Flags = append(entry.Flags, FlagNoTeam)
+			audit.NoTeam++
+		}
+		if len(entry.Flags) > 0 {
+			audit.Flagged++
+		}
+		audit.Entries = append(audit.Entries, entry)
+	}
+
+	// Flagged users first, for the reviewer
+	sort.Slice(audit.Entries, func(i, j int) bool {
+		a, b := audit.Entries[i], audit.Entries[j]
+		if len(a.Flags) != len(b.Flags) {
+			return len(a.Flags) > len(b.Flags)
+		}
+		return a.Username < b.Username
+	})
+	return audit
+}
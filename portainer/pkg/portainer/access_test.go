@@ -0,0 +1,115 @@
+package portainer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testDirectory() *Directory {
+	return &Directory{
+		Users: []APIUser{
+			{ID: 1, Username: "admin", Role: RoleAdmin},
+			{ID: 2, Username: "alice", Role: RoleStandard},
+			{ID: 3, Username: "bob", Role: RoleStandard},
+			{ID: 4, Username: "carol", Role: RoleStandard},
+		},
+		Teams: []APITeam{{ID: 10, Name: "ops"}, {ID: 11, Name: "media"}},
+		Memberships: []APITeamMembership{
+			{ID: 100, UserID: 2, TeamID: 10, Role: TeamLeader},
+			{ID: 101, UserID: 3, TeamID: 10, Role: TeamMember},
+			{ID: 102, UserID: 3, TeamID: 11, Role: TeamMember},
+		},
+	}
+}
+
+func TestDirectoryFind(t *testing.T) {
+	d := testDirectory()
+	if u, err := d.FindUser("Alice"); err != nil || u.ID != 2 {
+		t.Errorf("FindUser(Alice) = %v, %v", u, err)
+	}
+	if u, err := d.FindUser("3"); err != nil || u.Username != "bob" {
+		t.Errorf("FindUser(3) = %v, %v", u, err)
+	}
+	if _, err := d.FindTeam("dev"); err == nil {
+		t.Error("FindTeam(dev) should fail")
+	}
+	if d.Membership(3, 11) == nil || d.Membership(2, 11) != nil {
+		t.Error("Membership lookup wrong")
+	}
+}
+
+func TestDirectoryToTeams(t *testing.T) {
+	teams := testDirectory().ToTeams()
+	if teams[0].Name != "media" || !reflect.DeepEqual(teams[1].Leaders, []string{"alice"}) || !reflect.DeepEqual(teams[1].Members, []string{"bob"}) {
+		t.Errorf("teams = %+v", teams)
+	}
+}
+
+func TestEndpointPoliciesDecode(t *testing.T) {
+	var e APIEndpoint
+	data := `{"Id":1,"Name":"local","GroupId":2,"UserAccessPolicies":{"4":{"RoleId":0}},"TeamAccessPolicies":{}}`
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := e.UserAccessPolicies[4]; !ok || e.GroupID != 2 {
+		t.Errorf("endpoint = %+v", e)
+	}
+}
+
+func TestDirectoryAudit(t *testing.T) {
+	d := testDirectory()
+	endpoints := []APIEndpoint{
+		{ID: 1, Name: "local", UserAccessPolicies: map[int64]APIAccessPolicy{4: {}}},
+		{ID: 2, Name: "nas", GroupID: 5},
+	}
+	groups := []APIEndpointGroup{{ID: 5, Name: "storage", TeamAccessPolicies: map[int64]APIAccessPolicy{11: {}}}}
+
+	audit := d.Audit(endpoints, groups)
+	if audit.Users != 4 || audit.Admins != 1 || audit.NoTeam != 2 || audit.Flagged != 2 {
+		t.Errorf("counts = %+v", audit)
+	}
+	byName := map[string]AuditEntry{}
+	for _, e := range audit.Entries {
+		byName[e.Username] = e
+	}
+	if !reflect.DeepEqual(byName["admin"].Flags, []string{FlagAdmin, FlagNoTeam}) || byName["admin"].Endpoints[0] != "*" {
+		t.Errorf("admin = %+v", byName["admin"])
+	}
+	if !reflect.DeepEqual(byName["carol"].Flags, []string{FlagNoTeam}) || !reflect.DeepEqual(byName["carol"].Endpoints, []string{"local"}) {
+		t.Errorf("carol = %+v", byName["carol"])
+	}
+	if !reflect.DeepEqual(byName["bob"].Endpoints, []string{"nas"}) || len(byName["bob"].Flags) != 0 {
+		t.Errorf("bob = %+v", byName["bob"])
+	}
+	if audit.Entries[0].Username != "admin" {
+		t.Errorf("flagged users should come first, got %q", audit.Entries[0].Username)
+	}
+
+	access := d.EndpointAccess(endpoints, groups)
+	if !reflect.DeepEqual(access[1].Teams, []string{"media"}) || access[1].Group != "storage" {
+		t.Errorf("nas access = %+v", access[1])
+	}
+}
+
+func TestDirectoryStackAccess(t *testing.T) {
+	rc := &APIResourceControl{}
+	rc.TeamAccesses = append(rc.TeamAccesses, struct {
+		TeamID int64 `json:"TeamId"`
+	}{10})
+	stacks := []APIStack{
+		{ID: 1, Name: "web", ResourceControl: &APIResourceControl{Public: true}},
+		{ID: 2, Name: "db", ResourceControl: rc},
+		{ID: 3, Name: "infra"},
+	}
+	access := testDirectory().StackAccess(stacks)
+	want := []string{AccessPublic, AccessRestricted, AccessAdmins}
+	for i, a := range access {
+		if a.Access != want[i] {
+			t.Errorf("%s access = %q, want %q", a.Name, a.Access, want[i])
+		}
+	}
+	if !reflect.DeepEqual(access[1].Teams, []string{"ops"}) {
+		t.Errorf("db teams = %v", access[1].Teams)
+	}
+}
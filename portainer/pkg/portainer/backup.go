@@ -0,0 +1,202 @@
+package portainer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/schmoli/cli-tools/common"
+	"gopkg.in/yaml.v3"
+)
+
+// Files in a stack export. The layout matches what "stacks diff --all"
+// looks for, so an endpoint's directory can be compared with it.
+const (
+	ManifestFile  = "manifest.yaml"
+	ExportCompose = "docker-compose.yml"
+	ExportEnv     = ".env"
+)
+
+type APIBackupRequest struct {
+	Password string `json:"password"`
+}
+
+// Output types
+
+type BackupResult struct {
+	File      string `yaml:"file"`
+	Size      string `yaml:"size"`
+	Encrypted bool   `yaml:"encrypted"`
+	Action    string `yaml:"action"`
+}
+
+// ExportManifest lists the stacks in an export and where their files are,
+// relative to the export directory. Redacted names env vars whose values
+// were masked and must be filled in before the files are deployed.
+type ExportManifest struct {
+	Portainer string        `yaml:"portainer,omitempty"`
+	Stacks    []StackExport `yaml:"stacks"`
+}
+
+type StackExport struct {
+	ID         int64     `yaml:"id"`
+	Name       string    `yaml:"name"`
+	EndpointID int64     `yaml:"endpointId"`
+	Endpoint   string    `yaml:"endpoint"`
+	Type       string    `yaml:"type"`
+	Status     string    `yaml:"status"`
+	Path       string    `yaml:"path"`
+	Env        bool      `yaml:"env"`
+	Git        *StackGit `yaml:"git,omitempty"`
+	Redacted   []string  `yaml:"redacted,omitempty"`
+}
+
+type ExportResult struct {
+	Dir     string        `yaml:"dir"`
+	Stacks  []StackExport `yaml:"stacks"`
+	Removed []string      `yaml:"removed,omitempty"`
+}
+
+// ExportDir is the directory for an endpoint's stacks, named after it.
+func ExportDir(endpointID int64, endpoint string) string {
+	if name := StackName(endpoint); name != "" {
+		return name
+	}
+	return fmt.Sprintf("endpoint-%d", endpointID)
+}
+
+// ExportStack writes a stack's compose file and env into dir/path,
+// masking secret env values with r.
+func ExportStack(dir string, stack *APIStack, endpoint, content string, r *common.Redactor) (StackExport, error) {
+	export := StackExport{
+		ID:         stack.ID,
+		Name:       stack.Name,
+		EndpointID: stack.EndpointID,
+		Endpoint:   endpoint,
+		Type:       stack.TypeLabel(),
+		Status:     stack.StatusLabel(),
+		Path:       filepath.ToSlash(filepath.Join(ExportDir(stack.EndpointID, endpoint), stack.Name)),
+		Git:        stack.git(),
+	}
+	stackDir := filepath.Join(dir, filepath.FromSlash(export.Path))
+	if err := os.MkdirAll(stackDir, 0o755); err != nil {
+		return export, ConfigError(fmt.Sprintf("cannot create %s: %s", stackDir, err))
+	}
+	if err := writeExportFile(filepath.Join(stackDir, ExportCompose), content, 0o644); err != nil {
+		return export, err
+	}
+
+	envPath := filepath.Join(stackDir, ExportEnv)
+	if len(stack.Env) == 0 {
+		return export, removeExportFile(envPath)
+	}
+	env := RedactEnv(stack.Env, r)
+	for i := range env {
+		if env[i].Value != stack.Env[i].Value {
+			export.Redacted = append(export.Redacted, env[i].Name)
+		}
+	}
+	export.Env = true
+	// Env files may hold secrets when they are not masked
+	return export, writeExportFile(envPath, EnvLines(env)+"\n", 0o600)
+}
+
+func writeExportFile(path, content string, mode fs.FileMode) error {
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return ConfigError(fmt.Sprintf("cannot write %s: %s", path, err))
+	}
+	return nil
+}
+
+func removeExportFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ConfigError(fmt.Sprintf("cannot remove %s: %s", path, err))
+	}
+	return nil
+}
+
+// ReadManifest reads a previous export's manifest; a missing one is empty.
+func ReadManifest(dir string) (*ExportManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &ExportManifest{}, nil
+	}
+	if err != nil {
+		return nil, ConfigError(fmt.Sprintf("cannot read manifest: %s", err))
+	}
+	var m ExportManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, ConfigError(fmt.Sprintf("invalid %s: %s", ManifestFile, err))
+	}
+	return &m, nil
+}
+
+// WriteManifest writes the manifest sorted by path, so re-exporting an
+// unchanged Portainer leaves the directory unchanged.
+func WriteManifest(dir string, m *ExportManifest) error {
+	sort.Slice(m.Stacks, func(i, j int) bool { return m.Stacks[i].Path < m.Stacks[j].Path })
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return APIError(fmt.Sprintf("failed to serialize manifest: %s", err))
+	}
+	return writeExportFile(filepath.Join(dir, ManifestFile), string(data), 0o644)
+}
+
+// PruneExport removes the files of stacks in the previous manifest that
+// are gone now, and their directories once empty. Only files the export
+// writes are touched. It returns the removed stack paths.
+func PruneExport(dir string, previous, current *ExportManifest) ([]string, error) {
+	keep := map[string]bool{}
+	for _, s := range current.Stacks {
+		keep[s.Path] = true
+	}
+	var removed []string
+	for _, s := range previous.Stacks {
+		if keep[s.Path] || strings.Contains(s.Path, "..") || filepath.IsAbs(s.Path) {
+			continue
+		}
+		stackDir := filepath.Join(dir, filepath.FromSlash(s.Path))
+		for _, name := range []string{ExportCompose, ExportEnv} {
+			if err := removeExportFile(filepath.Join(stackDir, name)); err != nil {
+				return removed, err
+			}
+		}
+		// Removing a directory fails while anything else is in it
+		os.Remove(stackDir)
+		os.Remove(filepath.Dir(stackDir))
+		removed = append(removed, s.Path)
+	}
+	return removed, nil
+}
+
+// SaveBackup copies a backup archive to path through a temporary file, so
+// a failed download never leaves a truncated archive behind. The archive
+// holds credentials, so only the owner can read it.
+func SaveBackup(r io.Reader, path string) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, ConfigError(fmt.Sprintf("cannot create %s: %s", path, err))
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, NetworkError(fmt.Sprintf("backup download failed: %s", err))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return n, ConfigError(fmt.Sprintf("cannot write %s: %s", path, err))
+	}
+	return n, nil
+}
+
+func NewBackupResult(file string, size int64, encrypted bool, action string) BackupResult {
+	return BackupResult{File: file, Size: formatBytes(uint64(size)), Encrypted: encrypted, Action: action}
+}
@@ -0,0 +1,193 @@
+package portainer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+func TestExportStack(t *testing.T) {
+	dir := t.TempDir()
+	stack := &APIStack{ID: 4, Name: "media", Type: 2, Status: 1, EndpointID: 2, Env: []APIEnvVar{
+		{Name: "TZ", Value: "UTC"},
+		{Name: "DB_PASSWORD", Value: "hunter2"},
+	}}
+
+	export, err := ExportStack(dir, stack, "NAS Box", "services: {}\n", common.NewRedactor(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.Path != "nas-box/media" || !export.Env || !reflect.DeepEqual(export.Redacted, []string{"DB_PASSWORD"}) {
+		t.Errorf("export = %+v", export)
+	}
+	env, _ := os.ReadFile(filepath.Join(dir, "nas-box", "media", ExportEnv))
+	if string(env) != "DB_PASSWORD=[REDACTED]\nTZ=UTC\n" {
+		t.Errorf(".env = %q", env)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "nas-box", "media", ExportEnv)); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf(".env mode = %v, %v", info.Mode(), err)
+	}
+
+	// Env removed in Portainer since the last export
+	stack.Env = nil
+	if _, err := ExportStack(dir, stack, "NAS Box", "services: {}\n", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nas-box", "media", ExportEnv)); !os.IsNotExist(err) {
+		t.Errorf(".env should be removed, stat err = %v", err)
+	}
+}
+
+func TestExportThenDiff(t *testing.T) {
+	dir := t.TempDir()
+	compose := "services:\n  db:\n    image: postgres\n"
+	stack := &APIStack{ID: 4, Name: "db", EndpointID: 2, Env: []APIEnvVar{
+		{Name: "POSTGRES_PASSWORD", Value: "hunter2"},
+		{Name: "DSN", Value: "postgres://app:pw@db/app"},
+		{Name: "TZ", Value: "UTC"},
+	}}
+	r := common.NewRedactor(nil)
+
+	export, err := ExportStack(dir, stack, "local", compose, r)
+	if err != nil {
+		t.Fatalf("ExportStack: %v", err)
+	}
+	composePath := filepath.Join(dir, export.Path, ExportCompose)
+	envPath := filepath.Join(dir, export.Path, ExportEnv)
+	local, _ := os.ReadFile(composePath)
+	env, err := ReadEnvFile(envPath)
+	if err != nil {
+		t.Fatalf("ReadEnvFile: %v", err)
+	}
+
+	same := DiffStack(stack, compose, string(local), composePath, env, envPath, r)
+	if same.Status != DiffInSync {
+		t.Errorf("masked values should match the deployed ones:\n%s", same.Diff)
+	}
+
+	// A secret changed in Portainer after the export is not caught, but
+	// anything else is, without showing the secrets
+	stack.Env[0].Value = "swordfish"
+	stack.Env[2].Value = "Europe/Paris"
+	drift := DiffStack(stack, compose, string(local), composePath, env, envPath, r)
+	if drift.Status != DiffDrift || !strings.Contains(drift.Diff, "-TZ=Europe/Paris") {
+		t.Errorf("expected TZ drift:\n%s", drift.Diff)
+	}
+	for _, secret := range []string{"hunter2", "swordfish", ":pw@"} {
+		if strings.Contains(drift.Diff, secret) {
+			t.Errorf("diff leaks %q:\n%s", secret, drift.Diff)
+		}
+	}
+}
+
+func TestManifestAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	previous := &ExportManifest{Stacks: []StackExport{{Name: "old", Path: "local/old"}, {Name: "web", Path: "local/web"}}}
+	for _, s := range previous.Stacks {
+		ExportStack(dir, &APIStack{Name: s.Name, EndpointID: 1}, "local", "services: {}\n", nil)
+	}
+	if err := WriteManifest(dir, previous); err != nil {
+		t.Fatalf("WriteManifest: %v", err)
+	}
+	read, err := ReadManifest(dir)
+	if err != nil || len(read.Stacks) != 2 {
+		t.Fatalf("ReadManifest = %+v, %v", read, err)
+	}
+
+	current := &ExportManifest{Stacks: []StackExport{{Name: "web", Path: "local/web"}}}
+	removed, err := PruneExport(dir, read, current)
+	if err != nil || !reflect.DeepEqual(removed, []string{"local/old"}) {
+		t.Errorf("removed = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "local", "old")); !os.IsNotExist(err) {
+		t.Errorf("old stack directory should be gone, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "local", "web", ExportCompose)); err != nil {
+		t.Errorf("kept stack missing: %v", err)
+	}
+
+	if m, err := ReadManifest(t.TempDir()); err != nil || len(m.Stacks) != 0 {
+		t.Errorf("missing manifest = %+v, %v", m, err)
+	}
+}
+
+func TestSaveBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	n, err := SaveBackup(strings.NewReader("archive"), path)
+	if err != nil || n != 7 {
+		t.Fatalf("SaveBackup = %d, %v", n, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("archive mode = %v, %v", info, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/backup":
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"password":"pw"}` {
+				t.Errorf("backup body = %s", body)
+			}
+			w.Write([]byte("archive"))
+		case "/api/restore":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("restore form: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if header.Filename != "backup.tar.gz" || string(data) != "archive" || r.FormValue("password") != "pw" {
+				t.Errorf("restore got %s %q password %q", header.Filename, data, r.FormValue("password"))
+			}
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	body, err := client.Backup(context.Background(), "pw")
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "archive" {
+		t.Errorf("archive = %q", data)
+	}
+
+	if err := client.Restore(context.Background(), strings.NewReader("archive"), "backup.tar.gz", "pw"); err != nil {
+		t.Errorf("Restore: %v", err)
+	}
+}
+
+func TestRestoreArchiveReadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	archive := io.MultiReader(strings.NewReader("part"), errReader{errors.New("disk gone")})
+	err := client.Restore(context.Background(), archive, "backup.tar.gz", "")
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrConfig || !strings.Contains(e.Message, "disk gone") {
+		t.Errorf("err = %v, want a config error naming the read failure", err)
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
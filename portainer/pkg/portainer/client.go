@@ -1,65 +1,38 @@
 package portainer
 
 import (
-	"crypto/tls"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"strings"
+	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
+// deployTimeout allows for image pulls while a stack is created or updated
+const deployTimeout = 5 * time.Minute
+
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	api    *common.Client
+	deploy *common.Client // api with deployTimeout, for stack create/update
 }
 
 func NewClient(url, token string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
+	auth := common.APIKeyAuth{Header: "X-API-Key", Key: token}
 	return &Client{
-		baseURL:    strings.TrimSuffix(url, "/"),
-		token:      token,
-		httpClient: client,
+		api:    common.NewClient(url, auth, common.Options{Timeout: 10 * time.Second, Insecure: insecure, ParseError: parseError}),
+		deploy: common.NewClient(url, auth, common.Options{Timeout: deployTimeout, Insecure: insecure, ParseError: parseError}),
 	}
 }
 
 func (c *Client) get(path string, result interface{}) error {
-	url := c.baseURL + path
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("X-API-Key", c.token)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response from %s: %s", path, err))
-		}
-		return nil
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return AuthError(fmt.Sprintf("invalid or expired token for %s", path))
-	case http.StatusNotFound:
-		return NotFoundError(fmt.Sprintf("resource not found: %s", path))
-	default:
-		return APIError(fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, path))
-	}
+	return c.api.Get(path, result)
 }
 
 func (c *Client) ListStacks() ([]APIStack, error) {
@@ -88,6 +61,115 @@ func (c *Client) GetStackFile(id int64) (*APIStackFile, error) {
 	return &file, nil
 }
 
+// CreateStack deploys a new compose stack on an endpoint from the content
+// of a compose file.
+func (c *Client) CreateStack(endpointID int64, name, content string, env []APIEnvVar) (*APIStack, error) {
+	if env == nil {
+		env = []APIEnvVar{}
+	}
+	req := APIStackCreateRequest{
+		Name:             name,
+		StackFileContent: content,
+		Env:              env,
+	}
+	var stack APIStack
+	path := fmt.Sprintf("/api/stacks?type=2&method=string&endpointId=%d", endpointID)
+	if err := c.deploy.Post(path, req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// CreateGitStack deploys a new compose stack from a file in a git
+// repository.
+func (c *Client) CreateGitStack(endpointID int64, name, repoURL, composeFile string, env []APIEnvVar) (*APIStack, error) {
+	if env == nil {
+		env = []APIEnvVar{}
+	}
+	req := APIStackGitCreateRequest{
+		Name:                        name,
+		RepositoryURL:               repoURL,
+		ComposeFilePathInRepository: composeFile,
+		Env:                         env,
+	}
+	var stack APIStack
+	path := fmt.Sprintf("/api/stacks?type=2&method=repository&endpointId=%d", endpointID)
+	if err := c.deploy.Post(path, req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// UpdateStack replaces a stack's compose file and env and redeploys it.
+func (c *Client) UpdateStack(id, endpointID int64, content string, env []APIEnvVar, prune, pullImage bool) (*APIStack, error) {
+	if env == nil {
+		env = []APIEnvVar{}
+	}
+	req := APIStackUpdateRequest{
+		StackFileContent: content,
+		Env:              env,
+		Prune:            prune,
+		PullImage:        pullImage,
+	}
+	var stack APIStack
+	path := fmt.Sprintf("/api/stacks/%d?endpointId=%d", id, endpointID)
+	if err := c.deploy.Put(path, req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// StartStack brings a stopped stack's containers up.
+func (c *Client) StartStack(id, endpointID int64) error {
+	return c.deploy.Post(fmt.Sprintf("/api/stacks/%d/start?endpointId=%d", id, endpointID), nil, nil)
+}
+
+// StopStack takes a stack's containers down without deleting the stack.
+func (c *Client) StopStack(id, endpointID int64) error {
+	return c.deploy.Post(fmt.Sprintf("/api/stacks/%d/stop?endpointId=%d", id, endpointID), nil, nil)
+}
+
+// RestartStack stops a stack and starts it again; a stack that is already
+// stopped is only started. If the start fails, the error says the stack
+// was left stopped.
+func (c *Client) RestartStack(s *APIStack) error {
+	if s.StatusLabel() != "inactive" {
+		if err := c.StopStack(s.ID, s.EndpointID); err != nil {
+			return err
+		}
+	}
+	err := c.StartStack(s.ID, s.EndpointID)
+	if pe, ok := err.(*PortainerError); ok {
+		pe.Details = append(pe.Details, fmt.Sprintf("stack %q (ID %d) was left stopped", s.Name, s.ID))
+	}
+	return err
+}
+
+// DeleteStack removes the stack and its containers.
+func (c *Client) DeleteStack(id, endpointID int64) error {
+	return c.deploy.Delete(fmt.Sprintf("/api/stacks/%d?endpointId=%d", id, endpointID), nil)
+}
+
+// RedeployGitStack pulls the latest commit of a git-backed stack and
+// redeploys it, keeping the given env.
+func (c *Client) RedeployGitStack(id, endpointID int64, ref string, env []APIEnvVar, prune, pullImage bool) (*APIStack, error) {
+	if env == nil {
+		env = []APIEnvVar{}
+	}
+	req := APIStackGitRedeployRequest{
+		Env:                     env,
+		Prune:                   prune,
+		PullImage:               pullImage,
+		RepositoryReferenceName: ref,
+	}
+	var stack APIStack
+	path := fmt.Sprintf("/api/stacks/%d/git/redeploy?endpointId=%d", id, endpointID)
+	if err := c.deploy.Put(path, req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
 func (c *Client) ListEndpoints() ([]APIEndpoint, error) {
 	var endpoints []APIEndpoint
 	if err := c.get("/api/endpoints", &endpoints); err != nil {
@@ -113,3 +195,520 @@ func (c *Client) ListContainers(endpointID int64) ([]APIContainer, error) {
 	}
 	return containers, nil
 }
+
+// ListRegistries returns the registries configured in Portainer. Passwords
+// are never included.
+func (c *Client) ListRegistries() ([]APIRegistry, error) {
+	var registries []APIRegistry
+	if err := c.get("/api/registries", &registries); err != nil {
+		return nil, err
+	}
+	return registries, nil
+}
+
+func (c *Client) CreateRegistry(req *APIRegistryCreateRequest) (*APIRegistry, error) {
+	var registry APIRegistry
+	if err := c.api.Post("/api/registries", req, &registry); err != nil {
+		return nil, err
+	}
+	return &registry, nil
+}
+
+func (c *Client) DeleteRegistry(id int64) error {
+	return c.api.Delete(fmt.Sprintf("/api/registries/%d", id), nil)
+}
+
+// ListTemplates returns the app templates from the template URL set in
+// Portainer's settings.
+func (c *Client) ListTemplates() ([]APITemplate, error) {
+	var body struct {
+		Templates []APITemplate `json:"templates"`
+	}
+	if err := c.get("/api/templates", &body); err != nil {
+		return nil, err
+	}
+	return body.Templates, nil
+}
+
+func (c *Client) ListCustomTemplates() ([]APICustomTemplate, error) {
+	var templates []APICustomTemplate
+	if err := c.get("/api/custom_templates", &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (c *Client) GetCustomTemplate(id int64) (*APICustomTemplate, error) {
+	var template APICustomTemplate
+	if err := c.get(fmt.Sprintf("/api/custom_templates/%d", id), &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (c *Client) GetCustomTemplateFile(id int64) (string, error) {
+	var file struct {
+		FileContent string `json:"FileContent"`
+	}
+	if err := c.get(fmt.Sprintf("/api/custom_templates/%d/file", id), &file); err != nil {
+		return "", err
+	}
+	return file.FileContent, nil
+}
+
+func (c *Client) InspectImage(endpointID int64, id string) (*APIImageInspect, error) {
+	var image APIImageInspect
+	path := fmt.Sprintf("/api/endpoints/%d/docker/images/%s/json", endpointID, url.PathEscape(id))
+	if err := c.get(path, &image); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func dockerPath(endpointID int64, path string) string {
+	return fmt.Sprintf("/api/endpoints/%d/docker%s", endpointID, path)
+}
+
+// DiskUsage returns the endpoint's images and volumes with the number of
+// containers using each and their sizes. Docker walks every volume to size
+// it, so this can take a while on large hosts.
+func (c *Client) DiskUsage(endpointID int64) (*APIDiskUsage, error) {
+	var usage APIDiskUsage
+	if err := c.deploy.Get(dockerPath(endpointID, "/system/df"), &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// PullImage starts pulling ref on the endpoint and returns Docker's JSON
+// progress stream; see ReadPull. For a registry configured in Portainer,
+// Portainer fills in the credentials itself.
+func (c *Client) PullImage(ctx context.Context, endpointID int64, ref ImageRef, registry *APIRegistry) (io.ReadCloser, error) {
+	q := url.Values{}
+	q.Set("fromImage", ref.Name())
+	q.Set("tag", ref.Tag)
+	header := http.Header{}
+	if registry != nil {
+		auth, _ := json.Marshal(map[string]int64{"registryId": registry.ID})
+		header.Set("X-Registry-Auth", base64.StdEncoding.EncodeToString(auth))
+	}
+	return c.api.Open(ctx, http.MethodPost, dockerPath(endpointID, "/images/create?"+q.Encode()), header)
+}
+
+// RemoveImage untags the image and deletes it once no tag is left. force
+// also removes images used by stopped containers.
+func (c *Client) RemoveImage(endpointID int64, name string, force bool) ([]APIImageDeleteItem, error) {
+	var items []APIImageDeleteItem
+	path := dockerPath(endpointID, fmt.Sprintf("/images/%s?force=%t", url.PathEscape(name), force))
+	if err := c.api.Delete(path, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PruneImages deletes dangling images, or with all every image no
+// container uses.
+func (c *Client) PruneImages(endpointID int64, all bool) (*APIPruneReport, error) {
+	var report APIPruneReport
+	path := dockerPath(endpointID, "/images/prune")
+	if all {
+		path += "?filters=" + url.QueryEscape(`{"dangling":["false"]}`)
+	}
+	if err := c.deploy.Post(path, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) RemoveVolume(endpointID int64, name string, force bool) error {
+	path := dockerPath(endpointID, fmt.Sprintf("/volumes/%s?force=%t", url.PathEscape(name), force))
+	return c.api.Delete(path, nil)
+}
+
+// PruneVolumes deletes unused anonymous volumes, or with all unused named
+// volumes as well. Docker before API 1.42 ignores all and prunes both.
+func (c *Client) PruneVolumes(endpointID int64, all bool) (*APIPruneReport, error) {
+	var report APIPruneReport
+	path := dockerPath(endpointID, "/volumes/prune")
+	if all {
+		path += "?filters=" + url.QueryEscape(`{"all":["true"]}`)
+	}
+	if err := c.deploy.Post(path, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) ListNetworks(endpointID int64) ([]APINetwork, error) {
+	var networks []APINetwork
+	if err := c.get(dockerPath(endpointID, "/networks"), &networks); err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// InspectNetwork looks a network up by name or ID. Unlike the list it
+// includes the attached containers.
+func (c *Client) InspectNetwork(endpointID int64, id string) (*APINetwork, error) {
+	var network APINetwork
+	if err := c.get(dockerPath(endpointID, "/networks/"+url.PathEscape(id)), &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+func (c *Client) ListUsers() ([]APIUser, error) {
+	var users []APIUser
+	if err := c.get("/api/users", &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (c *Client) CreateUser(username, password string, role int) (*APIUser, error) {
+	var user APIUser
+	req := APIUserCreateRequest{Username: username, Password: password, Role: role}
+	if err := c.api.Post("/api/users", req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *Client) DeleteUser(id int64) error {
+	return c.api.Delete(fmt.Sprintf("/api/users/%d", id), nil)
+}
+
+func (c *Client) SetUserRole(id int64, role int) (*APIUser, error) {
+	var user APIUser
+	req := map[string]int{"role": role}
+	if err := c.api.Put(fmt.Sprintf("/api/users/%d", id), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *Client) ListTeams() ([]APITeam, error) {
+	var teams []APITeam
+	if err := c.get("/api/teams", &teams); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (c *Client) CreateTeam(name string) (*APITeam, error) {
+	var team APITeam
+	if err := c.api.Post("/api/teams", map[string]string{"name": name}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (c *Client) ListTeamMemberships() ([]APITeamMembership, error) {
+	var memberships []APITeamMembership
+	if err := c.get("/api/team_memberships", &memberships); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (c *Client) CreateTeamMembership(userID, teamID int64, role int) (*APITeamMembership, error) {
+	var membership APITeamMembership
+	req := APITeamMembershipRequest{UserID: userID, TeamID: teamID, Role: role}
+	if err := c.api.Post("/api/team_memberships", req, &membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (c *Client) DeleteTeamMembership(id int64) error {
+	return c.api.Delete(fmt.Sprintf("/api/team_memberships/%d", id), nil)
+}
+
+func (c *Client) ListEndpointGroups() ([]APIEndpointGroup, error) {
+	var groups []APIEndpointGroup
+	if err := c.get("/api/endpoint_groups", &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Directory fetches users, teams and memberships together. All three
+// need an administrator's token.
+func (c *Client) Directory() (*Directory, error) {
+	var d Directory
+	var err error
+	if d.Users, err = c.ListUsers(); err != nil {
+		return nil, err
+	}
+	if d.Teams, err = c.ListTeams(); err != nil {
+		return nil, err
+	}
+	if d.Memberships, err = c.ListTeamMemberships(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (c *Client) ListEdgeGroups() ([]APIEdgeGroup, error) {
+	var groups []APIEdgeGroup
+	if err := c.get("/api/edge_groups", &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (c *Client) ListEdgeStacks() ([]APIEdgeStack, error) {
+	var stacks []APIEdgeStack
+	if err := c.get("/api/edge_stacks", &stacks); err != nil {
+		return nil, err
+	}
+	return stacks, nil
+}
+
+func (c *Client) GetEdgeStackFile(id int64) (*APIStackFile, error) {
+	var file APIStackFile
+	if err := c.get(fmt.Sprintf("/api/edge_stacks/%d/file", id), &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// CreateEdgeStack creates a compose edge stack; the agents in its groups
+// pick it up on their next poll.
+func (c *Client) CreateEdgeStack(name, content string, groups []int64) (*APIEdgeStack, error) {
+	req := APIEdgeStackCreateRequest{
+		Name:             name,
+		StackFileContent: content,
+		EdgeGroups:       groups,
+		DeploymentType:   EdgeDeployCompose,
+		Registries:       []int64{},
+	}
+	var stack APIEdgeStack
+	if err := c.api.Post("/api/edge_stacks/create/string", req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+func (c *Client) UpdateEdgeStack(id int64, content string, groups []int64, deploymentType int) (*APIEdgeStack, error) {
+	req := APIEdgeStackUpdateRequest{
+		StackFileContent: content,
+		EdgeGroups:       groups,
+		DeploymentType:   deploymentType,
+		UpdateVersion:    true,
+	}
+	var stack APIEdgeStack
+	if err := c.api.Put(fmt.Sprintf("/api/edge_stacks/%d", id), req, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// Edge fetches edge groups, edge stacks and endpoints together.
+func (c *Client) Edge() (*Edge, error) {
+	var e Edge
+	var err error
+	if e.Groups, err = c.ListEdgeGroups(); err != nil {
+		return nil, err
+	}
+	if e.Stacks, err = c.ListEdgeStacks(); err != nil {
+		return nil, err
+	}
+	if e.Endpoints, err = c.ListEndpoints(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Backup requests an archive of Portainer's data, encrypted with password
+// unless it is empty. The archive is streamed back in the response body.
+func (c *Client) Backup(ctx context.Context, password string) (io.ReadCloser, error) {
+	payload, err := json.Marshal(APIBackupRequest{Password: password})
+	if err != nil {
+		return nil, APIError(fmt.Sprintf("failed to encode request: %s", err))
+	}
+	return c.api.Send(ctx, http.MethodPost, "/api/backup", nil, payload)
+}
+
+// Restore uploads a backup archive. Portainer only accepts it on a fresh
+// instance that has not been set up yet, and restarts afterwards. The
+// archive is streamed, so it is never held in memory or traced.
+func (c *Client) Restore(ctx context.Context, archive io.Reader, name, password string) error {
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	written := make(chan error, 1)
+	go func() {
+		err := writeRestoreForm(form, archive, name, password)
+		pw.CloseWithError(err)
+		written <- err
+	}()
+
+	header := http.Header{"Content-Type": {form.FormDataContentType()}}
+	resp, err := c.api.Upload(ctx, http.MethodPost, "/api/restore", header, pr)
+	pr.Close()
+	if werr := <-written; werr != nil && werr != io.ErrClosedPipe {
+		return werr
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Close()
+}
+
+func writeRestoreForm(form *multipart.Writer, archive io.Reader, name, password string) error {
+	if err := form.WriteField("password", password); err != nil {
+		return err
+	}
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, archive); err != nil {
+		if err == io.ErrClosedPipe {
+			return err
+		}
+		return ConfigError(fmt.Sprintf("cannot read %s: %s", name, err))
+	}
+	return form.Close()
+}
+
+// ServerVersion checks the token against a cheap authenticated endpoint,
+// then reads the version from /api/status, which is public.
+func (c *Client) ServerVersion() (string, error) {
+	if err := c.get("/api/endpoints?limit=1", nil); err != nil {
+		return "", err
+	}
+	var status struct {
+		Version string `json:"Version"`
+	}
+	if err := c.get("/api/status", &status); err != nil {
+		return "", err
+	}
+	return status.Version, nil
+}
+
+func containerPath(endpointID int64, id, action string) string {
+	return fmt.Sprintf("/api/endpoints/%d/docker/containers/%s/%s", endpointID, url.PathEscape(id), action)
+}
+
+// alreadyDone treats Docker's 304 (already started/stopped) as success.
+func alreadyDone(err error) error {
+	if e, ok := err.(*PortainerError); ok && e.Status == http.StatusNotModified {
+		return nil
+	}
+	return err
+}
+
+func (c *Client) StartContainer(endpointID int64, id string) error {
+	return alreadyDone(c.deploy.Post(containerPath(endpointID, id, "start"), nil, nil))
+}
+
+// StopContainer waits timeout seconds for a clean stop before killing;
+// a negative timeout uses the container's own stop timeout.
+func (c *Client) StopContainer(endpointID int64, id string, timeout int) error {
+	return alreadyDone(c.deploy.Post(containerPath(endpointID, id, "stop")+stopTimeout(timeout), nil, nil))
+}
+
+func (c *Client) RestartContainer(endpointID int64, id string, timeout int) error {
+	return c.deploy.Post(containerPath(endpointID, id, "restart")+stopTimeout(timeout), nil, nil)
+}
+
+func (c *Client) KillContainer(endpointID int64, id, signal string) error {
+	path := containerPath(endpointID, id, "kill")
+	if signal != "" {
+		path += "?signal=" + url.QueryEscape(signal)
+	}
+	return c.api.Post(path, nil, nil)
+}
+
+// RemoveContainer deletes a container, optionally stopping it first
+// (force) and removing its anonymous volumes.
+func (c *Client) RemoveContainer(endpointID int64, id string, force, volumes bool) error {
+	path := fmt.Sprintf("/api/endpoints/%d/docker/containers/%s?force=%t&v=%t", endpointID, url.PathEscape(id), force, volumes)
+	return c.deploy.Delete(path, nil)
+}
+
+func (c *Client) InspectContainer(endpointID int64, id string) (*APIContainerInspect, error) {
+	var container APIContainerInspect
+	if err := c.get(containerPath(endpointID, id, "json"), &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// ContainerLogs opens the container's log stream. Unless the container
+// has a TTY, stdout and stderr arrive multiplexed; see DemuxLogs. With
+// Follow set the stream stays open until ctx is cancelled.
+func (c *Client) ContainerLogs(ctx context.Context, endpointID int64, id string, opts LogOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	q.Set("stdout", "1")
+	q.Set("stderr", "1")
+	q.Set("follow", strconv.FormatBool(opts.Follow))
+	q.Set("timestamps", strconv.FormatBool(opts.Timestamps))
+	if opts.Tail >= 0 {
+		q.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Since > 0 {
+		q.Set("since", strconv.FormatInt(opts.Since, 10))
+	}
+	return c.api.Stream(ctx, containerPath(endpointID, id, "logs")+"?"+q.Encode())
+}
+
+// ContainerStats takes one stats sample. Docker waits for a second CPU
+// reading so the sample carries a delta, which takes about a second.
+func (c *Client) ContainerStats(endpointID int64, id string) (*APIContainerStats, error) {
+	var stats APIContainerStats
+	if err := c.get(containerPath(endpointID, id, "stats")+"?stream=false", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CreateExec prepares cmd to run in a container and returns the exec ID
+// for AttachExec.
+func (c *Client) CreateExec(endpointID int64, id string, cmd []string, stdin, tty bool) (string, error) {
+	req := APIExecCreateRequest{
+		AttachStdin:  stdin,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+		Cmd:          cmd,
+	}
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := c.api.Post(containerPath(endpointID, id, "exec"), req, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// AttachExec starts an exec through Portainer's websocket endpoint. The
+// connection carries the process's input and output until it exits.
+// Portainer always starts the exec in TTY mode, so output is unframed.
+func (c *Client) AttachExec(ctx context.Context, endpointID int64, execID string) (*common.WebSocket, error) {
+	path := fmt.Sprintf("/api/websocket/exec?endpointId=%d&id=%s", endpointID, url.QueryEscape(execID))
+	return c.api.Websocket(ctx, path)
+}
+
+// ResizeExec sets the exec's TTY size in characters.
+func (c *Client) ResizeExec(endpointID int64, execID string, width, height int) error {
+	path := fmt.Sprintf("/api/endpoints/%d/docker/exec/%s/resize?h=%d&w=%d", endpointID, url.PathEscape(execID), height, width)
+	return c.api.Post(path, nil, nil)
+}
+
+func (c *Client) InspectExec(endpointID int64, execID string) (*APIExecInspect, error) {
+	var exec APIExecInspect
+	path := fmt.Sprintf("/api/endpoints/%d/docker/exec/%s/json", endpointID, url.PathEscape(execID))
+	if err := c.get(path, &exec); err != nil {
+		return nil, err
+	}
+	return &exec, nil
+}
+
+func stopTimeout(seconds int) string {
+	if seconds < 0 {
+		return ""
+	}
+	return fmt.Sprintf("?t=%d", seconds)
+}
@@ -1,25 +1,38 @@
 package portainer
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
 
 func TestNewClientTrimsURL(t *testing.T) {
 	client := NewClient("http://example.com/", "token", false)
-	if client.baseURL != "http://example.com" {
-		t.Errorf("baseURL = %q, want %q", client.baseURL, "http://example.com")
+	if client.api.BaseURL != "http://example.com" {
+		t.Errorf("baseURL = %q, want %q", client.api.BaseURL, "http://example.com")
 	}
 }
 
 func TestNewClientNoTrailingSlash(t *testing.T) {
 	client := NewClient("http://example.com", "token", false)
-	if client.baseURL != "http://example.com" {
-		t.Errorf("baseURL = %q, want %q", client.baseURL, "http://example.com")
+	if client.api.BaseURL != "http://example.com" {
+		t.Errorf("baseURL = %q, want %q", client.api.BaseURL, "http://example.com")
 	}
 }
 
 func TestNewClientStoresToken(t *testing.T) {
 	client := NewClient("http://example.com", "mytoken", false)
-	if client.token != "mytoken" {
-		t.Errorf("token = %q, want %q", client.token, "mytoken")
+	auth, ok := client.api.Auth.(common.APIKeyAuth)
+	if !ok || auth.Key != "mytoken" {
+		t.Errorf("auth = %#v, want api key %q", client.api.Auth, "mytoken")
 	}
 }
 
@@ -27,7 +40,7 @@ func TestNewClientSecureByDefault(t *testing.T) {
 	client := NewClient("http://example.com", "token", false)
 	// When insecure=false, should not have custom TLS config that skips verification
 	// Just verify client was created successfully
-	if client.httpClient == nil {
+	if client.api.HTTPClient == nil {
 		t.Error("expected non-nil httpClient")
 	}
 }
@@ -35,7 +48,290 @@ func TestNewClientSecureByDefault(t *testing.T) {
 func TestNewClientInsecure(t *testing.T) {
 	client := NewClient("http://example.com", "token", true)
 	// When insecure=true, transport should be set
-	if client.httpClient.Transport == nil {
+	if client.api.HTTPClient.Transport == nil {
 		t.Error("expected non-nil transport for insecure client")
 	}
 }
+
+func TestCreateStack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/stacks" || r.URL.RawQuery != "type=2&method=string&endpointId=3" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		var req APIStackCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Name != "web" || req.StackFileContent != "services: {}" || len(req.Env) != 1 {
+			t.Errorf("unexpected body: %+v", req)
+		}
+		w.Write([]byte(`{"Id":7,"Name":"web","Type":2,"Status":1,"EndpointId":3}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	stack, err := client.CreateStack(3, "web", "services: {}", []APIEnvVar{{Name: "A", Value: "1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stack.ID != 7 {
+		t.Errorf("got stack %+v", stack)
+	}
+}
+
+func TestUpdateStack(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/stacks/7" || r.URL.Query().Get("endpointId") != "3" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"Id":7,"Name":"web","EndpointId":3}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	if _, err := client.UpdateStack(7, 3, "services: {}", nil, true, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["prune"] != true || body["pullImage"] != true {
+		t.Errorf("flags not sent: %v", body)
+	}
+	if env, ok := body["env"].([]interface{}); !ok || len(env) != 0 {
+		t.Errorf("env = %v, want empty list", body["env"])
+	}
+}
+
+func TestStackLifecycleRequests(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.String())
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"Id":7}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	if err := client.StartStack(7, 3); err != nil {
+		t.Fatalf("StartStack: %v", err)
+	}
+	if err := client.StopStack(7, 3); err != nil {
+		t.Fatalf("StopStack: %v", err)
+	}
+	if err := client.DeleteStack(7, 3); err != nil {
+		t.Fatalf("DeleteStack: %v", err)
+	}
+	if _, err := client.RedeployGitStack(7, 3, "refs/heads/main", nil, false, true); err != nil {
+		t.Fatalf("RedeployGitStack: %v", err)
+	}
+
+	want := []string{
+		"POST /api/stacks/7/start?endpointId=3",
+		"POST /api/stacks/7/stop?endpointId=3",
+		"DELETE /api/stacks/7?endpointId=3",
+		"PUT /api/stacks/7/git/redeploy?endpointId=3",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRestartStack(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Path)
+		if r.URL.Path == "/api/stacks/8/start" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"port is already allocated"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	if err := client.RestartStack(&APIStack{ID: 7, Name: "web", EndpointID: 3, Status: 2}); err != nil {
+		t.Fatalf("RestartStack: %v", err)
+	}
+	if want := []string{"/api/stacks/7/start"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stopped stack: requests = %v, want only %v", got, want)
+	}
+
+	got = nil
+	err := client.RestartStack(&APIStack{ID: 8, Name: "db", EndpointID: 3, Status: 1})
+	if want := []string{"/api/stacks/8/stop", "/api/stacks/8/start"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("running stack: requests = %v, want %v", got, want)
+	}
+	pe, ok := err.(*PortainerError)
+	if !ok || len(pe.Details) == 0 || pe.Details[len(pe.Details)-1] != `stack "db" (ID 8) was left stopped` {
+		t.Errorf("err = %#v, want a detail naming the stopped stack", err)
+	}
+}
+
+func TestContainerActionRequests(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.String())
+		if r.URL.Path == "/api/endpoints/2/docker/containers/abc/start" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	if err := client.StartContainer(2, "abc"); err != nil {
+		t.Errorf("304 from start should not be an error: %v", err)
+	}
+	client.StopContainer(2, "abc", -1)
+	client.RestartContainer(2, "abc", 10)
+	client.KillContainer(2, "abc", "SIGHUP")
+	client.RemoveContainer(2, "abc", true, false)
+
+	want := []string{
+		"POST /api/endpoints/2/docker/containers/abc/start",
+		"POST /api/endpoints/2/docker/containers/abc/stop",
+		"POST /api/endpoints/2/docker/containers/abc/restart?t=10",
+		"POST /api/endpoints/2/docker/containers/abc/kill?signal=SIGHUP",
+		"DELETE /api/endpoints/2/docker/containers/abc?force=true&v=false",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestContainerLogsQuery(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String()
+		w.Write(frame(1, "hello\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	body, err := client.ContainerLogs(context.Background(), 2, "web", LogOptions{Follow: true, Tail: 50, Since: 1714560000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	want := "/api/endpoints/2/docker/containers/web/logs?follow=true&since=1714560000&stderr=1&stdout=1&tail=50&timestamps=false"
+	if got != want {
+		t.Errorf("request = %q, want %q", got, want)
+	}
+	var out bytes.Buffer
+	if err := DemuxLogs(body, &out, &out, false); err != nil || out.String() != "hello\n" {
+		t.Errorf("logs = %q, err = %v", out.String(), err)
+	}
+}
+
+func TestExecSession(t *testing.T) {
+	var requests []string
+	var created APIExecCreateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		switch r.URL.Path {
+		case "/api/endpoints/2/docker/containers/web/exec":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{"Id":"e1"}`))
+		case "/api/websocket/exec":
+			ws, err := common.AcceptWebSocket(w, r)
+			if err != nil {
+				t.Errorf("accept: %v", err)
+				return
+			}
+			buf := make([]byte, 64)
+			n, _ := ws.Read(buf)
+			ws.Write(append([]byte("$ "), buf[:n]...))
+			ws.Close()
+		case "/api/endpoints/2/docker/exec/e1/json":
+			w.Write([]byte(`{"ID":"e1","Running":false,"ExitCode":3}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	execID, err := client.CreateExec(2, "web", []string{"sh"}, true, true)
+	if err != nil || execID != "e1" {
+		t.Fatalf("CreateExec = %q, %v", execID, err)
+	}
+	if !created.AttachStdin || !created.Tty || len(created.Cmd) != 1 || created.Cmd[0] != "sh" {
+		t.Errorf("create request = %+v", created)
+	}
+
+	ws, err := client.AttachExec(context.Background(), 2, execID)
+	if err != nil {
+		t.Fatalf("AttachExec: %v", err)
+	}
+	ws.Write([]byte("ls\n"))
+	out, _ := io.ReadAll(ws)
+	ws.Close()
+	if string(out) != "$ ls\n" {
+		t.Errorf("session output = %q", out)
+	}
+
+	if err := client.ResizeExec(2, execID, 120, 40); err != nil {
+		t.Errorf("ResizeExec: %v", err)
+	}
+	exec, err := client.InspectExec(2, execID)
+	if err != nil || exec.ExitCode != 3 {
+		t.Errorf("InspectExec = %+v, %v", exec, err)
+	}
+
+	want := []string{
+		"POST /api/endpoints/2/docker/containers/web/exec",
+		"GET /api/websocket/exec?endpointId=2&id=e1",
+		"POST /api/endpoints/2/docker/exec/e1/resize?h=40&w=120",
+		"GET /api/endpoints/2/docker/exec/e1/json",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestPullImageRegistryAuth(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"status":"Digest: sha256:abc"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", false)
+	ref := ParseImageRef("ghcr.io/org/app:1.2")
+	body, err := client.PullImage(context.Background(), 3, ref, &APIRegistry{ID: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	if got.Method != http.MethodPost || got.URL.String() != "/api/endpoints/3/docker/images/create?fromImage=ghcr.io%2Forg%2Fapp&tag=1.2" {
+		t.Errorf("request = %s %s", got.Method, got.URL)
+	}
+	auth, _ := base64.StdEncoding.DecodeString(got.Header.Get("X-Registry-Auth"))
+	if string(auth) != `{"registryId":7}` {
+		t.Errorf("X-Registry-Auth = %q", auth)
+	}
+	if digest, err := ReadPull(body, io.Discard); err != nil || digest != "sha256:abc" {
+		t.Errorf("digest = %q, err = %v", digest, err)
+	}
+}
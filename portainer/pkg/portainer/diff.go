@@ -0,0 +1,89 @@
+package portainer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+// Drift states for StackDiff.Status
+const (
+	DiffInSync = "in-sync"
+	DiffDrift  = "drift"
+	DiffNoFile = "no-file"
+)
+
+// DiffStack compares a deployed stack's compose file and env with local
+// copies. A nil localEnv skips the env comparison. Env values are compared
+// as they are but shown masked with r. A local value that is the deployed
+// one masked, as "backup export-stacks" writes it, counts as the same.
+func DiffStack(stack *APIStack, deployed, local, localPath string, localEnv []APIEnvVar, envPath string, r *common.Redactor) StackDiff {
+	result := StackDiff{
+		ID:         stack.ID,
+		Name:       stack.Name,
+		EndpointID: stack.EndpointID,
+		File:       localPath,
+		Status:     DiffInSync,
+	}
+
+	remote := fmt.Sprintf("portainer/%s", stack.Name)
+	diff := common.UnifiedDiff(remote+"/compose", localPath, deployed, local)
+	if localEnv != nil {
+		remoteShown, localShown := redactEnvPair(stack.Env, unmaskEnv(stack.Env, localEnv), r)
+		diff += common.UnifiedDiff(remote+"/env", envPath, EnvLines(remoteShown), EnvLines(localShown))
+	}
+	if diff != "" {
+		result.Status = DiffDrift
+		result.Diff = diff
+	}
+	return result
+}
+
+// unmaskEnv replaces local values that mask the deployed value with it.
+func unmaskEnv(remote, local []APIEnvVar) []APIEnvVar {
+	values := map[string]string{}
+	for _, e := range remote {
+		values[e.Name] = e.Value
+	}
+	out := make([]APIEnvVar, len(local))
+	for i, e := range local {
+		out[i] = e
+		if v, ok := values[e.Name]; ok && common.MatchesMasked(e.Value, v) {
+			out[i].Value = v
+		}
+	}
+	return out
+}
+
+// redactEnvPair masks both sides of an env comparison with r. A changed
+// secret would mask to the same text on both sides, so the local value is
+// marked to keep the change visible.
+func redactEnvPair(remote, local []APIEnvVar, r *common.Redactor) ([]APIEnvVar, []APIEnvVar) {
+	if r == nil {
+		return remote, local
+	}
+	values := map[string]string{}
+	for _, e := range remote {
+		values[e.Name] = e.Value
+	}
+	localShown := RedactEnv(local, r)
+	for i, e := range local {
+		if v, ok := values[e.Name]; ok && v != e.Value && localShown[i].Value == r.Value(e.Name, v) {
+			localShown[i].Value += " (changed)"
+		}
+	}
+	return RedactEnv(remote, r), localShown
+}
+
+// EnvLines renders env vars as sorted KEY=VALUE lines, so order changes
+// made in the UI don't count as drift.
+func EnvLines(env []APIEnvVar) string {
+	lines := make([]string, len(env))
+	for i, e := range env {
+		lines[i] = e.Name + "=" + e.Value
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
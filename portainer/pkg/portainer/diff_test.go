@@ -0,0 +1,59 @@
+package portainer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+func TestDiffStack(t *testing.T) {
+	stack := &APIStack{ID: 4, Name: "web", EndpointID: 2, Env: []APIEnvVar{{Name: "B", Value: "2"}, {Name: "A", Value: "1"}}}
+	compose := "services:\n  web:\n    image: nginx\n"
+
+	same := DiffStack(stack, compose, compose, "web.yml", []APIEnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, "web.env", nil)
+	if same.Status != DiffInSync || same.Diff != "" {
+		t.Errorf("env order should not count as drift: %+v", same)
+	}
+
+	drift := DiffStack(stack, compose, compose, "web.yml", []APIEnvVar{{Name: "A", Value: "9"}, {Name: "B", Value: "2"}}, "web.env", nil)
+	if drift.Status != DiffDrift {
+		t.Fatalf("status = %q, want drift", drift.Status)
+	}
+	for _, want := range []string{"--- portainer/web/env", "+++ web.env", "-A=1", "+A=9"} {
+		if !strings.Contains(drift.Diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, drift.Diff)
+		}
+	}
+
+	noEnv := DiffStack(stack, compose, compose+"  db:\n", "web.yml", nil, "", nil)
+	if noEnv.Status != DiffDrift || strings.Contains(noEnv.Diff, "env") {
+		t.Errorf("expected compose-only drift: %+v", noEnv)
+	}
+}
+
+func TestDiffStackMasksSecrets(t *testing.T) {
+	stack := &APIStack{ID: 4, Name: "web", Env: []APIEnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}, {Name: "TZ", Value: "UTC"}}}
+	compose := "services: {}\n"
+	r := common.NewRedactor(nil)
+
+	same := DiffStack(stack, compose, compose, "web.yml", []APIEnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}, {Name: "TZ", Value: "UTC"}}, "web.env", r)
+	if same.Status != DiffInSync {
+		t.Errorf("status = %q, want in-sync", same.Status)
+	}
+
+	changed := DiffStack(stack, compose, compose, "web.yml", []APIEnvVar{{Name: "DB_PASSWORD", Value: "swordfish"}, {Name: "TZ", Value: "Europe/Paris"}}, "web.env", r)
+	if changed.Status != DiffDrift {
+		t.Fatalf("status = %q, want drift", changed.Status)
+	}
+	for _, want := range []string{"-DB_PASSWORD=[REDACTED]", "+DB_PASSWORD=[REDACTED] (changed)", "-TZ=UTC", "+TZ=Europe/Paris"} {
+		if !strings.Contains(changed.Diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, changed.Diff)
+		}
+	}
+	for _, secret := range []string{"hunter2", "swordfish"} {
+		if strings.Contains(changed.Diff, secret) {
+			t.Errorf("diff leaks %q:\n%s", secret, changed.Diff)
+		}
+	}
+}
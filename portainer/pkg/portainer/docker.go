@@ -0,0 +1,395 @@
+package portainer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Docker /system/df response, trimmed to images and volumes. Unlike the
+// plain list endpoints it reports how many containers use each image and
+// volume, and volume sizes.
+type APIDiskUsage struct {
+	Images  []APIImage  `json:"Images"`
+	Volumes []APIVolume `json:"Volumes"`
+}
+
+type APIImage struct {
+	ID         string   `json:"Id"`
+	RepoTags   []string `json:"RepoTags"`
+	Created    int64    `json:"Created"`
+	Size       int64    `json:"Size"`
+	SharedSize int64    `json:"SharedSize"` // -1 when not computed
+	Containers int      `json:"Containers"`
+}
+
+type APIVolume struct {
+	Name      string            `json:"Name"`
+	Driver    string            `json:"Driver"`
+	CreatedAt string            `json:"CreatedAt"`
+	Labels    map[string]string `json:"Labels"`
+	UsageData *struct {
+		Size     int64 `json:"Size"` // -1 when the driver can't tell
+		RefCount int   `json:"RefCount"`
+	} `json:"UsageData"`
+}
+
+type APINetwork struct {
+	ID       string            `json:"Id"`
+	Name     string            `json:"Name"`
+	Driver   string            `json:"Driver"`
+	Scope    string            `json:"Scope"`
+	Internal bool              `json:"Internal"`
+	Created  string            `json:"Created"`
+	Labels   map[string]string `json:"Labels"`
+	IPAM     struct {
+		Config []struct {
+			Subnet  string `json:"Subnet"`
+			Gateway string `json:"Gateway"`
+		} `json:"Config"`
+	} `json:"IPAM"`
+	Containers map[string]struct {
+		Name        string `json:"Name"`
+		IPv4Address string `json:"IPv4Address"`
+		MacAddress  string `json:"MacAddress"`
+	} `json:"Containers"`
+}
+
+type APIImageDeleteItem struct {
+	Untagged string `json:"Untagged"`
+	Deleted  string `json:"Deleted"`
+}
+
+type APIPruneReport struct {
+	ImagesDeleted  []APIImageDeleteItem `json:"ImagesDeleted"`
+	VolumesDeleted []string             `json:"VolumesDeleted"`
+	SpaceReclaimed int64                `json:"SpaceReclaimed"`
+}
+
+// One line of the JSON progress stream from an image pull
+type APIPullProgress struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Progress string `json:"progress"`
+	Error    string `json:"error"`
+}
+
+// Output types
+
+type Image struct {
+	ID         string   `yaml:"id"`
+	Tags       []string `yaml:"tags"`
+	Size       string   `yaml:"size"`
+	Containers int      `yaml:"containers"`
+	Dangling   bool     `yaml:"dangling"`
+	Created    string   `yaml:"created"`
+	Endpoint   int64    `yaml:"endpoint"`
+
+	size int64
+}
+
+// ImageList totals the images shown. Reclaimable is the space freed by
+// removing the ones no container uses, less layers shared with others.
+type ImageList struct {
+	Count       int     `yaml:"count"`
+	Size        string  `yaml:"size"`
+	Reclaimable string  `yaml:"reclaimable"`
+	Images      []Image `yaml:"images"`
+}
+
+type Volume struct {
+	Name       string `yaml:"name"`
+	Driver     string `yaml:"driver"`
+	Size       string `yaml:"size"`
+	Containers int    `yaml:"containers"`
+	Stack      string `yaml:"stack"`
+	Created    string `yaml:"created"`
+	Endpoint   int64  `yaml:"endpoint"`
+
+	size int64
+}
+
+// VolumeList totals the volumes shown. Reclaimable is the size of the
+// ones no container uses.
+type VolumeList struct {
+	Count       int      `yaml:"count"`
+	Size        string   `yaml:"size"`
+	Reclaimable string   `yaml:"reclaimable"`
+	Volumes     []Volume `yaml:"volumes"`
+}
+
+type Network struct {
+	ID         string `yaml:"id"`
+	Name       string `yaml:"name"`
+	Driver     string `yaml:"driver"`
+	Scope      string `yaml:"scope"`
+	Subnet     string `yaml:"subnet"`
+	Stack      string `yaml:"stack"`
+	Endpoint   int64  `yaml:"endpoint"`
+	Containers int    `yaml:"containers"`
+}
+
+type NetworkList []Network
+
+type NetworkDetail struct {
+	ID         string             `yaml:"id"`
+	Name       string             `yaml:"name"`
+	Driver     string             `yaml:"driver"`
+	Scope      string             `yaml:"scope"`
+	Internal   bool               `yaml:"internal"`
+	Endpoint   int64              `yaml:"endpoint"`
+	Created    string             `yaml:"created"`
+	Subnets    []Subnet           `yaml:"subnets"`
+	Labels     map[string]string  `yaml:"labels,omitempty"`
+	Containers []NetworkContainer `yaml:"containers"`
+}
+
+type Subnet struct {
+	Subnet  string `yaml:"subnet"`
+	Gateway string `yaml:"gateway,omitempty"`
+}
+
+type NetworkContainer struct {
+	Name string `yaml:"name"`
+	IPv4 string `yaml:"ipv4"`
+	MAC  string `yaml:"mac"`
+}
+
+type ImagePullResult struct {
+	Image    string `yaml:"image"`
+	Endpoint int64  `yaml:"endpoint"`
+	Digest   string `yaml:"digest,omitempty"`
+	Status   string `yaml:"status"`
+}
+
+type ImageRemoveResult struct {
+	Image    string   `yaml:"image"`
+	Endpoint int64    `yaml:"endpoint"`
+	Untagged []string `yaml:"untagged"`
+	Deleted  []string `yaml:"deleted"`
+}
+
+type VolumeActionResult struct {
+	Name     string `yaml:"name"`
+	Endpoint int64  `yaml:"endpoint"`
+	Action   string `yaml:"action"`
+}
+
+type PruneResult struct {
+	Endpoint       int64    `yaml:"endpoint"`
+	Count          int      `yaml:"count"`
+	SpaceReclaimed string   `yaml:"spaceReclaimed"`
+	Deleted        []string `yaml:"deleted"`
+}
+
+// Dangling reports whether the image has lost all its tags.
+func (i *APIImage) Dangling() bool {
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *APIImage) ToImage(endpointID int64) Image {
+	tags := []string{}
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	return Image{
+		ID:         shortID(strings.TrimPrefix(i.ID, "sha256:")),
+		Tags:       tags,
+		Size:       formatBytes(uint64(max(i.Size, 0))),
+		Containers: i.Containers,
+		Dangling:   i.Dangling(),
+		Created:    time.Unix(i.Created, 0).UTC().Format(time.RFC3339),
+		Endpoint:   endpointID,
+		size:       i.Size,
+	}
+}
+
+// NewImageList builds the image report for each endpoint's disk usage,
+// largest first. danglingOnly keeps only untagged images.
+func NewImageList(usage map[int64]*APIDiskUsage, danglingOnly bool) ImageList {
+	list := ImageList{Images: []Image{}}
+	var total, reclaimable int64
+	for endpointID, du := range usage {
+		for _, img := range du.Images {
+			if danglingOnly && !img.Dangling() {
+				continue
+			}
+			list.Images = append(list.Images, img.ToImage(endpointID))
+			total += max(img.Size, 0)
+			if img.Containers == 0 {
+				reclaimable += max(img.Size-max(img.SharedSize, 0), 0)
+			}
+		}
+	}
+	sort.Slice(list.Images, func(i, j int) bool {
+		a, b := list.Images[i], list.Images[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		return a.size > b.size
+	})
+	list.Count = len(list.Images)
+	list.Size = formatBytes(uint64(total))
+	list.Reclaimable = formatBytes(uint64(reclaimable))
+	return list
+}
+
+func (v *APIVolume) ToVolume(endpointID int64) Volume {
+	out := Volume{
+		Name:     v.Name,
+		Driver:   v.Driver,
+		Size:     "-",
+		Stack:    v.Labels["com.docker.compose.project"],
+		Created:  dockerTime(v.CreatedAt),
+		Endpoint: endpointID,
+		size:     -1,
+	}
+	if v.UsageData != nil {
+		out.Containers = v.UsageData.RefCount
+		if v.UsageData.Size >= 0 {
+			out.Size = formatBytes(uint64(v.UsageData.Size))
+			out.size = v.UsageData.Size
+		}
+	}
+	return out
+}
+
+// NewVolumeList builds the volume report for each endpoint's disk usage,
+// largest first. unusedOnly keeps only volumes no container mounts.
+func NewVolumeList(usage map[int64]*APIDiskUsage, unusedOnly bool) VolumeList {
+	list := VolumeList{Volumes: []Volume{}}
+	var total, reclaimable int64
+	for endpointID, du := range usage {
+		for _, vol := range du.Volumes {
+			v := vol.ToVolume(endpointID)
+			if unusedOnly && v.Containers > 0 {
+				continue
+			}
+			list.Volumes = append(list.Volumes, v)
+			if v.size > 0 {
+				total += v.size
+				if v.Containers == 0 {
+					reclaimable += v.size
+				}
+			}
+		}
+	}
+	sort.Slice(list.Volumes, func(i, j int) bool {
+		a, b := list.Volumes[i], list.Volumes[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.size != b.size {
+			return a.size > b.size
+		}
+		return a.Name < b.Name
+	})
+	list.Count = len(list.Volumes)
+	list.Size = formatBytes(uint64(total))
+	list.Reclaimable = formatBytes(uint64(reclaimable))
+	return list
+}
+
+func (n *APINetwork) subnets() []Subnet {
+	subnets := []Subnet{}
+	for _, c := range n.IPAM.Config {
+		subnets = append(subnets, Subnet{Subnet: c.Subnet, Gateway: c.Gateway})
+	}
+	return subnets
+}
+
+func (n *APINetwork) ToNetwork(endpointID int64) Network {
+	var subnets []string
+	for _, s := range n.subnets() {
+		subnets = append(subnets, s.Subnet)
+	}
+	return Network{
+		ID:         shortID(n.ID),
+		Name:       n.Name,
+		Driver:     n.Driver,
+		Scope:      n.Scope,
+		Subnet:     strings.Join(subnets, ","),
+		Stack:      n.Labels["com.docker.compose.project"],
+		Endpoint:   endpointID,
+		Containers: len(n.Containers),
+	}
+}
+
+func (n *APINetwork) ToDetail(endpointID int64) NetworkDetail {
+	d := NetworkDetail{
+		ID:         shortID(n.ID),
+		Name:       n.Name,
+		Driver:     n.Driver,
+		Scope:      n.Scope,
+		Internal:   n.Internal,
+		Endpoint:   endpointID,
+		Created:    dockerTime(n.Created),
+		Subnets:    n.subnets(),
+		Labels:     n.Labels,
+		Containers: []NetworkContainer{},
+	}
+	for _, c := range n.Containers {
+		d.Containers = append(d.Containers, NetworkContainer{Name: c.Name, IPv4: c.IPv4Address, MAC: c.MacAddress})
+	}
+	sort.Slice(d.Containers, func(i, j int) bool {
+		return d.Containers[i].Name < d.Containers[j].Name
+	})
+	return d
+}
+
+func (r *APIPruneReport) ToResult(endpointID int64) PruneResult {
+	out := PruneResult{Endpoint: endpointID, SpaceReclaimed: formatBytes(uint64(max(r.SpaceReclaimed, 0))), Deleted: []string{}}
+	for _, img := range r.ImagesDeleted {
+		if img.Deleted != "" {
+			out.Deleted = append(out.Deleted, shortID(strings.TrimPrefix(img.Deleted, "sha256:")))
+		}
+	}
+	out.Deleted = append(out.Deleted, r.VolumesDeleted...)
+	out.Count = len(out.Deleted)
+	return out
+}
+
+// ReadPull follows a pull's progress stream to the end, writing one line
+// per layer status change to progress, and returns the pulled digest.
+// Docker reports pull failures inside the stream, not as an HTTP status.
+func ReadPull(r io.Reader, progress io.Writer) (string, error) {
+	digest := ""
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var p APIPullProgress
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+			continue
+		}
+		if p.Error != "" {
+			return "", APIError(p.Error)
+		}
+		if d, ok := strings.CutPrefix(p.Status, "Digest: "); ok {
+			digest = d
+		}
+		// Byte counts arrive many times a second; only state changes are shown
+		if p.Progress != "" || p.Status == "" {
+			continue
+		}
+		if p.ID != "" {
+			fmt.Fprintf(progress, "%s: %s\n", p.ID, p.Status)
+		} else {
+			fmt.Fprintln(progress, p.Status)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", NetworkError(err.Error())
+	}
+	return digest, nil
+}
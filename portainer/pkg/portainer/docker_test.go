@@ -0,0 +1,109 @@
+package portainer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewImageListReclaimable(t *testing.T) {
+	usage := map[int64]*APIDiskUsage{
+		1: {Images: []APIImage{
+			{ID: "sha256:aaa", RepoTags: []string{"nginx:latest"}, Size: 4096, SharedSize: 0, Containers: 1},
+			{ID: "sha256:bbb", RepoTags: []string{"<none>:<none>"}, Size: 2048, SharedSize: 1024, Containers: 0},
+			{ID: "sha256:ccc", RepoTags: nil, Size: 1024, SharedSize: -1, Containers: 0},
+		}},
+	}
+
+	list := NewImageList(usage, false)
+	if list.Count != 3 || list.Size != "7.0 KB" {
+		t.Errorf("count = %d, size = %q", list.Count, list.Size)
+	}
+	// bbb frees what it doesn't share with other images; aaa is in use
+	if list.Reclaimable != "2.0 KB" {
+		t.Errorf("reclaimable = %q, want 2.0 KB", list.Reclaimable)
+	}
+	if list.Images[0].ID != "aaa" {
+		t.Errorf("not sorted by size: first is %q", list.Images[0].ID)
+	}
+
+	dangling := NewImageList(usage, true)
+	if dangling.Count != 2 || !dangling.Images[0].Dangling || len(dangling.Images[0].Tags) != 0 {
+		t.Errorf("dangling = %+v", dangling.Images)
+	}
+}
+
+func TestNewVolumeListReclaimable(t *testing.T) {
+	usage := map[int64]*APIDiskUsage{
+		1: {Volumes: []APIVolume{
+			{Name: "db", Labels: map[string]string{"com.docker.compose.project": "app"}},
+			{Name: "old"},
+			{Name: "nfs"},
+		}},
+	}
+	sizes := []struct {
+		size int64
+		refs int
+	}{{2048, 1}, {1024, 0}, {-1, 0}}
+	for i, s := range sizes {
+		v := &usage[1].Volumes[i]
+		v.UsageData = &struct {
+			Size     int64 `json:"Size"`
+			RefCount int   `json:"RefCount"`
+		}{s.size, s.refs}
+	}
+
+	list := NewVolumeList(usage, false)
+	if list.Size != "3.0 KB" || list.Reclaimable != "1.0 KB" {
+		t.Errorf("size = %q, reclaimable = %q", list.Size, list.Reclaimable)
+	}
+	if list.Volumes[0].Name != "db" || list.Volumes[0].Stack != "app" {
+		t.Errorf("first = %+v", list.Volumes[0])
+	}
+	if list.Volumes[2].Size != "-" {
+		t.Errorf("unknown size = %q, want -", list.Volumes[2].Size)
+	}
+
+	unused := NewVolumeList(usage, true)
+	if unused.Count != 2 {
+		t.Errorf("unused count = %d, want 2", unused.Count)
+	}
+}
+
+func TestPruneReportToResult(t *testing.T) {
+	report := APIPruneReport{
+		ImagesDeleted: []APIImageDeleteItem{
+			{Untagged: "nginx:old"},
+			{Deleted: "sha256:0123456789abcdef"},
+		},
+		SpaceReclaimed: 3 * 1024 * 1024,
+	}
+	result := report.ToResult(2)
+	if result.Count != 1 || result.Deleted[0] != "0123456789ab" || result.SpaceReclaimed != "3.0 MB" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestReadPull(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/nginx","id":"latest"}`,
+		`{"status":"Downloading","progressDetail":{"current":1},"progress":"[>  ]","id":"a1"}`,
+		`{"status":"Pull complete","id":"a1"}`,
+		`{"status":"Digest: sha256:abc"}`,
+		`{"status":"Status: Downloaded newer image for nginx:latest"}`,
+	}, "\n")
+
+	var progress bytes.Buffer
+	digest, err := ReadPull(strings.NewReader(stream), &progress)
+	if err != nil || digest != "sha256:abc" {
+		t.Fatalf("digest = %q, err = %v", digest, err)
+	}
+	if strings.Contains(progress.String(), "Downloading") || !strings.Contains(progress.String(), "a1: Pull complete\n") {
+		t.Errorf("progress = %q", progress.String())
+	}
+
+	_, err = ReadPull(strings.NewReader(`{"error":"manifest unknown"}`), &progress)
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrAPI || !strings.Contains(e.Message, "manifest unknown") {
+		t.Errorf("err = %v, want manifest unknown", err)
+	}
+}
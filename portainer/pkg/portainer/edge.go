@@ -0,0 +1,362 @@
+package portainer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Edge stack status types reported by edge agents (Portainer 2.19+)
+const (
+	EdgeStatusPending = iota
+	EdgeStatusDeploymentReceived
+	EdgeStatusError
+	EdgeStatusAcknowledged
+	EdgeStatusRemoved
+	EdgeStatusRemoteUpdateSuccess
+	EdgeStatusImagesPulled
+	EdgeStatusRunning
+	EdgeStatusDeploying
+	EdgeStatusRemoving
+	EdgeStatusPausedDeploying
+	EdgeStatusPausedRemoving
+	EdgeStatusCompleted
+)
+
+var edgeStatusLabels = []string{
+	"pending", "received", "error", "acknowledged", "removed", "updated", "images-pulled",
+	"running", "deploying", "removing", "paused-deploying", "paused-removing", "completed",
+}
+
+// Edge stack deployment types
+const (
+	EdgeDeployCompose    = 0
+	EdgeDeployKubernetes = 1
+)
+
+// Edge deployment outcomes, summarising the status types
+const (
+	EdgeOutcomeDeployed = "deployed"
+	EdgeOutcomePending  = "pending"
+	EdgeOutcomeFailed   = "failed"
+)
+
+type APIEdgeGroup struct {
+	ID        int64   `json:"Id"`
+	Name      string  `json:"Name"`
+	Dynamic   bool    `json:"Dynamic"`
+	TagIDs    []int64 `json:"TagIds"`
+	Endpoints []int64 `json:"Endpoints"` // filled in from tags for dynamic groups
+}
+
+type APIEdgeStack struct {
+	ID             int64                        `json:"Id"`
+	Name           string                       `json:"Name"`
+	Status         map[int64]APIEdgeStackStatus `json:"Status"`
+	CreationDate   int64                        `json:"CreationDate"`
+	EdgeGroups     []int64                      `json:"EdgeGroups"`
+	DeploymentType int                          `json:"DeploymentType"`
+	Version        int                          `json:"Version"`
+}
+
+// APIEdgeStackStatus is one endpoint's status history for an edge stack,
+// oldest first.
+type APIEdgeStackStatus struct {
+	EndpointID     int64           `json:"EndpointID"`
+	Status         []APIEdgeStatus `json:"Status"`
+	DeploymentInfo struct {
+		Version int `json:"Version"`
+	} `json:"DeploymentInfo"`
+}
+
+type APIEdgeStatus struct {
+	Type  int    `json:"Type"`
+	Error string `json:"Error"`
+	Time  int64  `json:"Time"`
+}
+
+type APIEdgeStackCreateRequest struct {
+	Name             string  `json:"name"`
+	StackFileContent string  `json:"stackFileContent"`
+	EdgeGroups       []int64 `json:"edgeGroups"`
+	DeploymentType   int     `json:"deploymentType"`
+	Registries       []int64 `json:"registries"`
+}
+
+// APIEdgeStackUpdateRequest with UpdateVersion set makes the agents
+// redeploy even when the file is unchanged.
+type APIEdgeStackUpdateRequest struct {
+	StackFileContent string  `json:"stackFileContent"`
+	EdgeGroups       []int64 `json:"edgeGroups"`
+	DeploymentType   int     `json:"deploymentType"`
+	UpdateVersion    bool    `json:"updateVersion"`
+}
+
+// Output types
+
+type EdgeGroup struct {
+	ID        int64    `yaml:"id"`
+	Name      string   `yaml:"name"`
+	Type      string   `yaml:"type"`
+	Endpoints []string `yaml:"endpoints"`
+	Stacks    []string `yaml:"stacks"`
+}
+
+type EdgeStackListItem struct {
+	ID        int64    `yaml:"id"`
+	Name      string   `yaml:"name"`
+	Type      string   `yaml:"type"`
+	Groups    []string `yaml:"groups"`
+	Version   int      `yaml:"version"`
+	Endpoints int      `yaml:"endpoints"`
+	Deployed  int      `yaml:"deployed"`
+	Pending   int      `yaml:"pending"`
+	Failed    int      `yaml:"failed"`
+}
+
+type EdgeStack struct {
+	EdgeStackListItem `yaml:",inline"`
+	Created           string               `yaml:"created,omitempty"`
+	Status            []EdgeEndpointStatus `yaml:"status"`
+	StackFile         string               `yaml:"stackFile,omitempty"`
+}
+
+// EdgeEndpointStatus is where an edge stack stands on one endpoint.
+// Outdated means the endpoint last deployed an older version of the stack.
+type EdgeEndpointStatus struct {
+	Stack      string `yaml:"stack"`
+	EndpointID int64  `yaml:"endpointId"`
+	Endpoint   string `yaml:"endpoint"`
+	Outcome    string `yaml:"outcome"`
+	Status     string `yaml:"status"`
+	Outdated   bool   `yaml:"outdated,omitempty"`
+	Updated    string `yaml:"updated,omitempty"`
+	Error      string `yaml:"error,omitempty"`
+}
+
+func EdgeStatusLabel(t int) string {
+	if t >= 0 && t < len(edgeStatusLabels) {
+		return edgeStatusLabels[t]
+	}
+	return "unknown"
+}
+
+// edgeOutcome is failed on an error, deployed once the agent has the stack
+// running, and pending for every step in between.
+func edgeOutcome(t int) string {
+	switch t {
+	case EdgeStatusError:
+		return EdgeOutcomeFailed
+	case EdgeStatusRunning, EdgeStatusRemoteUpdateSuccess, EdgeStatusCompleted:
+		return EdgeOutcomeDeployed
+	default:
+		return EdgeOutcomePending
+	}
+}
+
+func (s *APIEdgeStack) TypeLabel() string {
+	if s.DeploymentType == EdgeDeployKubernetes {
+		return "kubernetes"
+	}
+	return "compose"
+}
+
+// latest is the most recent status; ok is false before the agent reports.
+func (s *APIEdgeStackStatus) latest() (APIEdgeStatus, bool) {
+	if len(s.Status) == 0 {
+		return APIEdgeStatus{}, false
+	}
+	latest := s.Status[0]
+	for _, st := range s.Status[1:] {
+		if st.Time >= latest.Time {
+			latest = st
+		}
+	}
+	return latest, true
+}
+
+// Edge holds edge groups, edge stacks and endpoint names, which every
+// edge view needs together.
+type Edge struct {
+	Groups    []APIEdgeGroup
+	Stacks    []APIEdgeStack
+	Endpoints []APIEndpoint
+}
+
+// FindGroup looks an edge group up by name or ID.
+func (e *Edge) FindGroup(ref string) (*APIEdgeGroup, error) {
+	id, _ := strconv.ParseInt(ref, 10, 64)
+	for i := range e.Groups {
+		if strings.EqualFold(e.Groups[i].Name, ref) {
+			return &e.Groups[i], nil
+		}
+	}
+	for i := range e.Groups {
+		if e.Groups[i].ID == id {
+			return &e.Groups[i], nil
+		}
+	}
+	return nil, NotFoundError(fmt.Sprintf("edge group %q", ref))
+}
+
+// FindStack looks an edge stack up by name or ID.
+func (e *Edge) FindStack(ref string) (*APIEdgeStack, error) {
+	id, _ := strconv.ParseInt(ref, 10, 64)
+	for i := range e.Stacks {
+		if e.Stacks[i].Name == ref {
+			return &e.Stacks[i], nil
+		}
+	}
+	for i := range e.Stacks {
+		if e.Stacks[i].ID == id {
+			return &e.Stacks[i], nil
+		}
+	}
+	return nil, NotFoundError(fmt.Sprintf("edge stack %q", ref))
+}
+
+func (e *Edge) endpointName(id int64) string {
+	for _, ep := range e.Endpoints {
+		if ep.ID == id {
+			return ep.Name
+		}
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (e *Edge) groupName(id int64) string {
+	for _, g := range e.Groups {
+		if g.ID == id {
+			return g.Name
+		}
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func (e *Edge) ToGroups() []EdgeGroup {
+	out := make([]EdgeGroup, len(e.Groups))
+	for i, g := range e.Groups {
+		out[i] = EdgeGroup{ID: g.ID, Name: g.Name, Type: "static", Endpoints: []string{}, Stacks: []string{}}
+		if g.Dynamic {
+			out[i].Type = "dynamic"
+		}
+		for _, id := range g.Endpoints {
+			out[i].Endpoints = append(out[i].Endpoints, e.endpointName(id))
+		}
+		for _, s := range e.Stacks {
+			for _, gid := range s.EdgeGroups {
+				if gid == g.ID {
+					out[i].Stacks = append(out[i].Stacks, s.Name)
+				}
+			}
+		}
+		sort.Strings(out[i].Endpoints)
+		sort.Strings(out[i].Stacks)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// targets are the endpoints an edge stack should run on: those in its
+// groups, plus any that reported a status since leaving them.
+func (e *Edge) targets(s *APIEdgeStack) []int64 {
+	seen := map[int64]bool{}
+	var ids []int64
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, gid := range s.EdgeGroups {
+		for _, g := range e.Groups {
+			if g.ID == gid {
+				for _, id := range g.Endpoints {
+					add(id)
+				}
+			}
+		}
+	}
+	for id := range s.Status {
+		add(id)
+	}
+	return ids
+}
+
+// Status lists an edge stack's state on each of its endpoints, failed
+// first, then pending, then by endpoint name.
+func (e *Edge) Status(s *APIEdgeStack) []EdgeEndpointStatus {
+	out := []EdgeEndpointStatus{}
+	for _, id := range e.targets(s) {
+		row := EdgeEndpointStatus{Stack: s.Name, EndpointID: id, Endpoint: e.endpointName(id), Outcome: EdgeOutcomePending, Status: EdgeStatusLabel(EdgeStatusPending)}
+		if st, ok := s.Status[id]; ok {
+			if latest, ok := st.latest(); ok {
+				row.Outcome = edgeOutcome(latest.Type)
+				row.Status = EdgeStatusLabel(latest.Type)
+				row.Updated = formatUnixTime(latest.Time)
+				row.Error = latest.Error
+			}
+			row.Outdated = st.DeploymentInfo.Version != 0 && st.DeploymentInfo.Version < s.Version
+		}
+		out = append(out, row)
+	}
+	rank := map[string]int{EdgeOutcomeFailed: 0, EdgeOutcomePending: 1, EdgeOutcomeDeployed: 2}
+	sort.SliceStable(out, func(i, j int) bool {
+		if rank[out[i].Outcome] != rank[out[j].Outcome] {
+			return rank[out[i].Outcome] < rank[out[j].Outcome]
+		}
+		return out[i].Endpoint < out[j].Endpoint
+	})
+	return out
+}
+
+func (e *Edge) ToListItem(s *APIEdgeStack) EdgeStackListItem {
+	item := EdgeStackListItem{ID: s.ID, Name: s.Name, Type: s.TypeLabel(), Groups: []string{}, Version: s.Version}
+	for _, gid := range s.EdgeGroups {
+		item.Groups = append(item.Groups, e.groupName(gid))
+	}
+	for _, st := range e.Status(s) {
+		item.Endpoints++
+		switch st.Outcome {
+		case EdgeOutcomeDeployed:
+			item.Deployed++
+		case EdgeOutcomeFailed:
+			item.Failed++
+		default:
+			item.Pending++
+		}
+	}
+	return item
+}
+
+func (e *Edge) ToStackList() []EdgeStackListItem {
+	out := make([]EdgeStackListItem, len(e.Stacks))
+	for i := range e.Stacks {
+		out[i] = e.ToListItem(&e.Stacks[i])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (e *Edge) ToStack(s *APIEdgeStack, stackFile string) EdgeStack {
+	return EdgeStack{
+		EdgeStackListItem: e.ToListItem(s),
+		Created:           formatUnixTime(s.CreationDate),
+		Status:            e.Status(s),
+		StackFile:         stackFile,
+	}
+}
+
+// GroupIDs resolves edge group names or IDs.
+func (e *Edge) GroupIDs(refs []string) ([]int64, error) {
+	var ids []int64
+	for _, ref := range refs {
+		g, err := e.FindGroup(ref)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, g.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,79 @@
+package portainer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testEdge(t *testing.T) *Edge {
+	t.Helper()
+	var stacks []APIEdgeStack
+	data := `[{"Id":1,"Name":"monitor","EdgeGroups":[10],"Version":3,"Status":{
+		"21":{"EndpointID":21,"Status":[{"Type":1,"Time":100},{"Type":7,"Time":200}],"DeploymentInfo":{"Version":3}},
+		"22":{"EndpointID":22,"Status":[{"Type":8,"Time":100},{"Type":2,"Error":"pull access denied","Time":150}],"DeploymentInfo":{"Version":3}},
+		"23":{"EndpointID":23,"Status":[{"Type":7,"Time":90}],"DeploymentInfo":{"Version":2}}}}]`
+	if err := json.Unmarshal([]byte(data), &stacks); err != nil {
+		t.Fatalf("decode edge stacks: %v", err)
+	}
+	return &Edge{
+		Groups: []APIEdgeGroup{
+			{ID: 10, Name: "sites", Endpoints: []int64{21, 22, 23, 24}},
+			{ID: 11, Name: "arm", Dynamic: true, Endpoints: []int64{22}},
+		},
+		Stacks: stacks,
+		Endpoints: []APIEndpoint{
+			{ID: 21, Name: "cabin"}, {ID: 22, Name: "shop"}, {ID: 23, Name: "barn"}, {ID: 24, Name: "garage"},
+		},
+	}
+}
+
+func TestEdgeStatus(t *testing.T) {
+	e := testEdge(t)
+	status := e.Status(&e.Stacks[0])
+
+	var got []string
+	for _, s := range status {
+		got = append(got, s.Endpoint+":"+s.Outcome+":"+s.Status)
+	}
+	want := []string{"shop:failed:error", "garage:pending:pending", "barn:deployed:running", "cabin:deployed:running"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("status = %v, want %v", got, want)
+	}
+	if status[0].Error != "pull access denied" || status[0].Updated == "" {
+		t.Errorf("failed row = %+v", status[0])
+	}
+	if !status[2].Outdated || status[3].Outdated {
+		t.Errorf("outdated: barn %v, cabin %v", status[2].Outdated, status[3].Outdated)
+	}
+}
+
+func TestEdgeListAndGroups(t *testing.T) {
+	e := testEdge(t)
+	item := e.ToStackList()[0]
+	if item.Endpoints != 4 || item.Deployed != 2 || item.Pending != 1 || item.Failed != 1 || !reflect.DeepEqual(item.Groups, []string{"sites"}) {
+		t.Errorf("list item = %+v", item)
+	}
+
+	groups := e.ToGroups()
+	if groups[0].Name != "arm" || groups[0].Type != "dynamic" || len(groups[0].Stacks) != 0 {
+		t.Errorf("groups[0] = %+v", groups[0])
+	}
+	if !reflect.DeepEqual(groups[1].Endpoints, []string{"barn", "cabin", "garage", "shop"}) || !reflect.DeepEqual(groups[1].Stacks, []string{"monitor"}) {
+		t.Errorf("groups[1] = %+v", groups[1])
+	}
+}
+
+func TestEdgeFind(t *testing.T) {
+	e := testEdge(t)
+	ids, err := e.GroupIDs([]string{"Sites", "11"})
+	if err != nil || !reflect.DeepEqual(ids, []int64{10, 11}) {
+		t.Errorf("GroupIDs = %v, %v", ids, err)
+	}
+	if _, err := e.GroupIDs([]string{"lab"}); err == nil {
+		t.Error("unknown group should fail")
+	}
+	if s, err := e.FindStack("1"); err != nil || s.Name != "monitor" {
+		t.Errorf("FindStack(1) = %v, %v", s, err)
+	}
+}
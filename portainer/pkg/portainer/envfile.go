@@ -0,0 +1,64 @@
+package portainer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+// ReadEnvFile parses a .env file into stack env vars.
+func ReadEnvFile(path string) ([]APIEnvVar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ConfigError(fmt.Sprintf("cannot read env file: %s", err))
+	}
+	defer f.Close()
+	return ParseEnv(f, path)
+}
+
+// ParseEnv reads KEY=VALUE lines the way docker compose does: blank lines
+// and # comments are skipped, an "export " prefix is allowed and matching
+// quotes around the value are removed.
+func ParseEnv(r io.Reader, name string) ([]APIEnvVar, error) {
+	var env []APIEnvVar
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, ConfigError(fmt.Sprintf("%s:%d: expected KEY=VALUE", name, n))
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, APIEnvVar{Name: key, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ConfigError(fmt.Sprintf("cannot read %s: %s", name, err))
+	}
+	return env, nil
+}
+
+// RedactEnv returns a copy of env with secret values masked by r. A nil r
+// returns env as is.
+func RedactEnv(env []APIEnvVar, r *common.Redactor) []APIEnvVar {
+	if r == nil || env == nil {
+		return env
+	}
+	out := make([]APIEnvVar, len(env))
+	for i, e := range env {
+		out[i] = APIEnvVar{Name: e.Name, Value: r.Value(e.Name, e.Value)}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package portainer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/schmoli/cli-tools/common"
+)
+
+func TestParseEnv(t *testing.T) {
+	input := `# database
+DB_HOST=db
+export DB_USER = app
+DB_PASS="s3cr3t # not a comment"
+EMPTY=
+QUOTED='single'
+`
+	env, err := ParseEnv(strings.NewReader(input), ".env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []APIEnvVar{
+		{Name: "DB_HOST", Value: "db"},
+		{Name: "DB_USER", Value: "app"},
+		{Name: "DB_PASS", Value: "s3cr3t # not a comment"},
+		{Name: "EMPTY", Value: ""},
+		{Name: "QUOTED", Value: "single"},
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("got %+v, want %+v", env, want)
+	}
+}
+
+func TestParseEnvInvalidLine(t *testing.T) {
+	_, err := ParseEnv(strings.NewReader("OK=1\nnot a pair\n"), ".env")
+	if err == nil || !strings.Contains(err.Error(), ".env:2") {
+		t.Errorf("expected error naming line 2, got %v", err)
+	}
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrConfig {
+		t.Errorf("expected CONFIG_ERROR, got %v", err)
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := []APIEnvVar{
+		{Name: "TZ", Value: "Europe/London"},
+		{Name: "DB_PASSWORD", Value: "hunter2"},
+	}
+
+	masked := RedactEnv(env, common.NewRedactor(nil))
+	if masked[0].Value != "Europe/London" || masked[1].Value != "[REDACTED]" {
+		t.Errorf("masked = %+v", masked)
+	}
+	if env[1].Value != "hunter2" {
+		t.Error("RedactEnv changed its input")
+	}
+	if shown := RedactEnv(env, nil); shown[1].Value != "hunter2" {
+		t.Errorf("nil redactor masked %+v", shown)
+	}
+}
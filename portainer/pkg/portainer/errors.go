@@ -1,59 +1,59 @@
 package portainer
 
-import "fmt"
+import (
+	"encoding/json"
 
-type ErrorCode string
-
-const (
-	ErrConfig  ErrorCode = "CONFIG_ERROR"
-	ErrAuth    ErrorCode = "AUTH_FAILED"
-	ErrNotFound ErrorCode = "NOT_FOUND"
-	ErrNetwork ErrorCode = "NETWORK_ERROR"
-	ErrAPI     ErrorCode = "API_ERROR"
+	"github.com/schmoli/cli-tools/common"
 )
 
-type PortainerError struct {
-	Code    ErrorCode
-	Message string
-}
+type ErrorCode = common.ErrorCode
 
-func (e *PortainerError) Error() string {
-	return e.Message
-}
+const (
+	ErrConfig   = common.ErrConfig
+	ErrAuth     = common.ErrAuth
+	ErrNotFound = common.ErrNotFound
+	ErrNetwork  = common.ErrNetwork
+	ErrAPI      = common.ErrAPI
+	ErrDrift    = common.ErrDrift
+)
 
-func (e *PortainerError) ExitCode() int {
-	switch e.Code {
-	case ErrConfig:
-		return 1
-	case ErrAuth:
-		return 2
-	case ErrNotFound:
-		return 3
-	case ErrNetwork:
-		return 4
-	case ErrAPI:
-		return 5
-	default:
-		return 1
-	}
-}
+type PortainerError = common.Error
 
 func ConfigError(msg string) *PortainerError {
-	return &PortainerError{Code: ErrConfig, Message: msg}
+	return common.ConfigError(msg)
 }
 
 func AuthError(msg string) *PortainerError {
-	return &PortainerError{Code: ErrAuth, Message: msg}
+	return common.AuthError(msg)
 }
 
 func NotFoundError(msg string) *PortainerError {
-	return &PortainerError{Code: ErrNotFound, Message: msg}
+	return common.NotFoundError(msg)
 }
 
 func NetworkError(msg string) *PortainerError {
-	return &PortainerError{Code: ErrNetwork, Message: msg}
+	return common.NetworkError(msg)
 }
 
 func APIError(msg string) *PortainerError {
-	return &PortainerError{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+	return common.APIError(msg)
+}
+
+func DriftError(msg string) *PortainerError {
+	return common.DriftError(msg)
+}
+
+// parseError reads Portainer's {"message": ..., "details": ...} envelope.
+func parseError(body []byte) (string, []string) {
+	var e struct {
+		Message string `json:"message"`
+		Details string `json:"details"`
+	}
+	if json.Unmarshal(body, &e) != nil || e.Message == "" {
+		return common.ParseErrorBody(body)
+	}
+	if e.Details != "" && e.Details != e.Message {
+		return e.Message, []string{e.Details}
+	}
+	return e.Message, nil
 }
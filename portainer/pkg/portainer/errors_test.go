@@ -53,3 +53,18 @@ func TestErrorMessage(t *testing.T) {
 		t.Errorf("Error() = %q, want %q", err.Error(), "missing url")
 	}
 }
+
+func TestParseError(t *testing.T) {
+	msg, details := parseError([]byte(`{"message":"Unable to find a stack with the specified identifier inside the database","details":"object not found inside the database"}`))
+	if msg != "Unable to find a stack with the specified identifier inside the database" {
+		t.Errorf("message = %q", msg)
+	}
+	if len(details) != 1 || details[0] != "object not found inside the database" {
+		t.Errorf("details = %v", details)
+	}
+
+	msg, _ = parseError([]byte("Invalid request payload\n"))
+	if msg != "Invalid request payload" {
+		t.Errorf("plain text message = %q", msg)
+	}
+}
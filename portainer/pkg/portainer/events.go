@@ -0,0 +1,174 @@
+package portainer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Reconnect backoff for dropped event streams
+const (
+	eventRetryMin = time.Second
+	eventRetryMax = 30 * time.Second
+)
+
+// APIEvent is a Docker event from /events
+type APIEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	Scope    string `json:"scope"`
+	Time     int64  `json:"time"`
+	TimeNano int64  `json:"timeNano"`
+}
+
+// Event is a decoded Docker event. Name, image, stack and exit code are
+// lifted from the actor's attributes where Docker sets them.
+type Event struct {
+	Time       string            `yaml:"time"`
+	EndpointID int64             `yaml:"endpointId"`
+	Type       string            `yaml:"type"`
+	Action     string            `yaml:"action"`
+	ID         string            `yaml:"id"`
+	Name       string            `yaml:"name,omitempty"`
+	Image      string            `yaml:"image,omitempty"`
+	Stack      string            `yaml:"stack,omitempty"`
+	ExitCode   string            `yaml:"exitCode,omitempty"`
+	Attributes map[string]string `yaml:"attributes,omitempty"`
+}
+
+func (e *APIEvent) ToEvent(endpointID int64) Event {
+	attrs := e.Actor.Attributes
+	id := e.Actor.ID
+	if e.Type == "container" || e.Type == "image" {
+		id = shortID(strings.TrimPrefix(id, "sha256:"))
+	}
+	return Event{
+		Time:       time.Unix(0, e.TimeNano).UTC().Format(time.RFC3339Nano),
+		EndpointID: endpointID,
+		Type:       e.Type,
+		Action:     e.Action,
+		ID:         id,
+		Name:       attrs["name"],
+		Image:      attrs["image"],
+		Stack:      attrs["com.docker.compose.project"],
+		ExitCode:   attrs["exitCode"],
+		Attributes: attrs,
+	}
+}
+
+// EventFilters are Docker event filters, e.g. type=container and
+// event=die. Each key may have several values.
+type EventFilters map[string][]string
+
+// ParseEventFilters reads --filter values such as
+// "type=container,event=die". Repeated keys accumulate.
+func ParseEventFilters(specs []string) (EventFilters, error) {
+	filters := EventFilters{}
+	for _, spec := range specs {
+		for _, pair := range strings.Split(spec, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || key == "" || value == "" {
+				return nil, ConfigError(fmt.Sprintf("invalid filter %q: expected KEY=VALUE", pair))
+			}
+			filters[key] = append(filters[key], value)
+		}
+	}
+	return filters, nil
+}
+
+// query builds the /events query, resuming after since when it is set.
+func (f EventFilters) query(since int64) string {
+	q := url.Values{}
+	if len(f) > 0 {
+		data, _ := json.Marshal(f)
+		q.Set("filters", string(data))
+	}
+	if since > 0 {
+		q.Set("since", fmt.Sprintf("%d.%09d", since/int64(time.Second), since%int64(time.Second)))
+	}
+	return q.Encode()
+}
+
+// DecodeEvents reads events from a stream until it ends, passing each to
+// fn. It returns the stream's error, or fn's.
+func DecodeEvents(r io.Reader, fn func(APIEvent) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var e APIEvent
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// FollowEvents streams an endpoint's events until ctx is cancelled,
+// reconnecting with backoff when the stream drops. Events missed while
+// reconnecting are replayed from the last one seen, which is skipped.
+// Errors that retrying cannot fix, such as a bad token or a missing
+// endpoint, end the stream, as does an error from fn; others are passed
+// to dropped before the next attempt.
+func (c *Client) FollowEvents(ctx context.Context, endpointID int64, filters EventFilters, fn func(Event) error, dropped func(err error, retry time.Duration)) error {
+	var last int64
+	wait := eventRetryMin
+	for {
+		var fnErr error
+		body, err := c.api.Stream(ctx, dockerPath(endpointID, "/events?"+filters.query(last)))
+		if err == nil {
+			err = DecodeEvents(body, func(e APIEvent) error {
+				if e.TimeNano <= last {
+					return nil
+				}
+				last = e.TimeNano
+				wait = eventRetryMin
+				fnErr = fn(e.ToEvent(endpointID))
+				return fnErr
+			})
+			body.Close()
+			if fnErr != nil {
+				return fnErr
+			}
+			if err == nil {
+				err = NetworkError("event stream closed by the server")
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !retryable(err) {
+			return err
+		}
+
+		dropped(err, wait)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, eventRetryMax)
+	}
+}
+
+// retryable is true for network errors, 5xx responses and errors from
+// reading a stream that broke off. A 4xx, such as Docker rejecting a
+// filter, would fail the same way every time.
+func retryable(err error) bool {
+	pe, ok := err.(*PortainerError)
+	if !ok {
+		return true
+	}
+	return pe.Code == ErrNetwork || (pe.Code == ErrAPI && pe.Status >= 500)
+}
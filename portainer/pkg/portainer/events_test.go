@@ -0,0 +1,122 @@
+package portainer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEventFilters(t *testing.T) {
+	f, err := ParseEventFilters([]string{"type=container,event=die", "event=oom"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := EventFilters{"type": {"container"}, "event": {"die", "oom"}}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("filters = %v, want %v", f, want)
+	}
+	if q := f.query(1700000000123456789); q != "filters=%7B%22event%22%3A%5B%22die%22%2C%22oom%22%5D%2C%22type%22%3A%5B%22container%22%5D%7D&since=1700000000.123456789" {
+		t.Errorf("query = %s", q)
+	}
+	if _, err := ParseEventFilters([]string{"type"}); err == nil {
+		t.Error("filter without value should fail")
+	}
+}
+
+const dieEvent = `{"Type":"container","Action":"die","Actor":{"ID":"0123456789abcdef0123","Attributes":{"name":"web","image":"nginx:1.27","exitCode":"137","com.docker.compose.project":"site"}},"scope":"local","time":1700000000,"timeNano":%d}`
+
+func TestDecodeEvents(t *testing.T) {
+	stream := fmt.Sprintf(dieEvent, 1700000000000000001) + "\n" + fmt.Sprintf(dieEvent, 1700000000000000002)
+	var events []Event
+	err := DecodeEvents(strings.NewReader(stream), func(e APIEvent) error {
+		events = append(events, e.ToEvent(3))
+		return nil
+	})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("events = %v, err = %v", events, err)
+	}
+	e := events[0]
+	if e.ID != "0123456789ab" || e.Name != "web" || e.Stack != "site" || e.ExitCode != "137" || e.EndpointID != 3 {
+		t.Errorf("event = %+v", e)
+	}
+	if e.Time != "2023-11-14T22:13:20.000000001Z" {
+		t.Errorf("time = %s", e.Time)
+	}
+}
+
+func TestFollowEventsReconnects(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("since"))
+		switch len(queries) {
+		case 1:
+			fmt.Fprintln(w, fmt.Sprintf(dieEvent, 1700000000000000001))
+		default:
+			// Replays the last event seen, which must be skipped
+			fmt.Fprintln(w, fmt.Sprintf(dieEvent, 1700000000000000001))
+			fmt.Fprintln(w, fmt.Sprintf(dieEvent, 1700000000000000002))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var times []string
+	drops := 0
+	err := client.FollowEvents(ctx, 1, nil, func(e Event) error {
+		times = append(times, e.Time)
+		if len(times) == 2 {
+			cancel()
+		}
+		return nil
+	}, func(err error, retry time.Duration) {
+		drops++
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(times) != 2 || drops != 1 {
+		t.Errorf("times = %v, drops = %d", times, drops)
+	}
+	if !reflect.DeepEqual(queries, []string{"", "1700000000.000000001"}) {
+		t.Errorf("since = %v", queries)
+	}
+}
+
+func TestFollowEventsStopsOnNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	err := client.FollowEvents(context.Background(), 9, nil, func(Event) error { return nil }, func(error, time.Duration) {
+		t.Error("a missing endpoint should not be retried")
+	})
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrNotFound {
+		t.Errorf("err = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestFollowEventsStopsOnBadRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"invalid filter 'type=bogus'"}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", false)
+
+	filters := EventFilters{"type": {"bogus"}}
+	err := client.FollowEvents(context.Background(), 9, filters, func(Event) error { return nil }, func(error, time.Duration) {
+		t.Error("a rejected filter should not be retried")
+	})
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrAPI || e.Status != http.StatusBadRequest {
+		t.Errorf("err = %v, want a 400 API error", err)
+	}
+}
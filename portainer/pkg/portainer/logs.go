@@ -0,0 +1,131 @@
+package portainer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogOptions selects which container log lines are returned.
+type LogOptions struct {
+	Follow     bool
+	Tail       int   // lines from the end; negative for all
+	Since      int64 // unix seconds; 0 for the start
+	Timestamps bool
+}
+
+// Docker log stream types in the multiplexed frame header
+const (
+	streamStdin  = 0
+	streamStdout = 1
+	streamStderr = 2
+)
+
+// DemuxLogs copies a Docker log stream to stdout and stderr. Without a
+// TTY each frame has an 8-byte header: the stream type, three zero bytes
+// and the big-endian payload size. TTY containers send raw output.
+func DemuxLogs(r io.Reader, stdout, stderr io.Writer, tty bool) error {
+	if tty {
+		_, err := io.Copy(stdout, r)
+		return err
+	}
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var w io.Writer
+		switch header[0] {
+		case streamStdin, streamStdout:
+			w = stdout
+		case streamStderr:
+			w = stderr
+		default:
+			return APIError(fmt.Sprintf("unexpected log stream type %d", header[0]))
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(w, r, size); err != nil {
+			return err
+		}
+	}
+}
+
+// ParseSince accepts a duration back from now ("10m", "2h"), an RFC3339
+// time or unix seconds, and returns unix seconds.
+func ParseSince(s string, now time.Time) (int64, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return 0, ConfigError(fmt.Sprintf("invalid --since %q: duration must be positive", s))
+		}
+		return now.Add(-d).Unix(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+		return n, nil
+	}
+	return 0, ConfigError(fmt.Sprintf("invalid --since %q: use a duration such as 10m, an RFC3339 time or unix seconds", s))
+}
+
+// PrefixWriter writes complete lines to w with a prefix, holding back a
+// partial line until it ends. Writers sharing mu never interleave within
+// a line, so several log streams can share one terminal.
+type PrefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func NewPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, mu: mu, prefix: prefix}
+}
+
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	var out []byte
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		out = append(out, p.prefix...)
+		out = append(out, p.buf[:i+1]...)
+		p.buf = p.buf[i+1:]
+	}
+	if len(out) > 0 {
+		if err := p.write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush writes any partial last line, ending it with a newline.
+func (p *PrefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	out := append([]byte(p.prefix), p.buf...)
+	p.buf = nil
+	return p.write(append(out, '\n'))
+}
+
+func (p *PrefixWriter) write(b []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.w.Write(b)
+	return err
+}
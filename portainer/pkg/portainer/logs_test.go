@@ -0,0 +1,108 @@
+package portainer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestDemuxLogs(t *testing.T) {
+	var in bytes.Buffer
+	in.Write(frame(1, "starting\n"))
+	in.Write(frame(2, "warning: low disk\n"))
+	in.Write(frame(1, "ready\n"))
+
+	var stdout, stderr bytes.Buffer
+	if err := DemuxLogs(&in, &stdout, &stderr, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.String() != "starting\nready\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if stderr.String() != "warning: low disk\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestDemuxLogsTTY(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := DemuxLogs(strings.NewReader("raw output\n"), &stdout, &stderr, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.String() != "raw output\n" || stderr.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestDemuxLogsTruncated(t *testing.T) {
+	data := frame(1, "complete line\n")
+	var stdout, stderr bytes.Buffer
+	if err := DemuxLogs(bytes.NewReader(data[:12]), &stdout, &stderr, false); err == nil {
+		t.Error("expected error for truncated frame")
+	}
+}
+
+func TestDemuxLogsBadStream(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := DemuxLogs(bytes.NewReader(frame(7, "x")), &stdout, &stderr, false)
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrAPI {
+		t.Errorf("expected API_ERROR, got %v", err)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"10m", now.Add(-10 * time.Minute).Unix()},
+		{"2h", now.Add(-2 * time.Hour).Unix()},
+		{"2024-05-01T11:00:00Z", now.Add(-time.Hour).Unix()},
+		{"1714560000", 1714560000},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSince(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"yesterday", "-5m", ""} {
+		if _, err := ParseSince(bad, now); err == nil {
+			t.Errorf("ParseSince(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	web := NewPrefixWriter(&out, &mu, "web | ")
+	db := NewPrefixWriter(&out, &mu, "db  | ")
+
+	web.Write([]byte("GET / 200\nGET /hea"))
+	db.Write([]byte("checkpoint\n"))
+	web.Write([]byte("lth 200\n"))
+	db.Write([]byte("shutting down"))
+	db.Flush()
+
+	want := "web | GET / 200\ndb  | checkpoint\nweb | GET /health 200\ndb  | shutting down\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -1,18 +1,31 @@
 package portainer
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
 // API response types (match Portainer JSON)
 type APIStack struct {
-	ID         int64       `json:"Id"`
-	Name       string      `json:"Name"`
-	Type       int         `json:"Type"`
-	Status     int         `json:"Status"`
-	EndpointID int64       `json:"EndpointId"`
-	Env        []APIEnvVar `json:"Env"`
+	ID         int64         `json:"Id"`
+	Name       string        `json:"Name"`
+	Type       int           `json:"Type"`
+	Status     int           `json:"Status"`
+	EndpointID int64         `json:"EndpointId"`
+	Env        []APIEnvVar   `json:"Env"`
+	GitConfig  *APIGitConfig `json:"GitConfig"`
+
+	ResourceControl *APIResourceControl `json:"ResourceControl"`
+}
+
+// APIGitConfig is set on stacks deployed from a git repository
+type APIGitConfig struct {
+	URL            string `json:"URL"`
+	ReferenceName  string `json:"ReferenceName"`
+	ConfigFilePath string `json:"ConfigFilePath"`
+	ConfigHash     string `json:"ConfigHash"`
 }
 
 type APIEnvVar struct {
@@ -24,12 +37,81 @@ type APIStackFile struct {
 	StackFileContent string `json:"StackFileContent"`
 }
 
+// Request bodies for stack create and update
+type APIStackCreateRequest struct {
+	Name             string      `json:"name"`
+	StackFileContent string      `json:"stackFileContent"`
+	Env              []APIEnvVar `json:"env"`
+}
+
+type APIStackGitCreateRequest struct {
+	Name                        string      `json:"name"`
+	RepositoryURL               string      `json:"repositoryURL"`
+	ComposeFilePathInRepository string      `json:"composeFilePathInRepository"`
+	Env                         []APIEnvVar `json:"env"`
+}
+
+type APIStackUpdateRequest struct {
+	StackFileContent string      `json:"stackFileContent"`
+	Env              []APIEnvVar `json:"env"`
+	Prune            bool        `json:"prune"`
+	PullImage        bool        `json:"pullImage"`
+}
+
+type APIStackGitRedeployRequest struct {
+	Env                     []APIEnvVar `json:"env"`
+	Prune                   bool        `json:"prune"`
+	PullImage               bool        `json:"pullImage"`
+	RepositoryReferenceName string      `json:"repositoryReferenceName"`
+}
+
 type APIEndpoint struct {
-	ID     int64  `json:"Id"`
-	Name   string `json:"Name"`
-	Type   int    `json:"Type"`
-	Status int    `json:"Status"`
-	URL    string `json:"URL"`
+	ID      int64  `json:"Id"`
+	Name    string `json:"Name"`
+	Type    int    `json:"Type"`
+	Status  int    `json:"Status"`
+	URL     string `json:"URL"`
+	GroupID int64  `json:"GroupId"`
+
+	UserAccessPolicies map[int64]APIAccessPolicy `json:"UserAccessPolicies"`
+	TeamAccessPolicies map[int64]APIAccessPolicy `json:"TeamAccessPolicies"`
+}
+
+// Portainer registry types
+const (
+	RegistryQuay      = 1
+	RegistryAzure     = 2
+	RegistryCustom    = 3
+	RegistryGitlab    = 4
+	RegistryProGet    = 5
+	RegistryDockerHub = 6
+	RegistryECR       = 7
+	RegistryGithub    = 8
+)
+
+type APIRegistry struct {
+	ID             int64  `json:"Id"`
+	Name           string `json:"Name"`
+	Type           int    `json:"Type"`
+	URL            string `json:"URL"`
+	Authentication bool   `json:"Authentication"`
+	Username       string `json:"Username"`
+}
+
+type APIRegistryCreateRequest struct {
+	Name           string `json:"name"`
+	Type           int    `json:"type"`
+	URL            string `json:"url"`
+	Authentication bool   `json:"authentication"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+}
+
+// Docker image inspect response, trimmed to the digests
+type APIImageInspect struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
 }
 
 // Output types (curated, YAML output)
@@ -40,9 +122,37 @@ type Stack struct {
 	Status     string      `yaml:"status"`
 	EndpointID int64       `yaml:"endpointId"`
 	Env        []APIEnvVar `yaml:"env,omitempty"`
+	Git        *StackGit   `yaml:"git,omitempty"`
 	StackFile  string      `yaml:"stackFile,omitempty"`
 }
 
+type StackGit struct {
+	URL    string `yaml:"url"`
+	Ref    string `yaml:"ref,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Commit string `yaml:"commit,omitempty"`
+}
+
+type StackActionResult struct {
+	ID         int64  `yaml:"id"`
+	Name       string `yaml:"name"`
+	EndpointID int64  `yaml:"endpointId"`
+	Action     string `yaml:"action"`
+}
+
+type StackDiff struct {
+	ID         int64  `yaml:"id"`
+	Name       string `yaml:"name"`
+	EndpointID int64  `yaml:"endpointId"`
+	File       string `yaml:"file,omitempty"`
+	Status     string `yaml:"status"`
+	Diff       string `yaml:"diff,omitempty"`
+}
+
+type StackDiffList struct {
+	Stacks []StackDiff `yaml:"stacks"`
+}
+
 type StackList struct {
 	Stacks []StackListItem `yaml:"stacks"`
 }
@@ -55,6 +165,27 @@ type StackListItem struct {
 	EndpointID int64  `yaml:"endpointId"`
 }
 
+type Registry struct {
+	ID             int64  `yaml:"id"`
+	Name           string `yaml:"name"`
+	Type           string `yaml:"type"`
+	URL            string `yaml:"url"`
+	Authentication bool   `yaml:"authentication"`
+	Username       string `yaml:"username,omitempty"`
+}
+
+// RegistryTest reports a registry check. Checked says what was proven:
+// the registry answers, Portainer's credentials are accepted, or an image
+// resolves.
+type RegistryTest struct {
+	ID      int64  `yaml:"id"`
+	Name    string `yaml:"name"`
+	Host    string `yaml:"host"`
+	Checked string `yaml:"checked"`
+	Image   string `yaml:"image,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
 type Endpoint struct {
 	ID     int64  `yaml:"id"`
 	Name   string `yaml:"name"`
@@ -110,10 +241,32 @@ func (s *APIStack) ToStack(stackFile string) Stack {
 		Status:     s.StatusLabel(),
 		EndpointID: s.EndpointID,
 		Env:        s.Env,
+		Git:        s.git(),
 		StackFile:  stackFile,
 	}
 }
 
+func (s *APIStack) git() *StackGit {
+	if s.GitConfig == nil || s.GitConfig.URL == "" {
+		return nil
+	}
+	return &StackGit{
+		URL:    s.GitConfig.URL,
+		Ref:    s.GitConfig.ReferenceName,
+		Path:   s.GitConfig.ConfigFilePath,
+		Commit: s.GitConfig.ConfigHash,
+	}
+}
+
+func (s *APIStack) ActionResult(action string) StackActionResult {
+	return StackActionResult{
+		ID:         s.ID,
+		Name:       s.Name,
+		EndpointID: s.EndpointID,
+		Action:     action,
+	}
+}
+
 func (e *APIEndpoint) TypeLabel() string {
 	switch e.Type {
 	case 1:
@@ -131,6 +284,16 @@ func (e *APIEndpoint) TypeLabel() string {
 	}
 }
 
+// HasDocker is true for endpoints with a Docker API to query; Kubernetes
+// and Azure endpoints have none.
+func (e *APIEndpoint) HasDocker() bool {
+	switch e.TypeLabel() {
+	case "docker", "agent", "edge-agent":
+		return true
+	}
+	return false
+}
+
 func (e *APIEndpoint) StatusLabel() string {
 	switch e.Status {
 	case 1:
@@ -157,6 +320,7 @@ type APIContainer struct {
 	ID      string            `json:"Id"`
 	Names   []string          `json:"Names"`
 	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
 	State   string            `json:"State"`
 	Status  string            `json:"Status"`
 	Created int64             `json:"Created"`
@@ -203,10 +367,7 @@ func (c *APIContainer) ToListItem(endpointID int64) ContainerListItem {
 	}
 
 	// Short ID (12 chars like Docker)
-	id := c.ID
-	if len(id) > 12 {
-		id = id[:12]
-	}
+	id := shortID(c.ID)
 
 	// Stack from compose label
 	stack := c.Labels["com.docker.compose.project"]
@@ -254,3 +415,195 @@ func formatUnixTime(unix int64) string {
 	}
 	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
 }
+
+// Docker exec create request
+type APIExecCreateRequest struct {
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+	Cmd          []string `json:"Cmd"`
+}
+
+type APIExecInspect struct {
+	ID       string `json:"ID"`
+	Running  bool   `json:"Running"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// Docker inspect response, trimmed to what ContainerDetail shows
+type APIContainerInspect struct {
+	ID      string   `json:"Id"`
+	Name    string   `json:"Name"`
+	Created string   `json:"Created"`
+	Path    string   `json:"Path"`
+	Args    []string `json:"Args"`
+	State   struct {
+		Status     string `json:"Status"`
+		ExitCode   int    `json:"ExitCode"`
+		Error      string `json:"Error"`
+		StartedAt  string `json:"StartedAt"`
+		FinishedAt string `json:"FinishedAt"`
+		Health     *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	RestartCount int `json:"RestartCount"`
+	Config       struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+		Tty    bool              `json:"Tty"`
+	} `json:"Config"`
+	HostConfig struct {
+		RestartPolicy struct {
+			Name string `json:"Name"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+type ContainerDetail struct {
+	ID            string            `yaml:"id"`
+	Name          string            `yaml:"name"`
+	Image         string            `yaml:"image"`
+	Endpoint      int64             `yaml:"endpoint"`
+	Stack         string            `yaml:"stack,omitempty"`
+	State         string            `yaml:"state"`
+	Health        string            `yaml:"health,omitempty"`
+	ExitCode      int               `yaml:"exitCode"`
+	Error         string            `yaml:"error,omitempty"`
+	StartedAt     string            `yaml:"startedAt,omitempty"`
+	FinishedAt    string            `yaml:"finishedAt,omitempty"`
+	RestartCount  int               `yaml:"restartCount"`
+	RestartPolicy string            `yaml:"restartPolicy,omitempty"`
+	Created       string            `yaml:"created"`
+	Command       string            `yaml:"command,omitempty"`
+	Ports         []Port            `yaml:"ports,omitempty"`
+	Mounts        []Mount           `yaml:"mounts,omitempty"`
+	Networks      map[string]string `yaml:"networks,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+}
+
+type Mount struct {
+	Type        string `yaml:"type"`
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	ReadOnly    bool   `yaml:"readOnly,omitempty"`
+}
+
+type ContainerActionResult struct {
+	ID       string `yaml:"id"`
+	Name     string `yaml:"name"`
+	Endpoint int64  `yaml:"endpoint"`
+	Action   string `yaml:"action"`
+}
+
+func (c *APIContainer) Name() string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+func (c *APIContainer) ShortID() string {
+	return shortID(c.ID)
+}
+
+func (c *APIContainer) ActionResult(endpointID int64, action string) ContainerActionResult {
+	return ContainerActionResult{
+		ID:       c.ShortID(),
+		Name:     c.Name(),
+		Endpoint: endpointID,
+		Action:   action,
+	}
+}
+
+func (c *APIContainerInspect) ToDetail(endpointID int64) ContainerDetail {
+	d := ContainerDetail{
+		ID:            shortID(c.ID),
+		Name:          strings.TrimPrefix(c.Name, "/"),
+		Image:         c.Config.Image,
+		Endpoint:      endpointID,
+		Stack:         c.Config.Labels["com.docker.compose.project"],
+		State:         c.State.Status,
+		ExitCode:      c.State.ExitCode,
+		Error:         c.State.Error,
+		StartedAt:     dockerTime(c.State.StartedAt),
+		FinishedAt:    dockerTime(c.State.FinishedAt),
+		RestartCount:  c.RestartCount,
+		RestartPolicy: c.HostConfig.RestartPolicy.Name,
+		Created:       dockerTime(c.Created),
+		Command:       strings.TrimSpace(c.Path + " " + strings.Join(c.Args, " ")),
+		Labels:        c.Config.Labels,
+	}
+	if c.State.Health != nil {
+		d.Health = c.State.Health.Status
+	}
+
+	for spec, bindings := range c.NetworkSettings.Ports {
+		port, proto, _ := strings.Cut(spec, "/")
+		p := Port{Protocol: proto}
+		fmt.Sscanf(port, "%d", &p.Container)
+		if len(bindings) == 0 {
+			d.Ports = append(d.Ports, p)
+		}
+		for _, b := range bindings {
+			fmt.Sscanf(b.HostPort, "%d", &p.Host)
+			d.Ports = append(d.Ports, p)
+		}
+	}
+	sort.Slice(d.Ports, func(i, j int) bool {
+		if d.Ports[i].Container != d.Ports[j].Container {
+			return d.Ports[i].Container < d.Ports[j].Container
+		}
+		return d.Ports[i].Protocol < d.Ports[j].Protocol
+	})
+
+	for _, m := range c.Mounts {
+		source := m.Source
+		if m.Type == "volume" && m.Name != "" {
+			source = m.Name
+		}
+		d.Mounts = append(d.Mounts, Mount{Type: m.Type, Source: source, Destination: m.Destination, ReadOnly: !m.RW})
+	}
+
+	if len(c.NetworkSettings.Networks) > 0 {
+		d.Networks = make(map[string]string)
+		for name, n := range c.NetworkSettings.Networks {
+			d.Networks[name] = n.IPAddress
+		}
+	}
+	return d
+}
+
+// shortID is the 12-character form Docker shows
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// dockerTime drops Docker's zero time ("0001-01-01T00:00:00Z") and
+// nanoseconds.
+func dockerTime(s string) string {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil || t.IsZero() || t.Year() <= 1 {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
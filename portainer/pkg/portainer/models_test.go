@@ -1,6 +1,9 @@
 package portainer
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestStackTypeLabel(t *testing.T) {
 	tests := []struct {
@@ -72,6 +75,15 @@ func TestEndpointTypeLabel(t *testing.T) {
 	}
 }
 
+func TestEndpointHasDocker(t *testing.T) {
+	for typeCode, want := range map[int]bool{1: true, 2: true, 3: false, 4: true, 5: false, 99: false} {
+		ep := &APIEndpoint{Type: typeCode}
+		if got := ep.HasDocker(); got != want {
+			t.Errorf("type %d: HasDocker() = %v, want %v", typeCode, got, want)
+		}
+	}
+}
+
 func TestEndpointStatusLabel(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -149,3 +161,41 @@ func TestEndpointToEndpoint(t *testing.T) {
 		t.Errorf("URL = %q, want %q", ep.URL, "tcp://docker:2375")
 	}
 }
+
+func TestContainerInspectToDetail(t *testing.T) {
+	raw := `{
+		"Id": "0123456789abcdef0123",
+		"Name": "/db",
+		"Created": "2024-05-01T10:00:00.123456789Z",
+		"Path": "docker-entrypoint.sh",
+		"Args": ["postgres"],
+		"State": {"Status": "running", "StartedAt": "2024-05-01T10:00:01Z", "FinishedAt": "0001-01-01T00:00:00Z", "Health": {"Status": "healthy"}},
+		"Config": {"Image": "postgres:16", "Labels": {"com.docker.compose.project": "app"}},
+		"Mounts": [{"Type": "volume", "Name": "pgdata", "Source": "/var/lib/docker/volumes/pgdata/_data", "Destination": "/data", "RW": false}],
+		"NetworkSettings": {"Ports": {"5432/tcp": [{"HostPort": "15432"}]}, "Networks": {"app_default": {"IPAddress": "172.18.0.2"}}}
+	}`
+	var inspect APIContainerInspect
+	if err := json.Unmarshal([]byte(raw), &inspect); err != nil {
+		t.Fatal(err)
+	}
+
+	d := inspect.ToDetail(3)
+	if d.ID != "0123456789ab" || d.Name != "db" || d.Stack != "app" || d.Health != "healthy" {
+		t.Errorf("unexpected detail: %+v", d)
+	}
+	if d.Created != "2024-05-01T10:00:00Z" || d.FinishedAt != "" {
+		t.Errorf("times: created %q, finished %q", d.Created, d.FinishedAt)
+	}
+	if d.Command != "docker-entrypoint.sh postgres" {
+		t.Errorf("command = %q", d.Command)
+	}
+	if len(d.Ports) != 1 || d.Ports[0] != (Port{Host: 15432, Container: 5432, Protocol: "tcp"}) {
+		t.Errorf("ports = %+v", d.Ports)
+	}
+	if len(d.Mounts) != 1 || d.Mounts[0].Source != "pgdata" || !d.Mounts[0].ReadOnly {
+		t.Errorf("mounts = %+v", d.Mounts)
+	}
+	if d.Networks["app_default"] != "172.18.0.2" {
+		t.Errorf("networks = %v", d.Networks)
+	}
+}
@@ -0,0 +1,122 @@
+package portainer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Image check statuses
+const (
+	ImageUpToDate = "up-to-date"
+	ImageOutdated = "outdated"
+	ImagePinned   = "pinned" // referenced by digest, so it never changes
+	ImageLocal    = "local"  // no registry digest: built or loaded on the host
+	ImageError    = "error"
+)
+
+type ImageCheck struct {
+	Stack        string `yaml:"stack"`
+	Endpoint     int64  `yaml:"endpoint"`
+	Container    string `yaml:"container"`
+	Image        string `yaml:"image"`
+	Status       string `yaml:"status"`
+	LocalDigest  string `yaml:"localDigest,omitempty"`
+	RemoteDigest string `yaml:"remoteDigest,omitempty"`
+	Error        string `yaml:"error,omitempty"`
+}
+
+// OutdatedReport is shaped like a Watchtower report: counts first, then
+// every image checked, then the stacks that have newer images.
+type OutdatedReport struct {
+	Scanned  int             `yaml:"scanned"`
+	Outdated int             `yaml:"outdated"`
+	Failed   int             `yaml:"failed"`
+	Skipped  int             `yaml:"skipped"`
+	Images   []ImageCheck    `yaml:"images"`
+	Stacks   []OutdatedStack `yaml:"stacks"`
+}
+
+type OutdatedStack struct {
+	Name       string   `yaml:"name"`
+	Endpoint   int64    `yaml:"endpoint"`
+	Containers []string `yaml:"containers"`
+}
+
+func (c *APIContainer) ImageCheck(endpointID int64) ImageCheck {
+	return ImageCheck{
+		Stack:     c.Labels["com.docker.compose.project"],
+		Endpoint:  endpointID,
+		Container: c.Name(),
+		Image:     c.Image,
+	}
+}
+
+// Resolve compares the digest the image was pulled with against the
+// registry's current digest for its tag, looked up with remote.
+func (ic *ImageCheck) Resolve(repoDigests []string, remote func(ImageRef) (string, error)) {
+	if strings.HasPrefix(ic.Image, "sha256:") {
+		ic.Status = ImageLocal
+		return
+	}
+	ref := ParseImageRef(ic.Image)
+	if ref.Digest != "" {
+		ic.Status = ImagePinned
+		ic.LocalDigest = ref.Digest
+		return
+	}
+	ic.LocalDigest = LocalDigest(ref, repoDigests)
+	if ic.LocalDigest == "" {
+		ic.Status = ImageLocal
+		return
+	}
+
+	digest, err := remote(ref)
+	switch {
+	case err != nil:
+		ic.Status = ImageError
+		ic.Error = err.Error()
+	case digest == "":
+		ic.Status = ImageError
+		ic.Error = "registry returned no digest"
+	default:
+		ic.RemoteDigest = digest
+		ic.Status = ImageUpToDate
+		if digest != ic.LocalDigest {
+			ic.Status = ImageOutdated
+		}
+	}
+}
+
+// NewOutdatedReport counts and sorts the checks and groups outdated
+// containers by stack.
+func NewOutdatedReport(checks []ImageCheck) *OutdatedReport {
+	sort.Slice(checks, func(i, j int) bool {
+		a, b := checks[i], checks[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Stack != b.Stack {
+			return a.Stack < b.Stack
+		}
+		return a.Container < b.Container
+	})
+
+	report := &OutdatedReport{Scanned: len(checks), Images: checks, Stacks: []OutdatedStack{}}
+	for _, c := range checks {
+		switch c.Status {
+		case ImageOutdated:
+			report.Outdated++
+			n := len(report.Stacks)
+			if n == 0 || report.Stacks[n-1].Name != c.Stack || report.Stacks[n-1].Endpoint != c.Endpoint {
+				report.Stacks = append(report.Stacks, OutdatedStack{Name: c.Stack, Endpoint: c.Endpoint})
+				n++
+			}
+			report.Stacks[n-1].Containers = append(report.Stacks[n-1].Containers, c.Container)
+		case ImageError:
+			report.Failed++
+		case ImagePinned, ImageLocal:
+			report.Skipped++
+		}
+	}
+	return report
+}
@@ -0,0 +1,61 @@
+package portainer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestImageCheckResolve(t *testing.T) {
+	remote := func(ref ImageRef) (string, error) {
+		switch ref.Repository {
+		case "library/nginx":
+			return "sha256:new", nil
+		case "library/redis":
+			return "sha256:same", nil
+		}
+		return "", errors.New("registry unreachable")
+	}
+
+	tests := []struct {
+		image   string
+		digests []string
+		want    string
+	}{
+		{"nginx:latest", []string{"nginx@sha256:old"}, ImageOutdated},
+		{"redis", []string{"redis@sha256:same"}, ImageUpToDate},
+		{"postgres@sha256:pinned", nil, ImagePinned},
+		{"myapp:dev", nil, ImageLocal},
+		{"sha256:0123abcd", nil, ImageLocal},
+		{"ghcr.io/org/app", []string{"ghcr.io/org/app@sha256:x"}, ImageError},
+	}
+	for _, tt := range tests {
+		check := ImageCheck{Image: tt.image}
+		check.Resolve(tt.digests, remote)
+		if check.Status != tt.want {
+			t.Errorf("%s: status = %q, want %q (%s)", tt.image, check.Status, tt.want, check.Error)
+		}
+	}
+}
+
+func TestNewOutdatedReport(t *testing.T) {
+	report := NewOutdatedReport([]ImageCheck{
+		{Stack: "media", Endpoint: 1, Container: "sonarr", Status: ImageOutdated},
+		{Stack: "app", Endpoint: 1, Container: "web", Status: ImageUpToDate},
+		{Stack: "media", Endpoint: 1, Container: "radarr", Status: ImageOutdated},
+		{Stack: "app", Endpoint: 1, Container: "db", Status: ImagePinned},
+		{Stack: "tools", Endpoint: 2, Container: "ci", Status: ImageError},
+	})
+
+	if report.Scanned != 5 || report.Outdated != 2 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("counts = %d/%d/%d/%d", report.Scanned, report.Outdated, report.Failed, report.Skipped)
+	}
+	if len(report.Stacks) != 1 || report.Stacks[0].Name != "media" {
+		t.Fatalf("stacks = %+v", report.Stacks)
+	}
+	if c := report.Stacks[0].Containers; len(c) != 2 || c[0] != "radarr" || c[1] != "sonarr" {
+		t.Errorf("media containers = %v", c)
+	}
+	if report.Images[0].Container != "db" {
+		t.Errorf("images not sorted: first is %q", report.Images[0].Container)
+	}
+}
@@ -4,18 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	enc := yaml.NewEncoder(os.Stdout)
 	enc.SetIndent(2)
@@ -25,23 +17,270 @@ func PrintYAML(data interface{}) error {
 	return nil
 }
 
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
+// Records starts a stream of records on stdout in the selected format,
+// for output that arrives over time such as events.
+func Records() *common.RecordWriter {
+	return printer.Records(os.Stdout)
+}
+
 func PrintError(err error) {
-	pe, ok := err.(*PortainerError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (StackListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "ENDPOINT", Field: "endpointId"},
+	}
+}
+
+func (Stack) TableColumns() []common.Column {
+	return StackListItem{}.TableColumns()
+}
+
+func (StackDiff) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "ENDPOINT", Field: "endpointId"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "FILE", Field: "file"},
 	}
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(pe.Code),
-			Message: pe.Message,
-		},
+func (Endpoint) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "URL", Field: "url"},
 	}
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", pe.Message)
+func (ContainerListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "IMAGE", Field: "image"},
+		{Header: "STATE", Field: "state"},
+		{Header: "HEALTH", Field: "health"},
+		{Header: "STACK", Field: "stack"},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+		{Header: "PORTS", Field: "ports", Wide: true},
+		{Header: "CREATED", Field: "created", Wide: true},
+	}
+}
+
+func (ContainerStats) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "NAME", Field: "name"},
+		{Header: "CPU %", Field: "cpu"},
+		{Header: "MEM USAGE / LIMIT", Field: "memory"},
+		{Header: "MEM %", Field: "memoryPercent"},
+		{Header: "NET I/O", Field: "netIO"},
+		{Header: "BLOCK I/O", Field: "blockIO"},
+		{Header: "PIDS", Field: "pids"},
+		{Header: "STACK", Field: "stack", Wide: true},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+	}
+}
+
+func (ImageCheck) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "STACK", Field: "stack"},
+		{Header: "CONTAINER", Field: "container"},
+		{Header: "IMAGE", Field: "image"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+		{Header: "LOCAL", Field: "localDigest", Wide: true},
+		{Header: "REMOTE", Field: "remoteDigest", Wide: true},
+		{Header: "ERROR", Field: "error", Wide: true},
+	}
+}
+
+func (Image) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TAGS", Field: "tags"},
+		{Header: "SIZE", Field: "size"},
+		{Header: "CONTAINERS", Field: "containers"},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+		{Header: "CREATED", Field: "created", Wide: true},
+	}
+}
+
+func (Volume) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "NAME", Field: "name"},
+		{Header: "SIZE", Field: "size"},
+		{Header: "CONTAINERS", Field: "containers"},
+		{Header: "STACK", Field: "stack"},
+		{Header: "DRIVER", Field: "driver", Wide: true},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+		{Header: "CREATED", Field: "created", Wide: true},
+	}
+}
+
+func (Network) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "DRIVER", Field: "driver"},
+		{Header: "SUBNET", Field: "subnet"},
+		{Header: "STACK", Field: "stack"},
+		{Header: "CONTAINERS", Field: "containers", Wide: true},
+		{Header: "SCOPE", Field: "scope", Wide: true},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+	}
+}
+
+func (User) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "USERNAME", Field: "username"},
+		{Header: "ROLE", Field: "role"},
+		{Header: "TEAMS", Field: "teams"},
+	}
+}
+
+func (Team) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "LEADERS", Field: "leaders"},
+		{Header: "MEMBERS", Field: "members"},
+	}
+}
+
+func (EndpointAccess) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "ENDPOINT", Field: "name"},
+		{Header: "USERS", Field: "users"},
+		{Header: "TEAMS", Field: "teams"},
+		{Header: "GROUP", Field: "group", Wide: true},
+	}
+}
+
+func (StackAccess) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "STACK", Field: "name"},
+		{Header: "ACCESS", Field: "access"},
+		{Header: "USERS", Field: "users"},
+		{Header: "TEAMS", Field: "teams"},
+		{Header: "ENDPOINT", Field: "endpoint", Wide: true},
+	}
+}
+
+func (AuditEntry) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "USERNAME", Field: "username"},
+		{Header: "ROLE", Field: "role"},
+		{Header: "TEAMS", Field: "teams"},
+		{Header: "FLAGS", Field: "flags"},
+		{Header: "ENDPOINTS", Field: "endpoints", Wide: true},
+	}
+}
+
+func (Registry) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "URL", Field: "url"},
+		{Header: "AUTH", Field: "authentication"},
+		{Header: "USERNAME", Field: "username", Wide: true},
+	}
+}
+
+func (TemplateListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "SOURCE", Field: "source"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "CATEGORIES", Field: "categories", Wide: true},
+	}
+}
+
+func (EdgeGroup) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "ENDPOINTS", Field: "endpoints"},
+		{Header: "STACKS", Field: "stacks", Wide: true},
+	}
+}
+
+func (EdgeStackListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "GROUPS", Field: "groups"},
+		{Header: "ENDPOINTS", Field: "endpoints"},
+		{Header: "DEPLOYED", Field: "deployed"},
+		{Header: "PENDING", Field: "pending"},
+		{Header: "FAILED", Field: "failed"},
+		{Header: "VERSION", Field: "version", Wide: true},
+	}
+}
+
+func (EdgeEndpointStatus) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "STACK", Field: "stack"},
+		{Header: "ENDPOINT", Field: "endpoint"},
+		{Header: "OUTCOME", Field: "outcome"},
+		{Header: "ERROR", Field: "error"},
+		{Header: "STATUS", Field: "status", Wide: true},
+		{Header: "OUTDATED", Field: "outdated", Wide: true},
+		{Header: "UPDATED", Field: "updated", Wide: true},
+	}
+}
+
+func (Event) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TIME", Field: "time"},
+		{Header: "ENDPOINT", Field: "endpointId"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "ACTION", Field: "action"},
+		{Header: "NAME", Field: "name"},
+		{Header: "ID", Field: "id", Wide: true},
+		{Header: "IMAGE", Field: "image", Wide: true},
+		{Header: "EXIT", Field: "exitCode", Wide: true},
+	}
+}
+
+func (StackExport) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ENDPOINT", Field: "endpoint"},
+		{Header: "STACK", Field: "name"},
+		{Header: "PATH", Field: "path"},
+		{Header: "REDACTED", Field: "redacted"},
+		{Header: "GIT", Field: "git.url", Wide: true},
 	}
 }
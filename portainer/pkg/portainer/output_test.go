@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
 func TestPrintYAMLFormat(t *testing.T) {
@@ -64,3 +66,30 @@ func TestPrintErrorFormat(t *testing.T) {
 		t.Errorf("output missing message, got: %s", output)
 	}
 }
+
+func TestContainerListTableColumns(t *testing.T) {
+	p, err := common.NewPrinter("table")
+	if err != nil {
+		t.Fatalf("NewPrinter: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := ContainerList{{ID: "abc123", Name: "web", Image: "nginx", State: "running", Health: "healthy", Stack: "app", Endpoint: 1}}
+	if err := p.Print(&buf, data); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+
+	header := strings.Fields(strings.Split(buf.String(), "\n")[0])
+	want := []string{"ID", "NAME", "IMAGE", "STATE", "HEALTH", "STACK"}
+	if strings.Join(header, " ") != strings.Join(want, " ") {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+}
+
+func TestSetOutputFormatRejectsUnknown(t *testing.T) {
+	err := SetOutputFormat("xml")
+	pe, ok := err.(*PortainerError)
+	if !ok || pe.Code != ErrConfig {
+		t.Errorf("SetOutputFormat(xml) = %v, want CONFIG_ERROR", err)
+	}
+}
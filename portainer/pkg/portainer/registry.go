@@ -0,0 +1,386 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+	registryTimeout   = 10 * time.Second
+)
+
+// manifestTypes are accepted when asking for a tag's digest. Multi-arch
+// images resolve to their index, which is what `docker pull` records in
+// RepoDigests.
+var manifestTypes = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// ImageRef is a parsed image reference such as "ghcr.io/org/app:1.2".
+type ImageRef struct {
+	Registry   string // registry host, registry-1.docker.io for Docker Hub
+	Repository string // library/ prefixed for official Docker Hub images
+	Tag        string
+	Digest     string // set when the reference is pinned with @sha256:...
+}
+
+// ParseImageRef normalises an image reference the way Docker does: no
+// registry means Docker Hub, a single name component means library/, and
+// no tag means latest.
+func ParseImageRef(s string) ImageRef {
+	var ref ImageRef
+	s, ref.Digest, _ = strings.Cut(s, "@")
+
+	name := s
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		name, ref.Tag = s[:i], s[i+1:]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, ref.Repository = first, rest
+	} else {
+		ref.Registry, ref.Repository = dockerHubRegistry, name
+	}
+	switch ref.Registry {
+	case "docker.io", "index.docker.io":
+		ref.Registry = dockerHubRegistry
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	return ref
+}
+
+// Name is the registry and repository without tag or digest.
+func (r ImageRef) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+func (r ImageRef) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// LocalDigest finds the digest recorded for ref's repository among an
+// image's RepoDigests, or "" if the image was never pulled from it.
+func LocalDigest(ref ImageRef, repoDigests []string) string {
+	for _, rd := range repoDigests {
+		local := ParseImageRef(rd)
+		if local.Name() == ref.Name() && local.Digest != "" {
+			return local.Digest
+		}
+	}
+	return ""
+}
+
+// MatchRegistry finds the Portainer registry configured for the host of
+// ref, if any. Docker Hub registries match Docker Hub images.
+func MatchRegistry(ref ImageRef, registries []APIRegistry) *APIRegistry {
+	for i := range registries {
+		if r := &registries[i]; r.Host() == ref.Registry {
+			return r
+		}
+	}
+	return nil
+}
+
+// Host is the registry's host as it appears in image references.
+func (r *APIRegistry) Host() string {
+	if r.Type == RegistryDockerHub {
+		return dockerHubRegistry
+	}
+	return registryHost(r.URL)
+}
+
+// ImageRef parses an image on this registry. An image without a registry
+// host is taken to be on it, so "org/app" works for GHCR as well.
+func (r *APIRegistry) ImageRef(image string) (ImageRef, error) {
+	ref := ParseImageRef(image)
+	if ref.Registry == r.Host() {
+		return ref, nil
+	}
+	if ref.Registry != dockerHubRegistry || strings.HasPrefix(image, "docker.io/") {
+		return ref, ConfigError(fmt.Sprintf("%s is not on %s", image, r.Host()))
+	}
+	return ParseImageRef(r.Host() + "/" + image), nil
+}
+
+func registryHost(rawURL string) string {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	switch u.Host {
+	case "docker.io", "index.docker.io":
+		return dockerHubRegistry
+	}
+	return u.Host
+}
+
+// RemoteDigest asks the registry for the current digest of ref's tag.
+// When Portainer holds credentials for the registry the request goes
+// through Portainer's registry proxy, which applies them, so passwords
+// never leave the server. Other registries are queried directly with an
+// anonymous token, as docker pull would for a public image.
+func (c *Client) RemoteDigest(ref ImageRef, registries []APIRegistry) (string, error) {
+	manifest := fmt.Sprintf("/v2/%s/manifests/%s", ref.Repository, ref.Tag)
+	if r := MatchRegistry(ref, registries); r != nil && r.Authentication {
+		header, err := c.api.Head(fmt.Sprintf("/api/registries/%d%s", r.ID, manifest), http.Header{"Accept": {manifestTypes}})
+		if err == nil {
+			return header.Get("Docker-Content-Digest"), nil
+		}
+		// Portainer versions without the registry proxy answer 404, as does
+		// a missing tag; either way the direct query gives the real answer
+		if e, ok := err.(*PortainerError); !ok || e.Code != ErrNotFound {
+			return "", err
+		}
+	}
+	return registryDigest(&http.Client{Timeout: registryTimeout}, ref)
+}
+
+// PingRegistry checks that a registry answers. When Portainer holds
+// credentials for it the check goes through Portainer's registry proxy,
+// so it also proves the credentials are accepted.
+func (c *Client) PingRegistry(r *APIRegistry) error {
+	if r.Authentication {
+		_, err := c.api.Head(fmt.Sprintf("/api/registries/%d/v2/", r.ID), nil)
+		if e, ok := err.(*PortainerError); !ok || e.Code != ErrNotFound {
+			return err
+		}
+	}
+	return registryPing(&http.Client{Timeout: registryTimeout}, r.Host())
+}
+
+// registryPing calls the /v2/ base endpoint, which answers 200, or 401
+// when the registry needs a token; either means it is up.
+func registryPing(hc *http.Client, host string) error {
+	resp, err := hc.Get(fmt.Sprintf("%s://%s/v2/", registryScheme(host), host))
+	if err != nil {
+		return NetworkError(fmt.Sprintf("registry %s: %s", host, err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return APIError(fmt.Sprintf("registry %s returned %s", host, resp.Status))
+	}
+	return nil
+}
+
+// registryDigest does a HEAD on the manifest, following the registry's
+// bearer token challenge once.
+func registryDigest(hc *http.Client, ref ImageRef) (string, error) {
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", registryScheme(ref.Registry), ref.Registry, ref.Repository, ref.Tag)
+
+	token := ""
+	for try := 0; try < 2; try++ {
+		req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+		if err != nil {
+			return "", NetworkError(err.Error())
+		}
+		req.Header.Set("Accept", manifestTypes)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := hc.Do(req)
+		if err != nil {
+			return "", NetworkError(fmt.Sprintf("registry %s: %s", ref.Registry, err))
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			return resp.Header.Get("Docker-Content-Digest"), nil
+		case resp.StatusCode == http.StatusUnauthorized && token == "":
+			token, err = registryToken(hc, resp.Header.Get("WWW-Authenticate"))
+			if err != nil {
+				return "", err
+			}
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return "", AuthError(fmt.Sprintf("registry %s denied access to %s; add credentials in Portainer", ref.Registry, ref.Repository))
+		case resp.StatusCode == http.StatusNotFound:
+			return "", NotFoundError(fmt.Sprintf("%s:%s not found in registry", ref.Name(), ref.Tag))
+		default:
+			return "", APIError(fmt.Sprintf("registry %s returned %s", ref.Registry, resp.Status))
+		}
+	}
+	return "", AuthError(fmt.Sprintf("registry %s rejected the anonymous token", ref.Registry))
+}
+
+// registryToken answers a `Bearer realm="...",service="...",scope="..."`
+// challenge with an anonymous token request.
+func registryToken(hc *http.Client, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", AuthError(fmt.Sprintf("unsupported registry auth challenge %q", challenge))
+	}
+	fields := map[string]string{}
+	for _, part := range splitChallenge(params) {
+		k, v, _ := strings.Cut(part, "=")
+		fields[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+	realm := fields["realm"]
+	if realm == "" {
+		return "", AuthError(fmt.Sprintf("registry auth challenge has no realm: %q", challenge))
+	}
+
+	q := url.Values{}
+	if fields["service"] != "" {
+		q.Set("service", fields["service"])
+	}
+	if fields["scope"] != "" {
+		q.Set("scope", fields["scope"])
+	}
+	resp, err := hc.Get(realm + "?" + q.Encode())
+	if err != nil {
+		return "", NetworkError(fmt.Sprintf("registry token: %s", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", AuthError(fmt.Sprintf("registry token request returned %s", resp.Status))
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", APIError(fmt.Sprintf("failed to parse registry token: %s", err))
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// splitChallenge splits on commas outside quotes; scopes contain commas
+// ("repository:app:pull,push").
+func splitChallenge(s string) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// registryScheme is plain http for loopback registries, which Docker
+// also allows without TLS.
+func registryScheme(host string) string {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		h = host
+	}
+	if h == "localhost" {
+		return "http"
+	}
+	if ip := net.ParseIP(h); ip != nil && ip.IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
+
+var registryTypes = map[int]string{
+	RegistryQuay:      "quay",
+	RegistryAzure:     "azure",
+	RegistryCustom:    "custom",
+	RegistryGitlab:    "gitlab",
+	RegistryProGet:    "proget",
+	RegistryDockerHub: "dockerhub",
+	RegistryECR:       "ecr",
+	RegistryGithub:    "ghcr",
+}
+
+// registryDefaultURLs are used when add is given no --url
+var registryDefaultURLs = map[int]string{
+	RegistryDockerHub: "docker.io",
+	RegistryGithub:    "ghcr.io",
+	RegistryQuay:      "quay.io",
+}
+
+func RegistryTypeLabel(t int) string {
+	if label, ok := registryTypes[t]; ok {
+		return label
+	}
+	return "unknown"
+}
+
+// ParseRegistryType accepts the labels from RegistryTypeLabel.
+func ParseRegistryType(s string) (int, error) {
+	for t, label := range registryTypes {
+		if strings.EqualFold(s, label) {
+			return t, nil
+		}
+	}
+	return 0, ConfigError(fmt.Sprintf("invalid registry type %q (dockerhub|ghcr|quay|gitlab|azure|ecr|proget|custom)", s))
+}
+
+// NewRegistryRequest builds the request to add a registry, filling in the
+// well-known URL of hosted registries.
+func NewRegistryRequest(name string, registryType int, url, username, password string) (*APIRegistryCreateRequest, error) {
+	if url == "" {
+		url = registryDefaultURLs[registryType]
+	}
+	if url == "" {
+		return nil, ConfigError(fmt.Sprintf("--url is required for %s registries", RegistryTypeLabel(registryType)))
+	}
+	return &APIRegistryCreateRequest{
+		Name:           name,
+		Type:           registryType,
+		URL:            url,
+		Authentication: username != "",
+		Username:       username,
+		Password:       password,
+	}, nil
+}
+
+func (r *APIRegistry) ToRegistry() Registry {
+	return Registry{
+		ID:             r.ID,
+		Name:           r.Name,
+		Type:           RegistryTypeLabel(r.Type),
+		URL:            r.URL,
+		Authentication: r.Authentication,
+		Username:       r.Username,
+	}
+}
+
+// FindRegistry looks a registry up by name or ID.
+func FindRegistry(registries []APIRegistry, ref string) (*APIRegistry, error) {
+	for i := range registries {
+		if registries[i].Name == ref {
+			return &registries[i], nil
+		}
+	}
+	for i := range registries {
+		if fmt.Sprint(registries[i].ID) == ref {
+			return &registries[i], nil
+		}
+	}
+	return nil, NotFoundError(fmt.Sprintf("registry %q", ref))
+}
@@ -0,0 +1,205 @@
+package portainer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ImageRef
+	}{
+		{"nginx", ImageRef{Registry: "registry-1.docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"nginx:1.25", ImageRef{Registry: "registry-1.docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"docker.io/grafana/grafana:10", ImageRef{Registry: "registry-1.docker.io", Repository: "grafana/grafana", Tag: "10"}},
+		{"ghcr.io/org/app", ImageRef{Registry: "ghcr.io", Repository: "org/app", Tag: "latest"}},
+		{"registry.lan:5000/team/app:v2", ImageRef{Registry: "registry.lan:5000", Repository: "team/app", Tag: "v2"}},
+		{"localhost/app:dev", ImageRef{Registry: "localhost", Repository: "app", Tag: "dev"}},
+		{"redis@sha256:abc", ImageRef{Registry: "registry-1.docker.io", Repository: "library/redis", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		if got := ParseImageRef(tt.in); got != tt.want {
+			t.Errorf("ParseImageRef(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLocalDigest(t *testing.T) {
+	digests := []string{"ghcr.io/org/app@sha256:111", "nginx@sha256:222"}
+	if got := LocalDigest(ParseImageRef("nginx:latest"), digests); got != "sha256:222" {
+		t.Errorf("nginx digest = %q", got)
+	}
+	if got := LocalDigest(ParseImageRef("docker.io/library/nginx"), digests); got != "sha256:222" {
+		t.Errorf("docker.io/library/nginx digest = %q", got)
+	}
+	if got := LocalDigest(ParseImageRef("ghcr.io/org/other"), digests); got != "" {
+		t.Errorf("unrelated repo digest = %q, want empty", got)
+	}
+}
+
+func TestMatchRegistry(t *testing.T) {
+	registries := []APIRegistry{
+		{ID: 1, Type: RegistryDockerHub, URL: "docker.io"},
+		{ID: 2, Type: RegistryCustom, URL: "https://registry.lan:5000"},
+	}
+	if r := MatchRegistry(ParseImageRef("nginx"), registries); r == nil || r.ID != 1 {
+		t.Errorf("nginx matched %+v, want Docker Hub", r)
+	}
+	if r := MatchRegistry(ParseImageRef("registry.lan:5000/app"), registries); r == nil || r.ID != 2 {
+		t.Errorf("registry.lan matched %+v, want 2", r)
+	}
+	if r := MatchRegistry(ParseImageRef("ghcr.io/org/app"), registries); r != nil {
+		t.Errorf("ghcr.io matched %+v, want none", r)
+	}
+}
+
+// registryStandIn serves one manifest behind an anonymous bearer token.
+func registryStandIn(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:team/app:pull" {
+				t.Errorf("token scope = %q", r.URL.Query().Get("scope"))
+			}
+			w.Write([]byte(`{"token":"anon"}`))
+		case "/v2/team/app/manifests/v2":
+			if r.Header.Get("Authorization") != "Bearer anon" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="stand-in",scope="repository:team/app:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json") {
+				t.Errorf("Accept = %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:new")
+		case "/v2/":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestRemoteDigestDirect(t *testing.T) {
+	registry := registryStandIn(t)
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	client := NewClient("http://127.0.0.1:1", "token", false)
+	digest, err := client.RemoteDigest(ParseImageRef(host+"/team/app:v2"), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:new" {
+		t.Errorf("digest = %q", digest)
+	}
+
+	_, err = client.RemoteDigest(ParseImageRef(host+"/team/missing:v2"), nil)
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrNotFound {
+		t.Errorf("missing tag: err = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestRemoteDigestThroughPortainer(t *testing.T) {
+	var got string
+	portainer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Method + " " + r.URL.Path
+		if r.Header.Get("X-API-Key") != "token" {
+			t.Error("missing api key")
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:private")
+	}))
+	defer portainer.Close()
+
+	client := NewClient(portainer.URL, "token", false)
+	registries := []APIRegistry{{ID: 4, Type: RegistryCustom, URL: "registry.lan", Authentication: true}}
+	digest, err := client.RemoteDigest(ParseImageRef("registry.lan/team/app:v2"), registries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:private" {
+		t.Errorf("digest = %q", digest)
+	}
+	if got != "HEAD /api/registries/4/v2/team/app/manifests/v2" {
+		t.Errorf("request = %q", got)
+	}
+}
+
+func TestPingRegistry(t *testing.T) {
+	registry := registryStandIn(t)
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "http://")
+
+	var got []string
+	portainer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/api/registries/5/v2/" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer portainer.Close()
+	client := NewClient(portainer.URL, "token", false)
+
+	if err := client.PingRegistry(&APIRegistry{ID: 3, Type: RegistryCustom, URL: host}); err != nil {
+		t.Errorf("anonymous ping: %v", err)
+	}
+	if err := client.PingRegistry(&APIRegistry{ID: 4, Type: RegistryCustom, URL: host, Authentication: true}); err != nil {
+		t.Errorf("ping through Portainer: %v", err)
+	}
+	err := client.PingRegistry(&APIRegistry{ID: 5, Type: RegistryCustom, URL: host, Authentication: true})
+	if e, ok := err.(*PortainerError); !ok || e.Code != ErrAuth {
+		t.Errorf("rejected credentials: err = %v, want AUTH", err)
+	}
+	if len(got) != 2 || got[0] != "HEAD /api/registries/4/v2/" {
+		t.Errorf("portainer requests = %v", got)
+	}
+}
+
+func TestNewRegistryRequest(t *testing.T) {
+	typ, err := ParseRegistryType("GHCR")
+	if err != nil || typ != RegistryGithub {
+		t.Fatalf("ParseRegistryType(GHCR) = %d, %v", typ, err)
+	}
+	req, err := NewRegistryRequest("github", typ, "", "bot", "pat")
+	if err != nil || req.URL != "ghcr.io" || !req.Authentication {
+		t.Errorf("request = %+v, err = %v", req, err)
+	}
+	if _, err := NewRegistryRequest("lan", RegistryCustom, "", "", ""); err == nil {
+		t.Error("custom registry without --url should fail")
+	}
+	if _, err := ParseRegistryType("harbor"); err == nil {
+		t.Error("unknown type should fail")
+	}
+}
+
+func TestRegistryImageRef(t *testing.T) {
+	ghcr := &APIRegistry{Type: RegistryGithub, URL: "ghcr.io"}
+	hub := &APIRegistry{Type: RegistryDockerHub, URL: "docker.io"}
+	for _, tc := range []struct {
+		registry *APIRegistry
+		image    string
+		want     string
+	}{
+		{ghcr, "schmoli/app", "ghcr.io/schmoli/app:latest"},
+		{ghcr, "ghcr.io/schmoli/app:1.2", "ghcr.io/schmoli/app:1.2"},
+		{hub, "nginx", "registry-1.docker.io/library/nginx:latest"},
+		{ghcr, "quay.io/org/app", ""},
+		{ghcr, "docker.io/org/app", ""},
+	} {
+		ref, err := tc.registry.ImageRef(tc.image)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tc.image, ref)
+			}
+			continue
+		}
+		if err != nil || ref.String() != tc.want {
+			t.Errorf("%s = %s, %v; want %s", tc.image, ref, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package portainer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Docker stats response (GET /containers/{id}/stats?stream=false), trimmed
+// to what ContainerStats shows. precpu_stats is the sample before cpu_stats.
+type APIContainerStats struct {
+	Name        string      `json:"name"`
+	CPUStats    APICPUStats `json:"cpu_stats"`
+	PreCPUStats APICPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IOServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	PidsStats struct {
+		Current int `json:"current"`
+	} `json:"pids_stats"`
+}
+
+type APICPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemCPUUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs     int    `json:"online_cpus"`
+}
+
+// Usage is a container's resource use computed from one stats sample.
+type Usage struct {
+	CPUPercent    float64
+	Memory        uint64 // excluding reclaimable page cache
+	MemoryLimit   uint64
+	MemoryPercent float64
+	NetRx, NetTx  uint64
+	BlockRead     uint64
+	BlockWrite    uint64
+	PIDs          int
+}
+
+// Usage computes CPU%, memory and IO the way `docker stats` does.
+func (s *APIContainerStats) Usage() Usage {
+	u := Usage{MemoryLimit: s.MemoryStats.Limit, PIDs: s.PidsStats.Current}
+
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemCPUUsage) - float64(s.PreCPUStats.SystemCPUUsage)
+	cpus := s.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(s.CPUStats.CPUUsage.PercpuUsage)
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		u.CPUPercent = cpuDelta / systemDelta * float64(cpus) * 100
+	}
+
+	// Page cache can be reclaimed, so it isn't counted: cgroup v1 reports
+	// total_inactive_file, v2 inactive_file
+	u.Memory = s.MemoryStats.Usage
+	cache, ok := s.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = s.MemoryStats.Stats["inactive_file"]
+	}
+	if cache < u.Memory {
+		u.Memory -= cache
+	}
+	if u.MemoryLimit > 0 {
+		u.MemoryPercent = float64(u.Memory) / float64(u.MemoryLimit) * 100
+	}
+
+	for _, n := range s.Networks {
+		u.NetRx += n.RxBytes
+		u.NetTx += n.TxBytes
+	}
+	for _, entry := range s.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			u.BlockRead += entry.Value
+		case "write":
+			u.BlockWrite += entry.Value
+		}
+	}
+	return u
+}
+
+// Output types
+
+type ContainerStats struct {
+	Name          string `yaml:"name"`
+	Endpoint      int64  `yaml:"endpoint"`
+	Stack         string `yaml:"stack"`
+	CPU           string `yaml:"cpu"`
+	Memory        string `yaml:"memory"`
+	MemoryPercent string `yaml:"memoryPercent"`
+	NetIO         string `yaml:"netIO"`
+	BlockIO       string `yaml:"blockIO"`
+	PIDs          int    `yaml:"pids"`
+
+	usage Usage
+}
+
+type ContainerStatsList []ContainerStats
+
+func (c *APIContainer) ToStats(endpointID int64, u Usage) ContainerStats {
+	return ContainerStats{
+		Name:          c.Name(),
+		Endpoint:      endpointID,
+		Stack:         c.Labels["com.docker.compose.project"],
+		CPU:           fmt.Sprintf("%.1f%%", u.CPUPercent),
+		Memory:        formatBytes(u.Memory) + " / " + formatBytes(u.MemoryLimit),
+		MemoryPercent: fmt.Sprintf("%.1f%%", u.MemoryPercent),
+		NetIO:         formatBytes(u.NetRx) + " / " + formatBytes(u.NetTx),
+		BlockIO:       formatBytes(u.BlockRead) + " / " + formatBytes(u.BlockWrite),
+		PIDs:          u.PIDs,
+		usage:         u,
+	}
+}
+
+// Sort orders the list by cpu or memory use, highest first, then by name.
+func (l ContainerStatsList) Sort(by string) {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].usage, l[j].usage
+		switch {
+		case by == "memory" && a.Memory != b.Memory:
+			return a.Memory > b.Memory
+		case by == "cpu" && a.CPUPercent != b.CPUPercent:
+			return a.CPUPercent > b.CPUPercent
+		}
+		return l[i].Name < l[j].Name
+	})
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
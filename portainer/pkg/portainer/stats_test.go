@@ -0,0 +1,78 @@
+package portainer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const statsSample = `{
+  "name": "/web",
+  "cpu_stats": {"cpu_usage": {"total_usage": 400000000}, "system_cpu_usage": 20000000000, "online_cpus": 4},
+  "precpu_stats": {"cpu_usage": {"total_usage": 200000000}, "system_cpu_usage": 18000000000},
+  "memory_stats": {"usage": 314572800, "limit": 1073741824, "stats": {"inactive_file": 104857600}},
+  "networks": {"eth0": {"rx_bytes": 1024, "tx_bytes": 2048}, "eth1": {"rx_bytes": 1024, "tx_bytes": 0}},
+  "blkio_stats": {"io_service_bytes_recursive": [
+    {"op": "read", "value": 4096}, {"op": "write", "value": 8192}, {"op": "Read", "value": 4096}
+  ]},
+  "pids_stats": {"current": 7}
+}`
+
+func TestStatsUsage(t *testing.T) {
+	var stats APIContainerStats
+	if err := json.Unmarshal([]byte(statsSample), &stats); err != nil {
+		t.Fatal(err)
+	}
+	u := stats.Usage()
+
+	// 0.2s of CPU over 2s of system time on 4 CPUs
+	if u.CPUPercent < 39.99 || u.CPUPercent > 40.01 {
+		t.Errorf("CPUPercent = %f, want 40", u.CPUPercent)
+	}
+	if u.Memory != 200<<20 {
+		t.Errorf("Memory = %d, want %d (usage minus inactive_file)", u.Memory, 200<<20)
+	}
+	if u.NetRx != 2048 || u.NetTx != 2048 {
+		t.Errorf("net = %d/%d", u.NetRx, u.NetTx)
+	}
+	if u.BlockRead != 8192 || u.BlockWrite != 8192 {
+		t.Errorf("block = %d/%d", u.BlockRead, u.BlockWrite)
+	}
+
+	c := APIContainer{Names: []string{"/web"}, Labels: map[string]string{"com.docker.compose.project": "app"}}
+	out := c.ToStats(1, u)
+	if out.CPU != "40.0%" || out.Memory != "200.0 MB / 1.0 GB" || out.MemoryPercent != "19.5%" || out.Stack != "app" || out.PIDs != 7 {
+		t.Errorf("ToStats = %+v", out)
+	}
+}
+
+func TestStatsUsageCgroupV1AndFirstSample(t *testing.T) {
+	var stats APIContainerStats
+	stats.MemoryStats.Usage = 300
+	stats.MemoryStats.Stats = map[string]uint64{"total_inactive_file": 100, "inactive_file": 50}
+	stats.CPUStats.CPUUsage.TotalUsage = 100
+	stats.CPUStats.CPUUsage.PercpuUsage = []uint64{50, 50}
+
+	u := stats.Usage()
+	if u.Memory != 200 {
+		t.Errorf("Memory = %d, want 200 (usage minus total_inactive_file)", u.Memory)
+	}
+	if u.CPUPercent != 0 {
+		t.Errorf("CPUPercent = %f, want 0 without a system delta", u.CPUPercent)
+	}
+}
+
+func TestStatsSort(t *testing.T) {
+	list := ContainerStatsList{
+		{Name: "b", usage: Usage{CPUPercent: 1, Memory: 300}},
+		{Name: "a", usage: Usage{CPUPercent: 5, Memory: 100}},
+		{Name: "c", usage: Usage{CPUPercent: 1, Memory: 200}},
+	}
+	list.Sort("cpu")
+	if list[0].Name != "a" || list[1].Name != "b" || list[2].Name != "c" {
+		t.Errorf("by cpu: %s %s %s", list[0].Name, list[1].Name, list[2].Name)
+	}
+	list.Sort("memory")
+	if list[0].Name != "b" || list[1].Name != "c" || list[2].Name != "a" {
+		t.Errorf("by memory: %s %s %s", list[0].Name, list[1].Name, list[2].Name)
+	}
+}
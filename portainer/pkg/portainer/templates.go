@@ -0,0 +1,293 @@
+package portainer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// App template types
+const (
+	TemplateContainer    = 1
+	TemplateSwarmStack   = 2
+	TemplateComposeStack = 3
+)
+
+// Custom template types, which follow stack types
+const (
+	CustomTemplateSwarm      = 1
+	CustomTemplateCompose    = 2
+	CustomTemplateKubernetes = 3
+)
+
+// Template sources
+const (
+	SourceApp    = "app"
+	SourceCustom = "custom"
+)
+
+// APITemplate is an app template (format version 2 or 3)
+type APITemplate struct {
+	ID          int64            `json:"id"`
+	Type        int              `json:"type"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Categories  []string         `json:"categories"`
+	Image       string           `json:"image"`
+	Command     string           `json:"command"`
+	Hostname    string           `json:"hostname"`
+	Privileged  bool             `json:"privileged"`
+	Restart     string           `json:"restart_policy"`
+	Ports       []string         `json:"ports"`
+	Env         []APITemplateEnv `json:"env"`
+	Volumes     []struct {
+		Container string `json:"container"`
+		Bind      string `json:"bind"`
+		ReadOnly  bool   `json:"readonly"`
+	} `json:"volumes"`
+	Labels []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"labels"`
+	Repository struct {
+		URL       string `json:"url"`
+		StackFile string `json:"stackfile"`
+	} `json:"repository"`
+}
+
+// APITemplateEnv is a variable the template asks for. Preset variables
+// are fixed by the template; Select offers a list of values.
+type APITemplateEnv struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Default string `json:"default"`
+	Preset  bool   `json:"preset"`
+	Select  []struct {
+		Value   string `json:"value"`
+		Default bool   `json:"default"`
+	} `json:"select"`
+}
+
+// APICustomTemplate is a template saved in Portainer. Its file may use
+// {{ name }} placeholders for Variables.
+type APICustomTemplate struct {
+	ID          int64  `json:"Id"`
+	Title       string `json:"Title"`
+	Description string `json:"Description"`
+	Type        int    `json:"Type"`
+	Variables   []struct {
+		Name         string `json:"name"`
+		Label        string `json:"label"`
+		DefaultValue string `json:"defaultValue"`
+	} `json:"Variables"`
+}
+
+// Output types
+
+type TemplateListItem struct {
+	ID          int64    `yaml:"id"`
+	Source      string   `yaml:"source"`
+	Type        string   `yaml:"type"`
+	Title       string   `yaml:"title"`
+	Categories  []string `yaml:"categories,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+func (t *APITemplate) TypeLabel() string {
+	switch t.Type {
+	case TemplateContainer:
+		return "container"
+	case TemplateSwarmStack:
+		return "swarm"
+	case TemplateComposeStack:
+		return "compose"
+	default:
+		return "unknown"
+	}
+}
+
+func (t *APITemplate) ToListItem() TemplateListItem {
+	return TemplateListItem{ID: t.ID, Source: SourceApp, Type: t.TypeLabel(), Title: t.Title, Categories: t.Categories, Description: t.Description}
+}
+
+func (t *APICustomTemplate) TypeLabel() string {
+	switch t.Type {
+	case CustomTemplateSwarm:
+		return "swarm"
+	case CustomTemplateCompose:
+		return "compose"
+	case CustomTemplateKubernetes:
+		return "kubernetes"
+	default:
+		return "unknown"
+	}
+}
+
+func (t *APICustomTemplate) ToListItem() TemplateListItem {
+	return TemplateListItem{ID: t.ID, Source: SourceCustom, Type: t.TypeLabel(), Title: t.Title, Description: t.Description}
+}
+
+var stackNameInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// StackName turns a template title into a valid compose project name,
+// e.g. "Home Assistant" into "home-assistant".
+func StackName(title string) string {
+	return strings.Trim(stackNameInvalid.ReplaceAllString(strings.ToLower(title), "-"), "-_")
+}
+
+// ResolveTemplateEnv fills a template's variables from values, falling
+// back to their defaults, and adds any other values as extra env vars.
+// Variables with neither are reported together.
+func ResolveTemplateEnv(vars []APITemplateEnv, values map[string]string) ([]APIEnvVar, error) {
+	var env []APIEnvVar
+	var missing []string
+	seen := map[string]bool{}
+	for _, v := range vars {
+		seen[v.Name] = true
+		value, ok := values[v.Name]
+		if !ok || v.Preset {
+			value, ok = v.Default, v.Default != "" || v.Preset
+			for _, s := range v.Select {
+				if s.Default {
+					value, ok = s.Value, true
+				}
+			}
+		}
+		if !ok {
+			label := v.Name
+			if v.Label != "" {
+				label += " (" + v.Label + ")"
+			}
+			missing = append(missing, label)
+			continue
+		}
+		env = append(env, APIEnvVar{Name: v.Name, Value: value})
+	}
+	if len(missing) > 0 {
+		err := ConfigError("template needs values; set them with --env NAME=VALUE")
+		err.Details = missing
+		return nil, err
+	}
+	return append(env, extraEnv(values, seen)...), nil
+}
+
+func extraEnv(values map[string]string, seen map[string]bool) []APIEnvVar {
+	var extra []APIEnvVar
+	for name, value := range values {
+		if !seen[name] {
+			extra = append(extra, APIEnvVar{Name: name, Value: value})
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Name < extra[j].Name })
+	return extra
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// RenderCustomTemplate substitutes {{ name }} placeholders in a custom
+// template's file the way Portainer's UI does. Values that are not
+// template variables are returned as stack env vars.
+func RenderCustomTemplate(t *APICustomTemplate, content string, values map[string]string) (string, []APIEnvVar, error) {
+	vars := map[string]string{}
+	seen := map[string]bool{}
+	var missing []string
+	for _, v := range t.Variables {
+		seen[v.Name] = true
+		value, ok := values[v.Name]
+		if !ok {
+			value, ok = v.DefaultValue, v.DefaultValue != ""
+		}
+		if !ok {
+			missing = append(missing, v.Name)
+			continue
+		}
+		vars[v.Name] = value
+	}
+	if len(missing) > 0 {
+		err := ConfigError("template needs values; set them with --env NAME=VALUE")
+		err.Details = missing
+		return "", nil, err
+	}
+
+	rendered := placeholder.ReplaceAllStringFunc(content, func(m string) string {
+		if value, ok := vars[placeholder.FindStringSubmatch(m)[1]]; ok {
+			return value
+		}
+		return m
+	})
+	return rendered, extraEnv(values, seen), nil
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+	Volumes  map[string]struct{}       `yaml:"volumes,omitempty"`
+}
+
+type composeService struct {
+	Image       string            `yaml:"image"`
+	Command     string            `yaml:"command,omitempty"`
+	Hostname    string            `yaml:"hostname,omitempty"`
+	Privileged  bool              `yaml:"privileged,omitempty"`
+	Restart     string            `yaml:"restart"`
+	Ports       []string          `yaml:"ports,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// ComposeFile writes a container template as a one-service compose file,
+// so it deploys as a stack like everything else. Env vars are passed
+// through from the stack env, and volumes without a bind path become
+// named volumes.
+func (t *APITemplate) ComposeFile(name string, env []APIEnvVar) (string, error) {
+	svc := composeService{
+		Image:      t.Image,
+		Command:    t.Command,
+		Hostname:   t.Hostname,
+		Privileged: t.Privileged,
+		Restart:    t.Restart,
+		Ports:      t.Ports,
+	}
+	if svc.Restart == "" {
+		svc.Restart = "unless-stopped"
+	}
+	file := composeFile{Services: map[string]composeService{name: svc}}
+
+	for _, v := range t.Volumes {
+		source := v.Bind
+		if source == "" {
+			source = StackName(v.Container)
+			if file.Volumes == nil {
+				file.Volumes = map[string]struct{}{}
+			}
+			file.Volumes[source] = struct{}{}
+		}
+		mount := source + ":" + v.Container
+		if v.ReadOnly {
+			mount += ":ro"
+		}
+		svc.Volumes = append(svc.Volumes, mount)
+	}
+	if len(env) > 0 {
+		svc.Environment = map[string]string{}
+		for _, e := range env {
+			svc.Environment[e.Name] = "${" + e.Name + "}"
+		}
+	}
+	if len(t.Labels) > 0 {
+		svc.Labels = map[string]string{}
+		for _, l := range t.Labels {
+			svc.Labels[l.Name] = l.Value
+		}
+	}
+	file.Services[name] = svc
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return "", APIError(fmt.Sprintf("failed to write compose file: %s", err))
+	}
+	return string(data), nil
+}
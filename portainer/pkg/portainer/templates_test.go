@@ -0,0 +1,104 @@
+package portainer
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestStackName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Home Assistant": "home-assistant",
+		"Nginx (proxy)":  "nginx-proxy",
+		"/var/lib/data":  "var-lib-data",
+		"uptime_kuma":    "uptime_kuma",
+	} {
+		if got := StackName(in); got != want {
+			t.Errorf("StackName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveTemplateEnv(t *testing.T) {
+	vars := []APITemplateEnv{
+		{Name: "TZ", Default: "UTC"},
+		{Name: "MODE", Preset: true, Default: "prod"},
+		{Name: "DB_PASSWORD", Label: "Database password"},
+	}
+
+	_, err := ResolveTemplateEnv(vars, map[string]string{"MODE": "dev"})
+	e, ok := err.(*PortainerError)
+	if !ok || len(e.Details) != 1 || e.Details[0] != "DB_PASSWORD (Database password)" {
+		t.Fatalf("err = %v, want DB_PASSWORD missing", err)
+	}
+
+	env, err := ResolveTemplateEnv(vars, map[string]string{"MODE": "dev", "DB_PASSWORD": "pw", "EXTRA": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []APIEnvVar{{"TZ", "UTC"}, {"MODE", "prod"}, {"DB_PASSWORD", "pw"}, {"EXTRA", "1"}}
+	if len(env) != len(want) {
+		t.Fatalf("env = %+v", env)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Errorf("env[%d] = %+v, want %+v", i, env[i], want[i])
+		}
+	}
+}
+
+func TestRenderCustomTemplate(t *testing.T) {
+	tmpl := &APICustomTemplate{}
+	tmpl.Variables = append(tmpl.Variables, struct {
+		Name         string `json:"name"`
+		Label        string `json:"label"`
+		DefaultValue string `json:"defaultValue"`
+	}{Name: "tag", DefaultValue: "latest"})
+
+	content, env, err := RenderCustomTemplate(tmpl, "image: app:{{ tag }}\nport: {{port}}\n", map[string]string{"TZ": "UTC"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "image: app:latest\nport: {{port}}\n" {
+		t.Errorf("content = %q", content)
+	}
+	if len(env) != 1 || env[0].Name != "TZ" {
+		t.Errorf("env = %+v", env)
+	}
+}
+
+func TestTemplateComposeFile(t *testing.T) {
+	tmpl := APITemplate{Type: TemplateContainer, Image: "portainer/agent:latest", Ports: []string{"9001:9001/tcp"}}
+	tmpl.Volumes = append(tmpl.Volumes,
+		struct {
+			Container string `json:"container"`
+			Bind      string `json:"bind"`
+			ReadOnly  bool   `json:"readonly"`
+		}{Container: "/var/run/docker.sock", Bind: "/var/run/docker.sock", ReadOnly: true},
+		struct {
+			Container string `json:"container"`
+			Bind      string `json:"bind"`
+			ReadOnly  bool   `json:"readonly"`
+		}{Container: "/data"},
+	)
+
+	content, err := tmpl.ComposeFile("agent", []APIEnvVar{{Name: "TZ", Value: "UTC"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed composeFile
+	if err := yaml.Unmarshal([]byte(content), &parsed); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, content)
+	}
+	svc := parsed.Services["agent"]
+	if svc.Image != "portainer/agent:latest" || svc.Restart != "unless-stopped" || svc.Environment["TZ"] != "${TZ}" {
+		t.Errorf("service = %+v", svc)
+	}
+	if strings.Join(svc.Volumes, " ") != "/var/run/docker.sock:/var/run/docker.sock:ro data:/data" {
+		t.Errorf("volumes = %v", svc.Volumes)
+	}
+	if _, ok := parsed.Volumes["data"]; !ok {
+		t.Errorf("named volume not declared:\n%s", content)
+	}
+}
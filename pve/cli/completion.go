@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+)
+
+func init() {
+	startCmd.ValidArgsFunction = tool.Complete("guests-stopped", completeGuests(func(status string) bool { return status != "running" }))
+	stopCmd.ValidArgsFunction = tool.Complete("guests-running", completeGuests(func(status string) bool { return status == "running" }))
+}
+
+// completeGuests offers VMIDs of guests whose status matches, so start
+// suggests stopped guests and stop suggests running ones.
+func completeGuests(match func(status string) bool) func() ([]string, error) {
+	return func() ([]string, error) {
+		client, err := getClient()
+		if err != nil {
+			return nil, err
+		}
+		guests, err := client.ListGuests()
+		if err != nil {
+			return nil, err
+		}
+
+		var out []string
+		for _, g := range guests {
+			if match(g.Status) {
+				out = append(out, fmt.Sprintf("%d\t%s (%s)", g.VMID, g.Name, g.Type))
+			}
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/schmoli/cli-tools/common"
+)
+
+// doctor checks pve-cli's service for the doctor command
+var doctor = &common.Doctor{
+	URLEnv:     "PVE_URL",
+	Service:    "Proxmox VE",
+	MinVersion: "6.2",
+	Features:   "API tokens",
+	Connect: func() (string, bool, error) {
+		url, _, _, insecure, err := getConfig()
+		return url, insecure, err
+	},
+	Version: func() (string, error) {
+		client, err := getClient()
+		if err != nil {
+			return "", err
+		}
+		return client.ServerVersion()
+	},
+}
@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/pve/pkg/pve"
+)
+
+var (
+	flagURL         string
+	flagTokenID     string
+	flagTokenSecret string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "pve-cli",
+	Short: "CLI for Proxmox VE API",
+}
+
+// tool describes pve-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "pve",
+	Keys:    []string{"url", "token-id", "token", "insecure"},
+	Secrets: []string{"token"},
+	Context: &flagContext,
+	Print:   pve.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "Proxmox URL (or set PVE_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagTokenID, "token-id", "", "Token ID (or set PVE_TOKEN_ID)")
+	rootCmd.PersistentFlags().StringVar(&flagTokenSecret, "token", "", "Token secret (or set PVE_TOKEN_SECRET)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for pve-cli")
+	rootCmd.PersistentPreRun = setup
+
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func getConfig() (string, string, string, bool, error) {
+	file, err := common.LoadServiceConfig("pve", flagContext)
+	if err != nil {
+		return "", "", "", false, err
+	}
+
+	url := flagURL
+	if url == "" {
+		url = os.Getenv("PVE_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", "", false, pve.ConfigError("missing URL. Use --url, set PVE_URL or add url to a config context")
+	}
+
+	tokenID := flagTokenID
+	if tokenID == "" {
+		tokenID = os.Getenv("PVE_TOKEN_ID")
+	}
+	if tokenID == "" {
+		tokenID = file.Get("token-id")
+	}
+	if tokenID == "" {
+		return "", "", "", false, pve.ConfigError("missing token ID. Use --token-id, set PVE_TOKEN_ID or add token-id to a config context")
+	}
+
+	tokenSecret := flagTokenSecret
+	if tokenSecret == "" {
+		tokenSecret = os.Getenv("PVE_TOKEN_SECRET")
+	}
+	if tokenSecret == "" {
+		if tokenSecret, err = file.Secret("token"); err != nil {
+			return "", "", "", false, err
+		}
+	}
+	if tokenSecret == "" {
+		return "", "", "", false, pve.ConfigError("missing token secret. Use --token, set PVE_TOKEN_SECRET or run 'pve-cli config set-secret token'")
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", "", false, pve.ConfigError("URL must start with http:// or https://")
+	}
+
+	return url, tokenID, tokenSecret, flagInsecure || file.Bool("insecure"), nil
+}
+
+func getClient() (*pve.Client, error) {
+	url, tokenID, tokenSecret, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return pve.NewClient(url, tokenID, tokenSecret, insecure), nil
+}
+
+func parseVMID(arg string) (int64, error) {
+	var id int64
+	if _, err := fmt.Sscanf(arg, "%d", &id); err != nil {
+		return 0, pve.ConfigError(fmt.Sprintf("invalid VMID: %s", arg))
+	}
+	if id <= 0 {
+		return 0, pve.ConfigError("VMID must be positive")
+	}
+	return id, nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := pve.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	pve.PrintError(err)
+	if pe, ok := err.(*pve.PveError); ok {
+		os.Exit(pe.ExitCode())
+	}
+	os.Exit(1)
+}
+
+// Command returns the pve-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of pve-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all VMs and LXCs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		guests, err := client.ListGuests()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		if err := pve.Print(guests); err != nil {
+			handleError(err)
+		}
+		return nil
+	},
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start <vmid>",
+	Short: "Start a VM or LXC",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vmid, err := parseVMID(args[0])
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		vmType, name, err := client.FindGuestType(vmid)
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		if err := client.StartGuest(vmid, vmType); err != nil {
+			handleError(err)
+			return nil
+		}
+
+		result := pve.ActionResult{
+			VMID:   vmid,
+			Name:   name,
+			Action: "started",
+		}
+		if err := pve.Print(result); err != nil {
+			handleError(err)
+		}
+		return nil
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop <vmid>",
+	Short: "Stop a VM or LXC",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vmid, err := parseVMID(args[0])
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		vmType, name, err := client.FindGuestType(vmid)
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		if err := client.StopGuest(vmid, vmType); err != nil {
+			handleError(err)
+			return nil
+		}
+
+		result := pve.ActionResult{
+			VMID:   vmid,
+			Name:   name,
+			Action: "stopped",
+		}
+		if err := pve.Print(result); err != nil {
+			handleError(err)
+		}
+		return nil
+	},
+}
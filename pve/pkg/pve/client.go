@@ -1,105 +1,31 @@
 package pve
 
 import (
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
 type Client struct {
-	baseURL     string
-	tokenID     string
-	tokenSecret string
-	httpClient  *http.Client
-	node        string // cached node name
+	api  *common.Client
+	node string // cached node name
 }
 
 func NewClient(url, tokenID, tokenSecret string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
+	auth := common.PVETokenAuth{TokenID: tokenID, Secret: tokenSecret}
 	return &Client{
-		baseURL:     strings.TrimSuffix(url, "/"),
-		tokenID:     tokenID,
-		tokenSecret: tokenSecret,
-		httpClient:  client,
+		api: common.NewClient(url, auth, common.Options{Timeout: 10 * time.Second, Insecure: insecure, ParseError: parseError}),
 	}
 }
 
-func (c *Client) authHeader() string {
-	return fmt.Sprintf("PVEAPIToken=%s=%s", c.tokenID, c.tokenSecret)
-}
-
 func (c *Client) get(path string, result interface{}) error {
-	url := c.baseURL + path
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("Authorization", c.authHeader())
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response from %s: %s", path, err))
-		}
-		return nil
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return AuthError(fmt.Sprintf("invalid or expired token for %s", path))
-	case http.StatusNotFound:
-		return NotFoundError(fmt.Sprintf("resource not found: %s", path))
-	default:
-		return APIError(fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, path))
-	}
+	return c.api.Get(path, result)
 }
 
 func (c *Client) post(path string, result interface{}) error {
-	url := c.baseURL + path
-
-	req, err := http.NewRequest("POST", url, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("Authorization", c.authHeader())
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-		if result != nil {
-			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-				return APIError(fmt.Sprintf("failed to parse response from %s: %s", path, err))
-			}
-		}
-		return nil
-	case http.StatusUnauthorized, http.StatusForbidden:
-		return AuthError(fmt.Sprintf("invalid or expired token for %s", path))
-	case http.StatusNotFound:
-		return NotFoundError(fmt.Sprintf("resource not found: %s", path))
-	default:
-		return APIError(fmt.Sprintf("unexpected status %d from %s", resp.StatusCode, path))
-	}
+	return c.api.Post(path, nil, result)
 }
 
 func (c *Client) GetNode() (string, error) {
@@ -243,3 +169,17 @@ func (c *Client) FindGuestType(vmid int64) (string, string, error) {
 
 	return "", "", NotFoundError(fmt.Sprintf("guest %d not found", vmid))
 }
+
+// ServerVersion returns the Proxmox VE version. /version needs a valid
+// token, so it doubles as an auth check.
+func (c *Client) ServerVersion() (string, error) {
+	var resp struct {
+		Data struct {
+			Version string `json:"version"`
+		} `json:"data"`
+	}
+	if err := c.get("/api2/json/version", &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.Version, nil
+}
@@ -3,6 +3,7 @@ package pve
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -133,3 +134,46 @@ func TestClientStopGuest(t *testing.T) {
 		t.Error("stop endpoint was not called")
 	}
 }
+
+func TestClientErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"data":null,"errors":{"vmid":"invalid format - value does not look like a valid VM ID\n"},"message":"Parameter verification failed.\n"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "user@pam!token", "secret", false)
+	_, err := client.GetNode()
+	pe, ok := err.(*PveError)
+	if !ok {
+		t.Fatalf("expected *PveError, got %T", err)
+	}
+	if pe.Status != http.StatusBadRequest {
+		t.Errorf("Status = %d", pe.Status)
+	}
+	if !strings.HasSuffix(pe.Message, ": Parameter verification failed.") {
+		t.Errorf("Message = %q", pe.Message)
+	}
+	if len(pe.Details) != 1 || pe.Details[0] != "vmid: invalid format - value does not look like a valid VM ID" {
+		t.Errorf("Details = %v", pe.Details)
+	}
+}
+
+func TestClientServerVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api2/json/version" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":{"version":"8.2.4","release":"8.2"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "user@pam!token", "secret", false)
+	version, err := client.ServerVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "8.2.4" {
+		t.Errorf("got version %q, want 8.2.4", version)
+	}
+}
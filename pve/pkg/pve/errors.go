@@ -1,59 +1,67 @@
 package pve
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
-type ErrorCode string
-
-const (
-	ErrConfig   ErrorCode = "CONFIG_ERROR"
-	ErrAuth     ErrorCode = "AUTH_FAILED"
-	ErrNotFound ErrorCode = "NOT_FOUND"
-	ErrNetwork  ErrorCode = "NETWORK_ERROR"
-	ErrAPI      ErrorCode = "API_ERROR"
+	"github.com/schmoli/cli-tools/common"
 )
 
-type PveError struct {
-	Code    ErrorCode
-	Message string
-}
+type ErrorCode = common.ErrorCode
 
-func (e *PveError) Error() string {
-	return e.Message
-}
+const (
+	ErrConfig   = common.ErrConfig
+	ErrAuth     = common.ErrAuth
+	ErrNotFound = common.ErrNotFound
+	ErrNetwork  = common.ErrNetwork
+	ErrAPI      = common.ErrAPI
+)
 
-func (e *PveError) ExitCode() int {
-	switch e.Code {
-	case ErrConfig:
-		return 1
-	case ErrAuth:
-		return 2
-	case ErrNotFound:
-		return 3
-	case ErrNetwork:
-		return 4
-	case ErrAPI:
-		return 5
-	default:
-		return 1
-	}
-}
+type PveError = common.Error
 
 func ConfigError(msg string) *PveError {
-	return &PveError{Code: ErrConfig, Message: msg}
+	return common.ConfigError(msg)
 }
 
 func AuthError(msg string) *PveError {
-	return &PveError{Code: ErrAuth, Message: msg}
+	return common.AuthError(msg)
 }
 
 func NotFoundError(msg string) *PveError {
-	return &PveError{Code: ErrNotFound, Message: msg}
+	return common.NotFoundError(msg)
 }
 
 func NetworkError(msg string) *PveError {
-	return &PveError{Code: ErrNetwork, Message: msg}
+	return common.NetworkError(msg)
 }
 
 func APIError(msg string) *PveError {
-	return &PveError{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+	return common.APIError(msg)
+}
+
+// parseError reads Proxmox's {"message": ..., "errors": {param: reason}}
+// envelope. Many failures only carry the reason in the status line, which
+// common.Client falls back to.
+func parseError(body []byte) (string, []string) {
+	var e struct {
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return "", nil
+	}
+
+	params := make([]string, 0, len(e.Errors))
+	for param := range e.Errors {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	var details []string
+	for _, param := range params {
+		details = append(details, fmt.Sprintf("%s: %s", param, strings.TrimSpace(e.Errors[param])))
+	}
+	return strings.TrimSpace(e.Message), details
 }
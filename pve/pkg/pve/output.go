@@ -5,18 +5,10 @@ import (
 	"io"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAMLTo(w io.Writer, data interface{}) error {
 	enc := yaml.NewEncoder(w)
 	enc.SetIndent(2)
@@ -30,23 +22,38 @@ func PrintYAML(data interface{}) error {
 	return PrintYAMLTo(os.Stdout, data)
 }
 
-func PrintError(err error) {
-	pe, ok := err.(*PveError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
-	}
+var printer = &common.Printer{Format: common.FormatYAML}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(pe.Code),
-			Message: pe.Message,
-		},
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
 	}
+	printer = p
+	return nil
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", pe.Message)
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
+func PrintError(err error) {
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (Guest) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "VMID", Field: "vmid"},
+		{Header: "NAME", Field: "name"},
+		{Header: "TYPE", Field: "type"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "IP", Field: "ip"},
+		{Header: "CPU", Field: "cpu", Wide: true},
+		{Header: "MEMORY (MB)", Field: "memory", Wide: true},
+		{Header: "UPTIME", Field: "uptime", Wide: true},
 	}
 }
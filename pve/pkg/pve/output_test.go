@@ -2,7 +2,10 @@ package pve
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
 func TestPrintYAML(t *testing.T) {
@@ -19,3 +22,27 @@ func TestPrintYAML(t *testing.T) {
 		t.Errorf("got %q, want %q", buf.String(), expected)
 	}
 }
+
+func TestGuestTableOutput(t *testing.T) {
+	p, err := common.NewPrinter("wide")
+	if err != nil {
+		t.Fatalf("NewPrinter: %v", err)
+	}
+
+	var buf bytes.Buffer
+	guests := []Guest{{VMID: 100, Name: "vm1", Type: "qemu", Status: "running", CPU: 4, Memory: 8192, Uptime: "1h", IP: "10.0.0.5"}}
+	if err := p.Print(&buf, guests); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "VMID") || !strings.Contains(lines[0], "MEMORY (MB)") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "10.0.0.5") {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
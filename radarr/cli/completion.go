@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+)
+
+func init() {
+	moviesShowCmd.ValidArgsFunction = tool.Complete("movies", completeMovies)
+}
+
+func completeMovies() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	movies, err := client.ListMovies()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(movies))
+	for i, m := range movies {
+		out[i] = fmt.Sprintf("%d\t%s (%d)", m.ID, m.Title, m.Year)
+	}
+	return out, nil
+}
@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/radarr/pkg/radarr"
+)
+
+var (
+	flagURL         string
+	flagAPIKey      string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+	flagDays        int
+	flagLimit       int
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "radarr-cli",
+	Short: "CLI for Radarr",
+}
+
+// tool describes radarr-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "radarr",
+	Keys:    []string{"url", "apikey", "insecure"},
+	Secrets: []string{"apikey"},
+	Context: &flagContext,
+	Print:   radarr.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+// Movies commands
+var moviesCmd = &cobra.Command{
+	Use:   "movies",
+	Short: "Manage movies",
+}
+
+var moviesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all movies",
+	RunE:  runMoviesList,
+}
+
+var moviesShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show movie details",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMoviesShow,
+}
+
+// Other commands
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Show upcoming releases",
+	RunE:  runCalendar,
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Show download queue",
+	RunE:  runQueue,
+}
+
+var wantedCmd = &cobra.Command{
+	Use:   "wanted",
+	Short: "Show missing movies",
+	RunE:  runWanted,
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search for new movies",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSearch,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "Radarr URL (or set RADARR_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagAPIKey, "apikey", "", "API key (or set RADARR_API_KEY)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for radarr-cli")
+	rootCmd.PersistentPreRun = setup
+
+	calendarCmd.Flags().IntVar(&flagDays, "days", 30, "Number of days to show")
+	wantedCmd.Flags().IntVar(&flagLimit, "limit", 20, "Maximum items to return")
+
+	moviesCmd.AddCommand(moviesListCmd, moviesShowCmd)
+
+	rootCmd.AddCommand(moviesCmd)
+	rootCmd.AddCommand(calendarCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(wantedCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func getConfig() (url, apiKey string, insecure bool, err error) {
+	file, err := common.LoadServiceConfig("radarr", flagContext)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	url = flagURL
+	if url == "" {
+		url = os.Getenv("RADARR_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", false, radarr.ConfigError("missing URL. Use --url, set RADARR_URL or add url to a config context")
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", false, radarr.ConfigError("URL must start with http:// or https://")
+	}
+
+	apiKey = flagAPIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("RADARR_API_KEY")
+	}
+	if apiKey == "" {
+		if apiKey, err = file.Secret("apikey"); err != nil {
+			return "", "", false, err
+		}
+	}
+	if apiKey == "" {
+		return "", "", false, radarr.ConfigError("missing API key. Use --apikey, set RADARR_API_KEY or run 'radarr-cli config set-secret apikey'")
+	}
+
+	return url, apiKey, flagInsecure || file.Bool("insecure"), nil
+}
+
+func getClient() (*radarr.Client, error) {
+	url, apiKey, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return radarr.NewClient(url, apiKey, insecure), nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := radarr.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	radarr.PrintError(err)
+	if re, ok := err.(*radarr.RadarrError); ok {
+		os.Exit(re.ExitCode())
+	}
+	os.Exit(1)
+}
+
+func runMoviesList(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	movies, err := client.ListMovies()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []radarr.MovieListItem
+	for _, m := range movies {
+		items = append(items, m.ToListItem())
+	}
+
+	if err := radarr.Print(radarr.MovieList{Movies: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runMoviesShow(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		handleError(radarr.ConfigError("invalid movie ID"))
+		return nil
+	}
+
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	movie, err := client.GetMovie(id)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	if err := radarr.Print(radarr.MovieDetail{Movie: movie.ToDetail()}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runCalendar(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	entries, err := client.GetCalendar(flagDays)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []radarr.CalendarItem
+	for _, e := range entries {
+		items = append(items, e.ToListItem())
+	}
+
+	if err := radarr.Print(radarr.CalendarList{Movies: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runQueue(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	queue, err := client.GetQueue()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []radarr.QueueItem
+	for _, q := range queue.Records {
+		items = append(items, q.ToListItem())
+	}
+
+	if err := radarr.Print(radarr.QueueList{Queue: items, Total: queue.TotalRecords}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runWanted(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	wanted, err := client.GetWanted(flagLimit)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []radarr.WantedItem
+	for _, m := range wanted.Records {
+		items = append(items, m.ToWantedItem())
+	}
+
+	if err := radarr.Print(radarr.WantedList{Movies: items, Total: wanted.TotalRecords}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	query := strings.Join(args, " ")
+	results, err := client.Search(query)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []radarr.SearchResultItem
+	for _, r := range results {
+		items = append(items, r.ToListItem())
+	}
+
+	if err := radarr.Print(radarr.SearchResultList{Results: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+// Command returns the radarr-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of radarr-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
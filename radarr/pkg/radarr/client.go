@@ -1,74 +1,25 @@
 package radarr
 
 import (
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
-	"strings"
 	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
 type Client struct {
-	baseURL    string
-	apiKey     string
-	httpClient *http.Client
+	api *common.Client
 }
 
 func NewClient(baseURL, apiKey string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
 	return &Client{
-		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		apiKey:     apiKey,
-		httpClient: client,
+		api: common.NewClient(baseURL, common.APIKeyAuth{Header: "X-Api-Key", Key: apiKey}, common.Options{Insecure: insecure, ParseError: common.ParseArrError}),
 	}
 }
 
 func (c *Client) request(method, path string, result interface{}) error {
-	reqURL := c.baseURL + "/api/v3" + path
-
-	req, err := http.NewRequest(method, reqURL, nil)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-
-	req.Header.Set("X-Api-Key", c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return NetworkError(err.Error())
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return AuthError("invalid API key")
-	}
-
-	if resp.StatusCode == http.StatusNotFound {
-		return NotFoundError("resource not found")
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return APIError(fmt.Sprintf("unexpected status %d", resp.StatusCode))
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response: %s", err))
-		}
-	}
-
-	return nil
+	return c.api.Do(method, "/api/v3"+path, nil, result)
 }
 
 func (c *Client) ListMovies() ([]APIMovie, error) {
@@ -127,3 +78,15 @@ func (c *Client) Search(term string) ([]APISearchResult, error) {
 	}
 	return results, nil
 }
+
+// ServerVersion returns the server version from /system/status, which
+// needs a valid API key.
+func (c *Client) ServerVersion() (string, error) {
+	var status struct {
+		Version string `json:"version"`
+	}
+	if err := c.request("GET", "/system/status", &status); err != nil {
+		return "", err
+	}
+	return status.Version, nil
+}
@@ -4,18 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	enc := yaml.NewEncoder(os.Stdout)
 	enc.SetIndent(2)
@@ -25,23 +17,92 @@ func PrintYAML(data interface{}) error {
 	return nil
 }
 
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
 func PrintError(err error) {
-	re, ok := err.(*RadarrError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (MovieListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "HAS FILE", Field: "hasFile"},
+		{Header: "SIZE", Field: "size", Wide: true},
+		{Header: "RUNTIME", Field: "runtime", Wide: true},
 	}
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(re.Code),
-			Message: re.Message,
-		},
+func (MovieDetailItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "HAS FILE", Field: "hasFile"},
+		{Header: "SIZE", Field: "size", Wide: true},
+		{Header: "RUNTIME", Field: "runtime", Wide: true},
+		{Header: "STUDIO", Field: "studio", Wide: true},
+		{Header: "IMDB ID", Field: "imdbId", Wide: true},
+		{Header: "PATH", Field: "path", Wide: true},
 	}
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", re.Message)
+func (CalendarItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "RELEASE DATE", Field: "releaseDate"},
+		{Header: "RELEASE TYPE", Field: "releaseType"},
+		{Header: "HAS FILE", Field: "hasFile"},
+	}
+}
+
+func (QueueItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "SIZE", Field: "size"},
+		{Header: "REMAINING", Field: "remaining"},
+		{Header: "TIME LEFT", Field: "timeLeft"},
+	}
+}
+
+func (WantedItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+	}
+}
+
+func (SearchResultItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "TMDB ID", Field: "tmdbId"},
+		{Header: "RUNTIME", Field: "runtime"},
+		{Header: "STUDIO", Field: "studio", Wide: true},
 	}
 }
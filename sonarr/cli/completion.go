@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+)
+
+func init() {
+	seriesShowCmd.ValidArgsFunction = tool.Complete("series", completeSeries)
+}
+
+func completeSeries() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	series, err := client.ListSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(series))
+	for i, s := range series {
+		out[i] = fmt.Sprintf("%d\t%s (%d)", s.ID, s.Title, s.Year)
+	}
+	return out, nil
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/schmoli/cli-tools/common"
+)
+
+// doctor checks sonarr-cli's service for the doctor command
+var doctor = &common.Doctor{
+	URLEnv:     "SONARR_URL",
+	Service:    "Sonarr",
+	MinVersion: "3.0",
+	Features:   "API v3",
+	Connect: func() (string, bool, error) {
+		url, _, insecure, err := getConfig()
+		return url, insecure, err
+	},
+	Version: func() (string, error) {
+		client, err := getClient()
+		if err != nil {
+			return "", err
+		}
+		return client.ServerVersion()
+	},
+}
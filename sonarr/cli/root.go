@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/sonarr/pkg/sonarr"
+)
+
+var (
+	flagURL         string
+	flagAPIKey      string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+	flagDays        int
+	flagLimit       int
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "sonarr-cli",
+	Short: "CLI for Sonarr",
+}
+
+// tool describes sonarr-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "sonarr",
+	Keys:    []string{"url", "apikey", "insecure"},
+	Secrets: []string{"apikey"},
+	Context: &flagContext,
+	Print:   sonarr.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+// Series commands
+var seriesCmd = &cobra.Command{
+	Use:   "series",
+	Short: "Manage TV series",
+}
+
+var seriesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all series",
+	RunE:  runSeriesList,
+}
+
+var seriesShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show series details",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSeriesShow,
+}
+
+// Other commands
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Show upcoming episodes",
+	RunE:  runCalendar,
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Show download queue",
+	RunE:  runQueue,
+}
+
+var wantedCmd = &cobra.Command{
+	Use:   "wanted",
+	Short: "Show missing episodes",
+	RunE:  runWanted,
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search for new series",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSearch,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "Sonarr URL (or set SONARR_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagAPIKey, "apikey", "", "API key (or set SONARR_API_KEY)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for sonarr-cli")
+	rootCmd.PersistentPreRun = setup
+
+	calendarCmd.Flags().IntVar(&flagDays, "days", 7, "Number of days to show")
+	wantedCmd.Flags().IntVar(&flagLimit, "limit", 20, "Maximum items to return")
+
+	seriesCmd.AddCommand(seriesListCmd, seriesShowCmd)
+
+	rootCmd.AddCommand(seriesCmd)
+	rootCmd.AddCommand(calendarCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(wantedCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func getConfig() (url, apiKey string, insecure bool, err error) {
+	file, err := common.LoadServiceConfig("sonarr", flagContext)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	url = flagURL
+	if url == "" {
+		url = os.Getenv("SONARR_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", false, sonarr.ConfigError("missing URL. Use --url, set SONARR_URL or add url to a config context")
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", false, sonarr.ConfigError("URL must start with http:// or https://")
+	}
+
+	apiKey = flagAPIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("SONARR_API_KEY")
+	}
+	if apiKey == "" {
+		if apiKey, err = file.Secret("apikey"); err != nil {
+			return "", "", false, err
+		}
+	}
+	if apiKey == "" {
+		return "", "", false, sonarr.ConfigError("missing API key. Use --apikey, set SONARR_API_KEY or run 'sonarr-cli config set-secret apikey'")
+	}
+
+	return url, apiKey, flagInsecure || file.Bool("insecure"), nil
+}
+
+func getClient() (*sonarr.Client, error) {
+	url, apiKey, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return sonarr.NewClient(url, apiKey, insecure), nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := sonarr.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	sonarr.PrintError(err)
+	if se, ok := err.(*sonarr.SonarrError); ok {
+		os.Exit(se.ExitCode())
+	}
+	os.Exit(1)
+}
+
+func runSeriesList(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	series, err := client.ListSeries()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []sonarr.SeriesListItem
+	for _, s := range series {
+		items = append(items, s.ToListItem())
+	}
+
+	if err := sonarr.Print(sonarr.SeriesList{Series: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runSeriesShow(cmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		handleError(sonarr.ConfigError("invalid series ID"))
+		return nil
+	}
+
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	series, err := client.GetSeries(id)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	if err := sonarr.Print(sonarr.SeriesDetail{Series: series.ToDetail()}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runCalendar(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	entries, err := client.GetCalendar(flagDays)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []sonarr.CalendarItem
+	for _, e := range entries {
+		items = append(items, e.ToListItem())
+	}
+
+	if err := sonarr.Print(sonarr.CalendarList{Episodes: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runQueue(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	queue, err := client.GetQueue()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []sonarr.QueueItem
+	for _, q := range queue.Records {
+		items = append(items, q.ToListItem())
+	}
+
+	if err := sonarr.Print(sonarr.QueueList{Queue: items, Total: queue.TotalRecords}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runWanted(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	wanted, err := client.GetWanted(flagLimit)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []sonarr.WantedItem
+	for _, e := range wanted.Records {
+		items = append(items, e.ToWantedItem())
+	}
+
+	if err := sonarr.Print(sonarr.WantedList{Episodes: items, Total: wanted.TotalRecords}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	query := strings.Join(args, " ")
+	results, err := client.Search(query)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	var items []sonarr.SearchResultItem
+	for _, r := range results {
+		items = append(items, r.ToListItem())
+	}
+
+	if err := sonarr.Print(sonarr.SearchResultList{Results: items}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+// Command returns the sonarr-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of sonarr-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
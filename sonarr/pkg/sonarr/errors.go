@@ -1,59 +1,35 @@
 package sonarr
 
-import "fmt"
+import "github.com/schmoli/cli-tools/common"
 
-type ErrorCode string
+type ErrorCode = common.ErrorCode
 
 const (
-	ErrConfig   ErrorCode = "CONFIG_ERROR"
-	ErrAuth     ErrorCode = "AUTH_FAILED"
-	ErrNotFound ErrorCode = "NOT_FOUND"
-	ErrNetwork  ErrorCode = "NETWORK_ERROR"
-	ErrAPI      ErrorCode = "API_ERROR"
+	ErrConfig   = common.ErrConfig
+	ErrAuth     = common.ErrAuth
+	ErrNotFound = common.ErrNotFound
+	ErrNetwork  = common.ErrNetwork
+	ErrAPI      = common.ErrAPI
 )
 
-type SonarrError struct {
-	Code    ErrorCode
-	Message string
-}
-
-func (e *SonarrError) Error() string {
-	return e.Message
-}
-
-func (e *SonarrError) ExitCode() int {
-	switch e.Code {
-	case ErrConfig:
-		return 1
-	case ErrAuth:
-		return 2
-	case ErrNotFound:
-		return 3
-	case ErrNetwork:
-		return 4
-	case ErrAPI:
-		return 5
-	default:
-		return 1
-	}
-}
+type SonarrError = common.Error
 
 func ConfigError(msg string) *SonarrError {
-	return &SonarrError{Code: ErrConfig, Message: msg}
+	return common.ConfigError(msg)
 }
 
 func AuthError(msg string) *SonarrError {
-	return &SonarrError{Code: ErrAuth, Message: msg}
+	return common.AuthError(msg)
 }
 
 func NotFoundError(msg string) *SonarrError {
-	return &SonarrError{Code: ErrNotFound, Message: msg}
+	return common.NotFoundError(msg)
 }
 
 func NetworkError(msg string) *SonarrError {
-	return &SonarrError{Code: ErrNetwork, Message: msg}
+	return common.NetworkError(msg)
 }
 
 func APIError(msg string) *SonarrError {
-	return &SonarrError{Code: ErrAPI, Message: fmt.Sprintf("API error: %s", msg)}
+	return common.APIError(msg)
 }
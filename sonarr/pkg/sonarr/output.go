@@ -4,18 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	enc := yaml.NewEncoder(os.Stdout)
 	enc.SetIndent(2)
@@ -25,23 +17,94 @@ func PrintYAML(data interface{}) error {
 	return nil
 }
 
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
 func PrintError(err error) {
-	se, ok := err.(*SonarrError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (SeriesListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "EPISODES", Field: "episodes"},
+		{Header: "NETWORK", Field: "network", Wide: true},
+		{Header: "SEASONS", Field: "seasons", Wide: true},
+		{Header: "SIZE", Field: "size", Wide: true},
+		{Header: "NEXT AIRING", Field: "nextAiring", Wide: true},
 	}
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(se.Code),
-			Message: se.Message,
-		},
+func (SeriesDetailItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "EPISODES", Field: "episodes"},
+		{Header: "NETWORK", Field: "network", Wide: true},
+		{Header: "SEASONS", Field: "seasons", Wide: true},
+		{Header: "SIZE", Field: "size", Wide: true},
+		{Header: "PATH", Field: "path", Wide: true},
 	}
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", se.Message)
+func (CalendarItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "SERIES", Field: "series"},
+		{Header: "EPISODE", Field: "episode"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "AIR DATE", Field: "airDate"},
+		{Header: "HAS FILE", Field: "hasFile"},
+	}
+}
+
+func (QueueItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "SERIES", Field: "series"},
+		{Header: "EPISODE", Field: "episode"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "SIZE", Field: "size"},
+		{Header: "REMAINING", Field: "remaining"},
+		{Header: "TIME LEFT", Field: "timeLeft"},
+		{Header: "TITLE", Field: "title", Wide: true},
+	}
+}
+
+func (WantedItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "SERIES ID", Field: "seriesId"},
+		{Header: "EPISODE", Field: "episode"},
+		{Header: "TITLE", Field: "title"},
+		{Header: "AIR DATE", Field: "airDate"},
+	}
+}
+
+func (SearchResultItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "TITLE", Field: "title"},
+		{Header: "YEAR", Field: "year"},
+		{Header: "TVDB ID", Field: "tvdbId"},
+		{Header: "NETWORK", Field: "network"},
+		{Header: "SEASONS", Field: "seasons"},
 	}
 }
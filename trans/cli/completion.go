@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{showCmd, startCmd, stopCmd} {
+		cmd.ValidArgsFunction = tool.Complete("torrents", completeTorrents)
+	}
+}
+
+func completeTorrents() ([]string, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	torrents, err := client.ListTorrents()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, len(torrents))
+	for i, t := range torrents {
+		out[i] = fmt.Sprintf("%d\t%s", t.ID, t.Name)
+	}
+	return out, nil
+}
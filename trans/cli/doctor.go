@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/schmoli/cli-tools/common"
+)
+
+// doctor checks trans-cli's service for the doctor command
+var doctor = &common.Doctor{
+	URLEnv:     "TRANSMISSION_URL",
+	Service:    "Transmission",
+	MinVersion: "2.80",
+	Features:   "RPC session-id handshake",
+	Connect: func() (string, bool, error) {
+		url, _, _, insecure, err := getConfig()
+		return url, insecure, err
+	},
+	Version: func() (string, error) {
+		client, err := getClient()
+		if err != nil {
+			return "", err
+		}
+		return client.ServerVersion()
+	},
+}
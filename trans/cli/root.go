@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/schmoli/cli-tools/common"
+	"github.com/schmoli/cli-tools/trans/pkg/trans"
+)
+
+var (
+	flagURL         string
+	flagUser        string
+	flagPass        string
+	flagInsecure    bool
+	flagOutput      string
+	flagContext     string
+	flagRetries     int
+	flagRetryWait   time.Duration
+	flagRetryUnsafe bool
+	flagVerbose     int
+	flagDebug       bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "trans-cli",
+	Short: "CLI for Transmission RPC",
+}
+
+// tool describes trans-cli to the shared config, completion and doctor helpers
+var tool = &common.Tool{
+	Name:    "trans",
+	Keys:    []string{"url", "user", "pass", "insecure"},
+	Secrets: []string{"pass"},
+	Context: &flagContext,
+	Print:   trans.Print,
+	Fail:    handleError,
+	Doctor:  doctor,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all torrents",
+	RunE:  runList(nil),
+}
+
+var downloadingCmd = &cobra.Command{
+	Use:   "downloading",
+	Short: "List downloading torrents",
+	RunE:  runList(func(t *trans.APITorrent) bool { return t.IsDownloading() }),
+}
+
+var seedingCmd = &cobra.Command{
+	Use:   "seeding",
+	Short: "List seeding torrents",
+	RunE:  runList(func(t *trans.APITorrent) bool { return t.IsSeeding() }),
+}
+
+var stoppedCmd = &cobra.Command{
+	Use:   "stopped",
+	Short: "List stopped torrents",
+	RunE:  runList(func(t *trans.APITorrent) bool { return t.IsStopped() }),
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show torrent details",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShow,
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add <magnet|file>",
+	Short: "Add torrent (magnet URI or .torrent file)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAdd,
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Start torrent",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStart,
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop <id>",
+	Short: "Stop torrent",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStop,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "Transmission URL (or set TRANSMISSION_URL)")
+	rootCmd.PersistentFlags().StringVar(&flagUser, "user", "", "Username (or set TRANSMISSION_USER)")
+	rootCmd.PersistentFlags().StringVar(&flagPass, "pass", "", "Password (or set TRANSMISSION_PASS)")
+	rootCmd.PersistentFlags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "yaml", common.OutputFlagUsage)
+	rootCmd.PersistentFlags().StringVar(&flagContext, "context", "", "Config context to use (or set CLI_TOOLS_CONTEXT)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", common.DefaultRetries, "Retries for transient failures on idempotent requests")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryWait, "retry-wait", common.DefaultRetryWait, "Base wait between retries, doubled on each attempt")
+	rootCmd.PersistentFlags().BoolVar(&flagRetryUnsafe, "retry-unsafe", false, "Also retry non-idempotent requests such as start/stop")
+	rootCmd.PersistentFlags().CountVarP(&flagVerbose, "verbose", "v", "Trace HTTP requests to stderr (-vv adds headers and bodies)")
+	rootCmd.PersistentFlags().BoolVar(&flagDebug, "debug", false, "Trace HTTP requests with headers and bodies (same as -vv)")
+	rootCmd.Flags().BoolP("version", "V", false, "version for trans-cli")
+	rootCmd.PersistentPreRun = setup
+
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(downloadingCmd)
+	rootCmd.AddCommand(seedingCmd)
+	rootCmd.AddCommand(stoppedCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(common.NewConfigCmd(tool))
+	rootCmd.AddCommand(common.NewDoctorCmd(tool))
+}
+
+func getConfig() (url, user, pass string, insecure bool, err error) {
+	file, err := common.LoadServiceConfig("trans", flagContext)
+	if err != nil {
+		return "", "", "", false, err
+	}
+
+	url = flagURL
+	if url == "" {
+		url = os.Getenv("TRANSMISSION_URL")
+	}
+	if url == "" {
+		url = file.Get("url")
+	}
+	if url == "" {
+		return "", "", "", false, trans.ConfigError("missing URL. Use --url, set TRANSMISSION_URL or add url to a config context")
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return "", "", "", false, trans.ConfigError("URL must start with http:// or https://")
+	}
+
+	user = flagUser
+	if user == "" {
+		user = os.Getenv("TRANSMISSION_USER")
+	}
+	if user == "" {
+		user = file.Get("user")
+	}
+
+	pass = flagPass
+	if pass == "" {
+		pass = os.Getenv("TRANSMISSION_PASS")
+	}
+	if pass == "" && user != "" {
+		if pass, err = file.Secret("pass"); err != nil {
+			return "", "", "", false, err
+		}
+	}
+
+	return url, user, pass, flagInsecure || file.Bool("insecure"), nil
+}
+
+func getClient() (*trans.Client, error) {
+	url, user, pass, insecure, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	return trans.NewClient(url, user, pass, insecure), nil
+}
+
+func parseID(arg string) (int64, error) {
+	var id int64
+	if _, err := fmt.Sscanf(arg, "%d", &id); err != nil {
+		return 0, trans.ConfigError(fmt.Sprintf("invalid ID: %s", arg))
+	}
+	if id <= 0 {
+		return 0, trans.ConfigError("ID must be positive")
+	}
+	return id, nil
+}
+
+func setup(cmd *cobra.Command, args []string) {
+	if err := trans.SetOutputFormat(flagOutput); err != nil {
+		handleError(err)
+	}
+	if err := common.SetRetryPolicy(common.RetryPolicy{Retries: flagRetries, Wait: flagRetryWait, Unsafe: flagRetryUnsafe}); err != nil {
+		handleError(err)
+	}
+
+	level := flagVerbose
+	if flagDebug {
+		level = common.TraceBody
+	}
+	common.SetTrace(level)
+}
+
+func handleError(err error) {
+	trans.PrintError(err)
+	if te, ok := err.(*trans.TransError); ok {
+		os.Exit(te.ExitCode())
+	}
+	os.Exit(1)
+}
+
+func runList(filter func(*trans.APITorrent) bool) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		client, err := getClient()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		torrents, err := client.ListTorrents()
+		if err != nil {
+			handleError(err)
+			return nil
+		}
+
+		var items []trans.TorrentListItem
+		for _, t := range torrents {
+			if filter == nil || filter(&t) {
+				items = append(items, t.ToListItem())
+			}
+		}
+
+		if err := trans.Print(trans.TorrentList{Torrents: items}); err != nil {
+			handleError(err)
+		}
+		return nil
+	}
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	torrent, err := client.GetTorrent(id)
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	if err := trans.Print(trans.TorrentDetail{Torrent: torrent.ToDetail()}); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	input := args[0]
+	var info *trans.TorrentAddedInfo
+
+	if strings.HasPrefix(input, "magnet:") {
+		info, err = client.AddTorrentMagnet(input)
+	} else {
+		info, err = client.AddTorrentFile(input)
+	}
+
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	output := struct {
+		Added struct {
+			ID   int64  `yaml:"id"`
+			Name string `yaml:"name"`
+		} `yaml:"added"`
+	}{}
+	output.Added.ID = info.ID
+	output.Added.Name = info.Name
+
+	if err := trans.Print(output); err != nil {
+		handleError(err)
+	}
+	return nil
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	if err := client.StartTorrent(id); err != nil {
+		handleError(err)
+		return nil
+	}
+
+	fmt.Printf("started torrent %d\n", id)
+	return nil
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	client, err := getClient()
+	if err != nil {
+		handleError(err)
+		return nil
+	}
+
+	if err := client.StopTorrent(id); err != nil {
+		handleError(err)
+		return nil
+	}
+
+	fmt.Printf("stopped torrent %d\n", id)
+	return nil
+}
+
+// Command returns the trans-cli command tree. The standalone binary executes
+// it directly; homelab mounts it as a subcommand.
+func Command(version string) *cobra.Command {
+	rootCmd.Version = version
+	return rootCmd
+}
+
+// Tool returns the shared description of trans-cli, for homelab's aggregate
+// doctor.
+func Tool() *common.Tool {
+	return tool
+}
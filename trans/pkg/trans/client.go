@@ -1,18 +1,16 @@
 package trans
 
 import (
-	"bytes"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
-	"strings"
 	"time"
+
+	"github.com/schmoli/cli-tools/common"
 )
 
-const sessionHeader = "X-Transmission-Session-Id"
+const rpcPath = "/transmission/rpc"
 
 // Fields requested for list view
 var listFields = []string{
@@ -28,98 +26,46 @@ var detailFields = append(listFields,
 	"addedDate", "doneDate", "downloadDir",
 )
 
+// RPC methods that only read state and are safe to retry
+var safeMethods = map[string]bool{
+	"torrent-get":   true,
+	"session-get":   true,
+	"session-stats": true,
+	"free-space":    true,
+}
+
 type Client struct {
-	rpcURL     string
-	user       string
-	pass       string
-	sessionID  string
-	httpClient *http.Client
+	api *common.Client
 }
 
 func NewClient(url, user, pass string, insecure bool) *Client {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	if insecure {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
-	rpcURL := strings.TrimSuffix(url, "/") + "/transmission/rpc"
-
+	auth := &common.TransmissionAuth{BasicAuth: common.BasicAuth{User: user, Pass: pass}}
 	return &Client{
-		rpcURL:     rpcURL,
-		user:       user,
-		pass:       pass,
-		httpClient: client,
+		api: common.NewClient(url, auth, common.Options{Timeout: 10 * time.Second, Insecure: insecure}),
 	}
 }
 
 func (c *Client) rpc(req *RPCRequest, result interface{}) error {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return APIError(fmt.Sprintf("failed to encode request: %s", err))
+	var rpcResp RPCResponse
+	post := c.api.Post
+	if safeMethods[req.Method] {
+		post = c.api.PostIdempotent
+	}
+	if err := post(rpcPath, req, &rpcResp); err != nil {
+		return err
 	}
 
-	// Try request, retry once on 409 (session ID expired)
-	for attempt := 0; attempt < 2; attempt++ {
-		httpReq, err := http.NewRequest("POST", c.rpcURL, bytes.NewReader(body))
-		if err != nil {
-			return NetworkError(err.Error())
-		}
-
-		httpReq.Header.Set("Content-Type", "application/json")
-		if c.sessionID != "" {
-			httpReq.Header.Set(sessionHeader, c.sessionID)
-		}
-		if c.user != "" {
-			httpReq.SetBasicAuth(c.user, c.pass)
-		}
-
-		resp, err := c.httpClient.Do(httpReq)
-		if err != nil {
-			return NetworkError(err.Error())
-		}
-		defer resp.Body.Close()
-
-		// Handle 409 - need new session ID
-		if resp.StatusCode == http.StatusConflict {
-			c.sessionID = resp.Header.Get(sessionHeader)
-			if c.sessionID == "" {
-				return APIError("received 409 but no session ID in response")
-			}
-			continue
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized {
-			return AuthError("invalid credentials")
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			return APIError(fmt.Sprintf("unexpected status %d", resp.StatusCode))
-		}
-
-		// Parse response
-		var rpcResp RPCResponse
-		if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-			return APIError(fmt.Sprintf("failed to parse response: %s", err))
-		}
-
-		if rpcResp.Result != "success" {
-			return APIError(rpcResp.Result)
-		}
+	if rpcResp.Result != "success" {
+		return APIError(rpcResp.Result)
+	}
 
-		if result != nil && rpcResp.Arguments != nil {
-			if err := json.Unmarshal(rpcResp.Arguments, result); err != nil {
-				return APIError(fmt.Sprintf("failed to parse arguments: %s", err))
-			}
+	if result != nil && rpcResp.Arguments != nil {
+		if err := json.Unmarshal(rpcResp.Arguments, result); err != nil {
+			return APIError(fmt.Sprintf("failed to parse arguments: %s", err))
 		}
-
-		return nil
 	}
 
-	return APIError("failed to get valid session after retry")
+	return nil
 }
 
 func (c *Client) ListTorrents() ([]APITorrent, error) {
@@ -228,3 +174,21 @@ func (c *Client) AddTorrentFile(path string) (*TorrentAddedInfo, error) {
 	}
 	return nil, APIError("no torrent info in response")
 }
+
+// ServerVersion returns the daemon version from session-get, which also
+// exercises the session-id handshake and any basic auth.
+func (c *Client) ServerVersion() (string, error) {
+	req := &RPCRequest{
+		Method:    "session-get",
+		Arguments: map[string][]string{"fields": {"version", "rpc-version"}},
+	}
+
+	var resp struct {
+		Version    string `json:"version"`
+		RPCVersion int    `json:"rpc-version"`
+	}
+	if err := c.rpc(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
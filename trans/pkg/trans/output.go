@@ -4,18 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/schmoli/cli-tools/common"
 	"gopkg.in/yaml.v3"
 )
 
-type ErrorOutput struct {
-	Error ErrorDetail `yaml:"error"`
-}
-
-type ErrorDetail struct {
-	Code    string `yaml:"code"`
-	Message string `yaml:"message"`
-}
-
 func PrintYAML(data interface{}) error {
 	enc := yaml.NewEncoder(os.Stdout)
 	enc.SetIndent(2)
@@ -25,23 +17,57 @@ func PrintYAML(data interface{}) error {
 	return nil
 }
 
-func PrintError(err error) {
-	te, ok := err.(*TransError)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+var printer = &common.Printer{Format: common.FormatYAML}
+
+// SetOutputFormat selects the format used by Print from an --output value.
+func SetOutputFormat(spec string) error {
+	p, err := common.NewPrinter(spec)
+	if err != nil {
+		return err
 	}
+	printer = p
+	return nil
+}
 
-	output := ErrorOutput{
-		Error: ErrorDetail{
-			Code:    string(te.Code),
-			Message: te.Message,
-		},
+// Print writes data to stdout in the selected output format.
+func Print(data interface{}) error {
+	return printer.Print(os.Stdout, data)
+}
+
+func PrintError(err error) {
+	common.PrintError(os.Stderr, err)
+}
+
+// Table columns for -o table / -o wide
+
+func (TorrentListItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "DONE", Field: "percentDone"},
+		{Header: "SIZE", Field: "totalSize"},
+		{Header: "DOWN", Field: "rateDownload"},
+		{Header: "UP", Field: "rateUpload"},
+		{Header: "ETA", Field: "eta"},
+		{Header: "RATIO", Field: "uploadRatio", Wide: true},
+		{Header: "PEERS", Field: "peersConnected", Wide: true},
+		{Header: "TRACKER", Field: "tracker", Wide: true},
 	}
+}
 
-	enc := yaml.NewEncoder(os.Stderr)
-	enc.SetIndent(2)
-	if err := enc.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %s\n", te.Message)
+func (TorrentDetailItem) TableColumns() []common.Column {
+	return []common.Column{
+		{Header: "ID", Field: "id"},
+		{Header: "NAME", Field: "name"},
+		{Header: "STATUS", Field: "status"},
+		{Header: "DONE", Field: "percentDone"},
+		{Header: "SIZE", Field: "totalSize"},
+		{Header: "RATIO", Field: "uploadRatio"},
+		{Header: "DOWNLOADED", Field: "downloadedEver", Wide: true},
+		{Header: "UPLOADED", Field: "uploadedEver", Wide: true},
+		{Header: "ADDED", Field: "addedDate", Wide: true},
+		{Header: "DONE DATE", Field: "doneDate", Wide: true},
+		{Header: "DIR", Field: "downloadDir", Wide: true},
 	}
 }